@@ -0,0 +1,66 @@
+// Uploading the local images of a document as attachments of its issue
+
+package main
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/astsu-dev/md2jira/converter"
+	"github.com/astsu-dev/md2jira/jira"
+)
+
+// uploadAttachments attaches the local images of a conversion to an issue.
+// Files already attached under the same name and size are not uploaded
+// again, so documents can be published repeatedly.
+func uploadAttachments(client *jira.Client, key string, attachments []converter.Attachment) error {
+	if len(attachments) == 0 {
+		return nil
+	}
+	existing, err := client.Attachments(key)
+	if err != nil {
+		return fmt.Errorf("listing attachments of %s: %v", key, err)
+	}
+	sizes := make(map[string]map[int64]bool)
+	for _, a := range existing {
+		if sizes[a.Filename] == nil {
+			sizes[a.Filename] = make(map[int64]bool)
+		}
+		sizes[a.Filename][a.Size] = true
+	}
+	for _, a := range attachments {
+		if err := uploadAttachment(client, key, a, sizes[a.Name]); err != nil {
+			return fmt.Errorf("attaching %s to %s: %v", a.Path, key, err)
+		}
+	}
+	return nil
+}
+
+// uploadAttachment uploads a file unless it is attached already with one of
+// the given sizes
+func uploadAttachment(client *jira.Client, key string, a converter.Attachment, sizes map[int64]bool) error {
+	file, err := os.Open(a.Path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+	info, err := file.Stat()
+	if err != nil {
+		return err
+	}
+	if sizes[info.Size()] {
+		return nil
+	}
+	if err := client.AddAttachment(key, a.Name, file); err != nil {
+		return err
+	}
+	fmt.Fprintf(os.Stderr, "Attached %s to %s as %s\n", a.Path, key, a.Name)
+	return nil
+}
+
+// printAttachments reports the files a dry run would attach
+func printAttachments(attachments []converter.Attachment) {
+	for _, a := range attachments {
+		fmt.Fprintf(os.Stderr, "Would attach %s as %s\n", a.Path, a.Name)
+	}
+}
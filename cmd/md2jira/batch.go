@@ -0,0 +1,202 @@
+// Batch conversion of several inputs into an output directory
+
+package main
+
+import (
+	"fmt"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/astsu-dev/md2jira/converter"
+)
+
+// batchInput is an input file and the path of its output relative to the output directory
+type batchInput struct {
+	path   string
+	output string
+}
+
+// flatInputs names each output after its input file
+func flatInputs(files []string, c conversion) []batchInput {
+	inputs := make([]batchInput, len(files))
+	for i, file := range files {
+		inputs[i] = batchInput{path: file, output: c.outputName(file)}
+	}
+	return inputs
+}
+
+// siblingInputs places each output next to its input
+func siblingInputs(inputs []batchInput, c conversion) []batchInput {
+	siblings := make([]batchInput, len(inputs))
+	for i, in := range inputs {
+		siblings[i] = batchInput{path: in.path, output: filepath.Join(filepath.Dir(in.path), c.outputName(in.path))}
+	}
+	return siblings
+}
+
+// markdownPatterns are the files converted by a recursive walk without --include
+var markdownPatterns = []string{"*.md", "*.markdown"}
+
+// walkInputs finds the Markdown files under each root, mirroring their
+// directory structure in the output paths. A file is converted when it matches
+// an include pattern and no exclude pattern; excluded directories are skipped.
+func walkInputs(roots, include, exclude []string, c conversion) ([]batchInput, error) {
+	if len(include) == 0 {
+		include = markdownPatterns
+	}
+	var inputs []batchInput
+	for _, root := range roots {
+		err := filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+			if err != nil {
+				return err
+			}
+			rel, err := filepath.Rel(root, path)
+			if err != nil {
+				return err
+			}
+			if rel == "." {
+				return nil
+			}
+			if matchesAny(rel, exclude) {
+				if d.IsDir() {
+					return filepath.SkipDir
+				}
+				return nil
+			}
+			if d.IsDir() || !matchesAny(rel, include) {
+				return nil
+			}
+			inputs = append(inputs, batchInput{
+				path:   path,
+				output: filepath.Join(filepath.Dir(rel), c.outputName(path)),
+			})
+			return nil
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+	return inputs, nil
+}
+
+// matchesAny reports whether a relative path matches one of the glob patterns.
+// Patterns without a slash match the base name; others match the whole path.
+func matchesAny(rel string, patterns []string) bool {
+	rel = filepath.ToSlash(rel)
+	for _, pattern := range patterns {
+		name := rel
+		if !strings.Contains(pattern, "/") {
+			name = path.Base(rel)
+		}
+		if ok, _ := path.Match(pattern, name); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// splitList splits a comma-separated flag value, ignoring empty entries
+func splitList(value string) []string {
+	var items []string
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
+// runBatch converts each input into outDir, printing a summary line per file,
+// and returns the exit code. Files that fail are reported and skipped.
+func runBatch(inputs []batchInput, outDir string, opts converter.Options, c conversion) int {
+	// Inputs must not share an output file
+	outputs := make(map[string]string, len(inputs))
+	for _, in := range inputs {
+		if other, ok := outputs[in.output]; ok {
+			fmt.Fprintf(os.Stderr, "Error: %s and %s would both be written to %s\n", other, in.path, in.output)
+			return exitUsage
+		}
+		outputs[in.output] = in.path
+		if filepath.Clean(filepath.Join(outDir, in.output)) == filepath.Clean(in.path) {
+			fmt.Fprintf(os.Stderr, "Error: %s would be overwritten by its own output; choose another --ext\n", in.path)
+			return exitUsage
+		}
+	}
+
+	code := exitOK
+	converted, warnings := 0, 0
+	for _, in := range inputs {
+		file := in.path
+		input, err := readSource(file)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error reading file: %v\n", err)
+			code = exitIO
+			continue
+		}
+
+		opts.BaseDir = filepath.Dir(file)
+		opts.SourcePath = file
+		opts.Directives, err = c.directives(file, input, opts)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error reading directives of %s: %v\n", file, err)
+			code = exitIO
+			continue
+		}
+		output, result, err := c.run(input, opts)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error converting %s: %v\n", file, err)
+			if code == exitOK {
+				code = exitConversion
+			}
+			continue
+		}
+		if c.showWarnings && !c.json {
+			for _, w := range result.Warnings {
+				fmt.Fprintln(os.Stderr, formatWarning(file, w))
+			}
+		}
+		if !c.json {
+			printProfile(os.Stderr, file, result.Profile)
+			if c.stats {
+				printStats(os.Stderr, file, result.Stats)
+			}
+		}
+
+		outFile := filepath.Join(outDir, in.output)
+		if err := os.MkdirAll(filepath.Dir(outFile), 0755); err != nil {
+			fmt.Fprintf(os.Stderr, "Error creating output directory: %v\n", err)
+			code = exitIO
+			continue
+		}
+		if err := os.WriteFile(outFile, []byte(c.lineEndings(output+"\n")), 0644); err != nil {
+			fmt.Fprintf(os.Stderr, "Error writing output file: %v\n", err)
+			code = exitIO
+			continue
+		}
+		converted++
+		warnings += len(result.Warnings)
+		fmt.Fprintf(os.Stderr, "%s -> %s (%s)\n", file, outFile, plural(len(result.Warnings), "warning"))
+	}
+
+	fmt.Fprintf(os.Stderr, "Converted %s of %d, %s\n", plural(converted, "file"), len(inputs), plural(warnings, "warning"))
+	if code == exitOK && c.failOnWarning && warnings > 0 {
+		code = exitWarnings
+	}
+	return code
+}
+
+// outputName returns the name of the converted file for an input
+func (c conversion) outputName(file string) string {
+	return strings.TrimSuffix(filepath.Base(file), filepath.Ext(file)) + c.extension()
+}
+
+// plural formats a count with a singular or plural noun
+func plural(n int, noun string) string {
+	if n == 1 {
+		return fmt.Sprintf("1 %s", noun)
+	}
+	return fmt.Sprintf("%d %ss", n, noun)
+}
@@ -0,0 +1,139 @@
+// md2jira capabilities lists the constructs of a document that degrade on a Jira product
+
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/astsu-dev/md2jira/converter"
+)
+
+// capabilitiesReport is the --json output of md2jira capabilities for one file
+type capabilitiesReport struct {
+	File         string                  `json:"file"`
+	Target       string                  `json:"target"`
+	Degradations []converter.Degradation `json:"degradations"`
+}
+
+// runCapabilities runs the capabilities subcommand and returns the exit code:
+// exitOK when nothing degrades and exitWarnings otherwise
+func runCapabilities(args []string) int {
+	fs := flag.NewFlagSet("capabilities", flag.ContinueOnError)
+	targetName := fs.String("target", "dc", "Jira product: dc, cloud or jsm")
+	jsonOutput := fs.Bool("json", false, "Emit the degradations as JSON")
+	configFile := fs.String("config", "", "Configuration file")
+	meetingNotes := fs.Bool("meeting-notes", false, "Convert @mentions in action items")
+	mathMacro := fs.String("math-macro", "", "Macro math is rendered with")
+	mermaid := fs.String("mermaid", "code", "Render mermaid fences as code, macro or image")
+	plantUML := fs.String("plantuml", "code", "Render plantuml fences as code, macro or image")
+	fs.Usage = func() {
+		fmt.Fprintf(os.Stderr, `Usage:
+  md2jira capabilities --target dc|cloud|jsm [options] input.md...
+
+Lists every construct of the documents that the target cannot display as
+written, and what it shows instead, as file:line:column: construct → fallback:
+reason. Exits with 4 if anything degrades.
+
+Targets:
+  dc            Jira Server and Data Center (default)
+  cloud         Jira Cloud
+  jsm           The Jira Service Management customer portal
+
+Options:
+  --json        Emit the degradations as JSON
+  --config string
+                Read defaults from this file instead of .md2jira.yaml/.md2jira.toml
+  --meeting-notes
+                Check @mentions as converted in meeting notes mode
+  --math-macro string
+                Macro math is rendered with (default: none, math becomes code)
+  --mermaid string
+                Mermaid fence rendering: code (default), macro or image
+  --plantuml string
+                PlantUML fence rendering: code (default), macro or image
+`)
+	}
+	files, err := parseInterspersed(fs, args)
+	if err != nil {
+		if err == flag.ErrHelp {
+			return exitOK
+		}
+		return exitUsage
+	}
+	cfg, err := loadFlagConfig(fs, *configFile)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error reading config: %v\n", err)
+		return exitUsage
+	}
+	target, err := converter.ParseTarget(*targetName)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		return exitUsage
+	}
+	opts := converter.Options{
+		LanguageMap:  cfg.languages,
+		Mentions:     cfg.mentions,
+		MeetingNotes: *meetingNotes,
+		MathMacro:    *mathMacro,
+	}
+	if opts.Mermaid, err = converter.ParseDiagramMode(*mermaid); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		return exitUsage
+	}
+	if opts.PlantUML, err = converter.ParseDiagramMode(*plantUML); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		return exitUsage
+	}
+
+	if len(files) == 0 {
+		files = []string{"-"}
+	}
+	var reports []capabilitiesReport
+	found := false
+	for _, file := range files {
+		var input []byte
+		name := file
+		if file == "-" {
+			name = "<stdin>"
+			input, err = readStdin()
+		} else {
+			input, err = readSource(file)
+		}
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error reading %s: %v\n", name, err)
+			return exitIO
+		}
+		degradations, err := converter.Degradations(string(input), target, opts)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error checking %s: %v\n", name, err)
+			return exitConversion
+		}
+		found = found || len(degradations) > 0
+		if *jsonOutput {
+			reports = append(reports, capabilitiesReport{File: name, Target: target.String(), Degradations: degradations})
+			continue
+		}
+		for _, d := range degradations {
+			fmt.Printf("%s:%s\n", name, d)
+		}
+		if len(degradations) > 0 {
+			fmt.Printf("%s: %s degrade on %s\n", name, plural(len(degradations), "construct"), target)
+		}
+	}
+
+	if *jsonOutput {
+		encoder := json.NewEncoder(os.Stdout)
+		encoder.SetIndent("", "  ")
+		if err := encoder.Encode(reports); err != nil {
+			fmt.Fprintf(os.Stderr, "Error writing report: %v\n", err)
+			return exitIO
+		}
+	}
+	if found {
+		return exitWarnings
+	}
+	return exitOK
+}
@@ -0,0 +1,65 @@
+// Copying the output to the system clipboard with --clipboard
+
+package main
+
+import (
+	"bytes"
+	"errors"
+	"os"
+	"os/exec"
+	"runtime"
+	"strings"
+	"unicode/utf16"
+)
+
+// clipboardCommand returns the command that copies its standard input to the
+// clipboard: pbcopy on macOS, clip on Windows, and wl-copy, xclip or xsel on
+// other systems
+func clipboardCommand() (*exec.Cmd, error) {
+	switch runtime.GOOS {
+	case "darwin":
+		return exec.Command("pbcopy"), nil
+	case "windows":
+		return exec.Command("clip"), nil
+	}
+	candidates := [][]string{
+		{"xclip", "-selection", "clipboard"},
+		{"xsel", "--clipboard", "--input"},
+	}
+	if os.Getenv("WAYLAND_DISPLAY") != "" {
+		candidates = append([][]string{{"wl-copy"}}, candidates...)
+	}
+	for _, c := range candidates {
+		if path, err := exec.LookPath(c[0]); err == nil {
+			return exec.Command(path, c[1:]...), nil
+		}
+	}
+	return nil, errors.New("no clipboard tool found; install wl-copy, xclip or xsel")
+}
+
+// copyToClipboard puts text on the system clipboard
+func copyToClipboard(text string) error {
+	cmd, err := clipboardCommand()
+	if err != nil {
+		return err
+	}
+	input := []byte(text)
+	if runtime.GOOS == "windows" {
+		input = clipText(text)
+	}
+	cmd.Stdin = bytes.NewReader(input)
+	cmd.Stderr = os.Stderr
+	return cmd.Run()
+}
+
+// clipText encodes text for clip, which reads UTF-16 when its input starts
+// with a byte order mark and the console code page otherwise
+func clipText(text string) []byte {
+	text = strings.ReplaceAll(strings.ReplaceAll(text, "\r\n", "\n"), "\n", "\r\n")
+	units := utf16.Encode([]rune("\ufeff" + text))
+	data := make([]byte, 0, 2*len(units))
+	for _, u := range units {
+		data = append(data, byte(u), byte(u>>8))
+	}
+	return data
+}
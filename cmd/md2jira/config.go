@@ -0,0 +1,275 @@
+// Configuration files: defaults for flags loaded from .md2jira.yaml or .md2jira.toml
+
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"text/template"
+
+	"github.com/BurntSushi/toml"
+	"gopkg.in/yaml.v3"
+)
+
+// configNames are the configuration files looked for, in order of preference
+var configNames = []string{".md2jira.yaml", ".md2jira.yml", ".md2jira.toml"}
+
+// config holds the settings read from a configuration file. Keys other than
+// languages, mentions, header and footer are flag names (escape, format,
+// link-style, ...) and set the default of that flag, if it is one of
+// configFlags; flags given on the command line take precedence. mentions is a flag too when it names a
+// mapping file rather than holding the mapping.
+type config struct {
+	path string
+	// flags maps flag names to their configured values
+	flags map[string]interface{}
+	// languages overrides the code block language mapping
+	languages map[string]string
+	// mentions maps @handles to JIRA users in meeting notes, and with
+	// --mentions in all text
+	mentions map[string]string
+	// header and footer wrap every converted document
+	header *template.Template
+	footer *template.Template
+}
+
+// findConfig returns the first configuration file in the working directory,
+// then the home directory, or "" if there is none
+func findConfig() string {
+	dirs := []string{"."}
+	if home, err := os.UserHomeDir(); err == nil {
+		dirs = append(dirs, home)
+	}
+	for _, dir := range dirs {
+		for _, name := range configNames {
+			path := filepath.Join(dir, name)
+			if info, err := os.Stat(path); err == nil && !info.IsDir() {
+				return path
+			}
+		}
+	}
+	return ""
+}
+
+// loadConfig reads a YAML or TOML configuration file, chosen by extension
+func loadConfig(path string) (*config, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	values := make(map[string]interface{})
+	if strings.EqualFold(filepath.Ext(path), ".toml") {
+		err = toml.Unmarshal(data, &values)
+	} else {
+		err = yaml.Unmarshal(data, &values)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("%s: %v", path, err)
+	}
+
+	cfg := &config{path: path, flags: values}
+	if cfg.languages, err = configTable(values, "languages"); err != nil {
+		return nil, fmt.Errorf("%s: languages must map Markdown languages to JIRA languages", path)
+	}
+	// mentions: users.yaml names a mapping file and sets --mentions
+	if _, file := values["mentions"].(string); !file {
+		if cfg.mentions, err = configTable(values, "mentions"); err != nil {
+			return nil, fmt.Errorf("%s: mentions must map @handles to JIRA users or name a mapping file", path)
+		}
+	}
+	if cfg.header, err = configTemplate(values, "header"); err != nil {
+		return nil, fmt.Errorf("%s: %v", path, err)
+	}
+	if cfg.footer, err = configTemplate(values, "footer"); err != nil {
+		return nil, fmt.Errorf("%s: %v", path, err)
+	}
+	return cfg, nil
+}
+
+// loadMentions reads a YAML or TOML file mapping @handles to JIRA users,
+// chosen by extension
+func loadMentions(path string) (map[string]string, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	values := make(map[string]interface{})
+	if strings.EqualFold(filepath.Ext(path), ".toml") {
+		err = toml.Unmarshal(data, &values)
+	} else {
+		err = yaml.Unmarshal(data, &values)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("%s: %v", path, err)
+	}
+	mentions := make(map[string]string, len(values))
+	for handle, user := range values {
+		name, ok := user.(string)
+		if !ok {
+			return nil, fmt.Errorf("%s: @%s must map to a JIRA user name or accountid:...", path, handle)
+		}
+		mentions[strings.ToLower(strings.TrimPrefix(handle, "@"))] = name
+	}
+	return mentions, nil
+}
+
+// configTemplate removes a Markdown template from the configured values and
+// parses it (nil if it is not configured)
+func configTemplate(values map[string]interface{}, name string) (*template.Template, error) {
+	value, ok := values[name]
+	if !ok {
+		return nil, nil
+	}
+	delete(values, name)
+	text, ok := value.(string)
+	if !ok {
+		return nil, fmt.Errorf("%s must be a template string", name)
+	}
+	return template.New(name).Parse(text)
+}
+
+// configTable removes a table from the configured values and returns it with
+// lower-case keys (nil if it is not configured)
+func configTable(values map[string]interface{}, name string) (map[string]string, error) {
+	value, ok := values[name]
+	if !ok {
+		return nil, nil
+	}
+	delete(values, name)
+	table, ok := value.(map[string]interface{})
+	if !ok {
+		return nil, fmt.Errorf("%s is not a table", name)
+	}
+	entries := make(map[string]string, len(table))
+	for key, entry := range table {
+		entries[strings.ToLower(key)] = fmt.Sprint(entry)
+	}
+	return entries, nil
+}
+
+// configFlags are the flags a configuration file may set: those that only
+// change how documents are rendered and reported. A file in the working
+// directory may come with an untrusted checkout, so output paths, commands,
+// URLs and the JIRA, GitHub and TLS settings can only be given on the command
+// line.
+var configFlags = map[string]bool{
+	"alert-after":           true,
+	"check-links":           true,
+	"collapse-code-over":    true,
+	"compat":                true,
+	"detect-traces":         true,
+	"dialect":               true,
+	"diff":                  true,
+	"emoticons":             true,
+	"enrich-links":          true,
+	"eol":                   true,
+	"escape":                true,
+	"escape-style":          true,
+	"fail-on-warning":       true,
+	"fail-under":            true,
+	"format":                true,
+	"hard-break":            true,
+	"heading-offset":        true,
+	"image-width":           true,
+	"inline-footnote-max":   true,
+	"inline-footnotes":      true,
+	"interval":              true,
+	"jitter":                true,
+	"join-lines":            true,
+	"json":                  true,
+	"link-style":            true,
+	"math-macro":            true,
+	"media-macro":           true,
+	"meeting-notes":         true,
+	"mentions":              true,
+	"mermaid-macro":         true,
+	"normalize-punctuation": true,
+	"plantuml-macro":        true,
+	"preserve-spacers":      true,
+	"profile-blocks":        true,
+	"provenance":            true,
+	"roadmap":               true,
+	"roadmap-template":      true,
+	"runbook":               true,
+	"safe-mode":             true,
+	"sanitize-html":         true,
+	"soft-break":            true,
+	"spellcheck-dict":       true,
+	"stats":                 true,
+	"strip-title":           true,
+	"symbols":               true,
+	"target":                true,
+	"task-style":            true,
+	"thumbnail":             true,
+	"timeline":              true,
+	"timeline-tz":           true,
+	"toc":                   true,
+	"toc-max-level":         true,
+	"toc-min-level":         true,
+	"verbose":               true,
+}
+
+// apply sets the configured flags that were not given on the command line.
+// Keys that are not flags of fs are ignored, so one file can configure the
+// converter and every subcommand; flags that are not configFlags are errors.
+func (c *config) apply(fs *flag.FlagSet) error {
+	given := make(map[string]bool)
+	fs.Visit(func(f *flag.Flag) { given[f.Name] = true })
+
+	names := make([]string, 0, len(c.flags))
+	for name := range c.flags {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		if fs.Lookup(name) == nil {
+			continue
+		}
+		if !configFlags[name] {
+			dashes := "--"
+			if len(name) == 1 {
+				dashes = "-"
+			}
+			return fmt.Errorf("%s: %s cannot be set in a configuration file; pass %s%s on the command line", c.path, name, dashes, name)
+		}
+		if given[name] {
+			continue
+		}
+		if err := fs.Set(name, configValue(c.flags[name])); err != nil {
+			return fmt.Errorf("%s: %s: %v", c.path, name, err)
+		}
+	}
+	return nil
+}
+
+// configValue formats a configured value as a flag value; lists become
+// comma-separated values
+func configValue(value interface{}) string {
+	if list, ok := value.([]interface{}); ok {
+		items := make([]string, len(list))
+		for i, item := range list {
+			items[i] = fmt.Sprint(item)
+		}
+		return strings.Join(items, ",")
+	}
+	return fmt.Sprint(value)
+}
+
+// loadFlagConfig loads the configuration file named by path, or the one found
+// by findConfig when path is empty, and applies it to fs
+func loadFlagConfig(fs *flag.FlagSet, path string) (*config, error) {
+	if path == "" {
+		if path = findConfig(); path == "" {
+			return &config{}, nil
+		}
+	}
+	cfg, err := loadConfig(path)
+	if err != nil {
+		return nil, err
+	}
+	return cfg, cfg.apply(fs)
+}
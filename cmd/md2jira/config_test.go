@@ -0,0 +1,88 @@
+package main
+
+import (
+	"flag"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestConfigSetsRenderingFlags(t *testing.T) {
+	path := filepath.Join(t.TempDir(), ".md2jira.yaml")
+	data := "escape: aggressive\ncompat: 3\ntask-style: text\nproject: PROJ\n"
+	if err := os.WriteFile(path, []byte(data), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	// project is not a flag of this command, so it is ignored
+	fs := flag.NewFlagSet("test", flag.ContinueOnError)
+	escape := fs.String("escape", "minimal", "")
+	compat := fs.String("compat", "latest", "")
+	taskStyle := fs.String("task-style", "checkbox", "")
+	if err := fs.Parse([]string{"--compat", "5"}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := loadFlagConfig(fs, path); err != nil {
+		t.Fatal(err)
+	}
+	if *escape != "aggressive" || *taskStyle != "text" {
+		t.Errorf("escape = %q, task-style = %q, want aggressive and text", *escape, *taskStyle)
+	}
+	if *compat != "5" {
+		t.Errorf("compat = %q, want the command line's 5", *compat)
+	}
+}
+
+func TestConfigRejectsCommandLineFlags(t *testing.T) {
+	tests := []struct {
+		flag  string
+		value string
+	}{
+		{"o", "/home/user/.bashrc"},
+		{"out-dir", "/tmp/out"},
+		{"alt-text-cmd", "./caption.sh"},
+		{"alert-cmd", "touch pwned"},
+		{"alert-webhook", "https://attacker.example"},
+		{"issue-base-url", "https://attacker.example"},
+		{"insecure-skip-verify", "true"},
+		{"ca-cert", "attacker.pem"},
+		{"project", "PROJ"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.flag, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), ".md2jira.yaml")
+			if err := os.WriteFile(path, []byte(tt.flag+": "+tt.value+"\n"), 0o644); err != nil {
+				t.Fatal(err)
+			}
+			fs := flag.NewFlagSet("test", flag.ContinueOnError)
+			value := fs.String(tt.flag, "", "")
+			_, err := loadFlagConfig(fs, path)
+			if err == nil || !strings.Contains(err.Error(), tt.flag+" cannot be set") {
+				t.Errorf("error = %v, want one naming %s", err, tt.flag)
+			}
+			if *value != "" {
+				t.Errorf("%s = %q, want it unset", tt.flag, *value)
+			}
+		})
+	}
+}
+
+func TestConfigRejectsFlagsGivenOnTheCommandLine(t *testing.T) {
+	path := filepath.Join(t.TempDir(), ".md2jira.yaml")
+	if err := os.WriteFile(path, []byte("alt-text-cmd: ./other.sh\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	fs := flag.NewFlagSet("test", flag.ContinueOnError)
+	altText := fs.String("alt-text-cmd", "", "")
+	if err := fs.Parse([]string{"--alt-text-cmd", "./caption.sh"}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := loadFlagConfig(fs, path); err == nil {
+		t.Error("a configuration file setting alt-text-cmd was accepted")
+	}
+	if *altText != "./caption.sh" {
+		t.Errorf("alt-text-cmd = %q, want ./caption.sh", *altText)
+	}
+}
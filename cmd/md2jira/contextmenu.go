@@ -0,0 +1,375 @@
+// md2jira install-context-menu adds a "Convert to JIRA markup" entry for
+// Markdown files to the file manager
+
+package main
+
+import (
+	"encoding/xml"
+	"flag"
+	"fmt"
+	"io/fs"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strings"
+)
+
+// defaultMenuLabel is the label of the context menu entry
+const defaultMenuLabel = "Convert to JIRA markup → clipboard"
+
+// contextMenu is how an entry is added to the file manager of a platform
+type contextMenu struct {
+	// files are written on install; removed are deleted on uninstall
+	files   []menuFile
+	removed []string
+	// install and uninstall are the commands run after writing or deleting
+	install   [][]string
+	uninstall [][]string
+	// note is printed after installing
+	note string
+}
+
+// menuFile is a file written on install
+type menuFile struct {
+	path    string
+	content string
+	mode    fs.FileMode
+}
+
+// runInstallContextMenu runs the install-context-menu subcommand and returns
+// the exit code
+func runInstallContextMenu(args []string) int {
+	fs := flag.NewFlagSet("install-context-menu", flag.ContinueOnError)
+	label := fs.String("label", defaultMenuLabel, "Label of the menu entry")
+	uninstall := fs.Bool("uninstall", false, "Remove the menu entry")
+	dryRun := fs.Bool("dry-run", false, "Print the files and commands without changing anything")
+	fs.Usage = func() {
+		fmt.Fprintf(os.Stderr, `Usage:
+  md2jira install-context-menu [options]
+
+Adds an entry to the right-click menu of .md and .markdown files that
+converts the file and copies the markup to the clipboard, running
+md2jira --no-config --clipboard with this md2jira executable. No
+configuration file is read, as the file may come from anywhere, such as a
+download or a checkout.
+
+  Windows   a shell verb in HKEY_CURRENT_USER\Software\Classes
+  macOS     a Finder Quick Action in ~/Library/Services
+  Linux     a Nautilus script and a KDE Dolphin service menu
+
+Options:
+  --label string
+                Label of the menu entry (default: %s)
+  --uninstall   Remove the menu entry
+  --dry-run     Print the files and commands without changing anything
+`, defaultMenuLabel)
+	}
+	rest, err := parseInterspersed(fs, args)
+	if err != nil {
+		if err == flag.ErrHelp {
+			return exitOK
+		}
+		return exitUsage
+	}
+	if len(rest) != 0 || strings.TrimSpace(*label) == "" {
+		fs.Usage()
+		return exitUsage
+	}
+
+	exe, err := os.Executable()
+	if err == nil {
+		exe, err = filepath.EvalSymlinks(exe)
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: locating the md2jira executable: %v\n", err)
+		return exitIO
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		return exitIO
+	}
+	var menu contextMenu
+	switch runtime.GOOS {
+	case "windows":
+		menu = windowsMenu(exe, *label)
+	case "darwin":
+		menu = macMenu(home, exe, *label)
+	default:
+		dataHome := os.Getenv("XDG_DATA_HOME")
+		if dataHome == "" {
+			dataHome = filepath.Join(home, ".local", "share")
+		}
+		menu = linuxMenu(dataHome, exe, *label)
+	}
+
+	if *uninstall {
+		return removeMenu(menu, *dryRun)
+	}
+	return installMenu(menu, *dryRun)
+}
+
+// installMenu writes the files of a menu entry and runs its install commands
+func installMenu(menu contextMenu, dryRun bool) int {
+	for _, f := range menu.files {
+		if dryRun {
+			fmt.Fprintf(os.Stderr, "Would write %s:\n%s\n", f.path, f.content)
+			continue
+		}
+		if err := os.MkdirAll(filepath.Dir(f.path), 0755); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			return exitIO
+		}
+		if err := os.WriteFile(f.path, []byte(f.content), f.mode); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			return exitIO
+		}
+		// WriteFile keeps the mode of an existing file
+		if err := os.Chmod(f.path, f.mode); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			return exitIO
+		}
+		fmt.Fprintf(os.Stderr, "Wrote %s\n", f.path)
+	}
+	if code := runMenuCommands(menu.install, dryRun); code != exitOK {
+		return code
+	}
+	if !dryRun && menu.note != "" {
+		fmt.Fprintln(os.Stderr, menu.note)
+	}
+	return exitOK
+}
+
+// removeMenu deletes the files of a menu entry and runs its uninstall commands
+func removeMenu(menu contextMenu, dryRun bool) int {
+	for _, path := range menu.removed {
+		if dryRun {
+			fmt.Fprintf(os.Stderr, "Would remove %s\n", path)
+			continue
+		}
+		if _, err := os.Lstat(path); os.IsNotExist(err) {
+			continue
+		}
+		if err := os.RemoveAll(path); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			return exitIO
+		}
+		fmt.Fprintf(os.Stderr, "Removed %s\n", path)
+	}
+	return runMenuCommands(menu.uninstall, dryRun)
+}
+
+// runMenuCommands runs the commands of a menu entry, or prints them in a dry run
+func runMenuCommands(commands [][]string, dryRun bool) int {
+	for _, command := range commands {
+		if dryRun {
+			fmt.Fprintf(os.Stderr, "Would run %s\n", strings.Join(command, " "))
+			continue
+		}
+		cmd := exec.Command(command[0], command[1:]...)
+		cmd.Stderr = os.Stderr
+		if err := cmd.Run(); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %s: %v\n", strings.Join(command, " "), err)
+			return exitIO
+		}
+	}
+	return exitOK
+}
+
+// markdownExtensions are the file types that get the menu entry
+var markdownExtensions = []string{".md", ".markdown"}
+
+// windowsMenu adds a shell verb to the Markdown file types of the user
+func windowsMenu(exe, label string) contextMenu {
+	var menu contextMenu
+	for _, ext := range markdownExtensions {
+		key := `HKCU\Software\Classes\SystemFileAssociations\` + ext + `\shell\md2jira`
+		menu.install = append(menu.install,
+			[]string{"reg", "add", key, "/ve", "/d", label, "/f"},
+			[]string{"reg", "add", key, "/v", "Icon", "/d", exe, "/f"},
+			[]string{"reg", "add", key + `\command`, "/ve", "/d", `"` + exe + `" --no-config --clipboard "%1"`, "/f"},
+		)
+		menu.uninstall = append(menu.uninstall, []string{"reg", "delete", key, "/f"})
+	}
+	return menu
+}
+
+// macMenu adds a Finder Quick Action, an Automator workflow running a shell
+// script on the selected file
+func macMenu(home, exe, label string) contextMenu {
+	bundle := filepath.Join(home, "Library", "Services", strings.ReplaceAll(label, "/", "-")+".workflow")
+	script := shellQuote(exe) + ` --no-config --clipboard "$1"`
+	return contextMenu{
+		files: []menuFile{
+			{path: filepath.Join(bundle, "Contents", "Info.plist"), content: fmt.Sprintf(macInfoPlist, xmlText(label)), mode: 0644},
+			{path: filepath.Join(bundle, "Contents", "document.wflow"), content: fmt.Sprintf(macWorkflow, xmlText(script)), mode: 0644},
+		},
+		removed: []string{bundle},
+		note:    "The Quick Action is listed under Quick Actions in the Finder context menu; it may take a new login to appear.",
+	}
+}
+
+// linuxMenu adds a Nautilus script and a Dolphin service menu
+func linuxMenu(dataHome, exe, label string) contextMenu {
+	script := filepath.Join(dataHome, "nautilus", "scripts", strings.ReplaceAll(label, "/", "-"))
+	service := filepath.Join(dataHome, "kio", "servicemenus", "md2jira.desktop")
+	return contextMenu{
+		files: []menuFile{
+			{path: script, content: fmt.Sprintf(nautilusScript, shellQuote(exe)), mode: 0755},
+			{path: service, content: fmt.Sprintf(dolphinServiceMenu, label, desktopExecQuote(exe)), mode: 0755},
+		},
+		removed: []string{script, service},
+		note:    "Nautilus lists the entry under Scripts; Dolphin in the context menu of Markdown files.",
+	}
+}
+
+// nautilusScript converts the first selected file
+const nautilusScript = `#!/bin/sh
+# Installed by md2jira install-context-menu
+exec %s --no-config --clipboard "$1"
+`
+
+// dolphinServiceMenu is a KDE service menu for Markdown files
+const dolphinServiceMenu = `[Desktop Entry]
+Type=Service
+MimeType=text/markdown;text/x-markdown;
+Actions=md2jira;
+X-KDE-Priority=TopLevel
+
+[Desktop Action md2jira]
+Name=%s
+Icon=edit-copy
+Exec=%s --no-config --clipboard %%f
+`
+
+// macInfoPlist declares the Quick Action as a Finder service for Markdown
+// and plain text files
+const macInfoPlist = `<?xml version="1.0" encoding="UTF-8"?>
+<!DOCTYPE plist PUBLIC "-//Apple//DTD PLIST 1.0//EN" "http://www.apple.com/DTDs/PropertyList-1.0.dtd">
+<plist version="1.0">
+<dict>
+	<key>NSServices</key>
+	<array>
+		<dict>
+			<key>NSMenuItem</key>
+			<dict>
+				<key>default</key>
+				<string>%s</string>
+			</dict>
+			<key>NSMessage</key>
+			<string>runWorkflowAsService</string>
+			<key>NSRequiredContext</key>
+			<dict>
+				<key>NSApplicationIdentifier</key>
+				<string>com.apple.finder</string>
+			</dict>
+			<key>NSSendFileTypes</key>
+			<array>
+				<string>net.daringfireball.markdown</string>
+				<string>public.plain-text</string>
+			</array>
+		</dict>
+	</array>
+</dict>
+</plist>
+`
+
+// macWorkflow is an Automator service running a shell script with the
+// selected files as arguments
+const macWorkflow = `<?xml version="1.0" encoding="UTF-8"?>
+<!DOCTYPE plist PUBLIC "-//Apple//DTD PLIST 1.0//EN" "http://www.apple.com/DTDs/PropertyList-1.0.dtd">
+<plist version="1.0">
+<dict>
+	<key>AMApplicationBuild</key>
+	<string>521</string>
+	<key>AMApplicationVersion</key>
+	<string>2.10</string>
+	<key>AMDocumentVersion</key>
+	<string>2</string>
+	<key>actions</key>
+	<array>
+		<dict>
+			<key>action</key>
+			<dict>
+				<key>AMAccepts</key>
+				<dict>
+					<key>Container</key>
+					<string>List</string>
+					<key>Optional</key>
+					<true/>
+					<key>Types</key>
+					<array>
+						<string>com.apple.cocoa.path</string>
+					</array>
+				</dict>
+				<key>AMActionVersion</key>
+				<string>2.0.3</string>
+				<key>AMProvides</key>
+				<dict>
+					<key>Container</key>
+					<string>List</string>
+					<key>Types</key>
+					<array>
+						<string>com.apple.cocoa.string</string>
+					</array>
+				</dict>
+				<key>ActionBundlePath</key>
+				<string>/System/Library/Automator/Run Shell Script.action</string>
+				<key>ActionName</key>
+				<string>Run Shell Script</string>
+				<key>ActionParameters</key>
+				<dict>
+					<key>COMMAND_STRING</key>
+					<string>%s</string>
+					<key>CheckedForUserDefaultShell</key>
+					<true/>
+					<key>inputMethod</key>
+					<integer>1</integer>
+					<key>shell</key>
+					<string>/bin/sh</string>
+					<key>source</key>
+					<string></string>
+				</dict>
+				<key>BundleIdentifier</key>
+				<string>com.apple.RunShellScript</string>
+				<key>CFBundleVersion</key>
+				<string>2.0.3</string>
+				<key>Class Name</key>
+				<string>RunShellScriptAction</string>
+			</dict>
+		</dict>
+	</array>
+	<key>connectors</key>
+	<dict/>
+	<key>workflowMetaData</key>
+	<dict>
+		<key>serviceInputTypeIdentifier</key>
+		<string>com.apple.Automator.fileSystemObject</string>
+		<key>serviceOutputTypeIdentifier</key>
+		<string>com.apple.Automator.nothing</string>
+		<key>serviceProcessesInput</key>
+		<integer>0</integer>
+		<key>workflowTypeIdentifier</key>
+		<string>com.apple.Automator.servicesMenu</string>
+	</dict>
+</dict>
+</plist>
+`
+
+// shellQuote quotes a word for sh
+func shellQuote(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
+
+// desktopExecQuote quotes a program for the Exec key of a desktop entry
+func desktopExecQuote(s string) string {
+	return `"` + strings.NewReplacer(`\`, `\\\\`, `"`, `\\"`, "`", "\\\\`", "$", `\\$`, "%", "%%").Replace(s) + `"`
+}
+
+// xmlText escapes text for an XML element
+func xmlText(s string) string {
+	var b strings.Builder
+	_ = xml.EscapeText(&b, []byte(s))
+	return b.String()
+}
@@ -0,0 +1,43 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestContextMenuSkipsConfiguration(t *testing.T) {
+	tests := []struct {
+		name string
+		menu contextMenu
+	}{
+		{"windows", windowsMenu(`C:\md2jira.exe`, "Convert")},
+		{"macos", macMenu("/Users/a", "/usr/local/bin/md2jira", "Convert")},
+		{"linux", linuxMenu("/home/a/.local/share", "/usr/bin/md2jira", "Convert")},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var entries []string
+			for _, f := range tt.menu.files {
+				entries = append(entries, f.content)
+			}
+			for _, cmd := range tt.menu.install {
+				entries = append(entries, strings.Join(cmd, " "))
+			}
+			runs := 0
+			for _, entry := range entries {
+				for _, line := range strings.Split(entry, "\n") {
+					if !strings.Contains(line, "--clipboard") {
+						continue
+					}
+					runs++
+					if !strings.Contains(line, "--no-config --clipboard") {
+						t.Errorf("entry runs md2jira with configuration files: %s", line)
+					}
+				}
+			}
+			if runs == 0 {
+				t.Error("no entry runs md2jira --clipboard")
+			}
+		})
+	}
+}
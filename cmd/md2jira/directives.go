@@ -0,0 +1,130 @@
+// Directives sidecars: how lossy constructs of an input are rendered, chosen
+// with --interactive and read from foo.directives.yaml on every later run
+
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/astsu-dev/md2jira/converter"
+	"gopkg.in/yaml.v3"
+)
+
+// snippetLines is the most source lines shown for a construct, which ends at
+// the first blank line
+const snippetLines = 6
+
+// directivesPath returns the sidecar of an input (foo.md -> foo.directives.yaml)
+func directivesPath(file string) string {
+	return strings.TrimSuffix(file, filepath.Ext(file)) + ".directives.yaml"
+}
+
+// loadDirectives reads a directives sidecar; a missing sidecar has no directives
+func loadDirectives(file string) (converter.Directives, error) {
+	data, err := os.ReadFile(file)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var directives converter.Directives
+	if err := yaml.Unmarshal(data, &directives); err != nil {
+		return nil, fmt.Errorf("%s: %v", file, err)
+	}
+	return directives, nil
+}
+
+// saveDirectives writes a directives sidecar for input
+func saveDirectives(file, input string, directives converter.Directives) error {
+	data, err := yaml.Marshal(directives)
+	if err != nil {
+		return err
+	}
+	header := fmt.Sprintf("# How md2jira renders the constructs of %s; written by --interactive\n", filepath.Base(input))
+	return os.WriteFile(file, append([]byte(header), data...), 0644)
+}
+
+// directives returns the directives of an input file. In interactive mode
+// every warning that offers choices is asked about on stderr, the answers are
+// read from stdin and the sidecar is updated with them.
+func (c conversion) directives(file string, input []byte, opts converter.Options) (converter.Directives, error) {
+	sidecar := directivesPath(file)
+	directives, err := loadDirectives(sidecar)
+	if err != nil || !c.interactive {
+		return directives, err
+	}
+
+	opts.WarnOnUnsupported = true
+	opts.Directives = directives
+	_, result, err := c.run(input, opts)
+	if err != nil {
+		return nil, err
+	}
+	lines := strings.Split(string(input), "\n")
+	asked := 0
+	for _, w := range result.Warnings {
+		if len(w.Choices) == 0 {
+			continue
+		}
+		choice, err := c.ask(file, lines, w)
+		if err != nil {
+			return nil, err
+		}
+		if directives == nil {
+			directives = converter.Directives{}
+		}
+		directives[w.Key] = choice
+		asked++
+	}
+	if asked == 0 {
+		return directives, nil
+	}
+	if err := saveDirectives(sidecar, file, directives); err != nil {
+		return nil, err
+	}
+	fmt.Fprintf(os.Stderr, "Saved %s to %s\n", plural(asked, "directive"), sidecar)
+	return directives, nil
+}
+
+// ask shows a warning with the source of its construct and reads the number
+// or name of a choice; an empty answer picks the first choice
+func (c conversion) ask(file string, lines []string, w converter.Warning) (string, error) {
+	fmt.Fprintln(os.Stderr, formatWarning(file, w))
+	for i := w.Line; i > 0 && i <= len(lines) && i < w.Line+snippetLines && strings.TrimSpace(lines[i-1]) != ""; i++ {
+		fmt.Fprintf(os.Stderr, "  %5d | %s\n", i, lines[i-1])
+	}
+	for i, choice := range w.Choices {
+		fmt.Fprintf(os.Stderr, "  %d) %s\n", i+1, choice)
+	}
+	for {
+		fmt.Fprintf(os.Stderr, "Choice [1-%d, default %s]: ", len(w.Choices), w.Choices[0])
+		answer, err := c.answers.ReadString('\n')
+		if err != nil && (err != io.EOF || answer == "") {
+			if err == io.EOF {
+				fmt.Fprintln(os.Stderr)
+				return "", fmt.Errorf("no answer for %s:%d", file, w.Line)
+			}
+			return "", err
+		}
+		answer = strings.TrimSpace(answer)
+		if answer == "" {
+			return w.Choices[0], nil
+		}
+		if n, err := strconv.Atoi(answer); err == nil && n >= 1 && n <= len(w.Choices) {
+			return w.Choices[n-1], nil
+		}
+		for _, choice := range w.Choices {
+			if strings.EqualFold(answer, choice) {
+				return choice, nil
+			}
+		}
+		fmt.Fprintf(os.Stderr, "Unknown choice %q\n", answer)
+	}
+}
@@ -0,0 +1,230 @@
+// md2jira from-pr copies a GitHub pull request description into a JIRA issue
+
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"net/http"
+	"os"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/astsu-dev/md2jira/converter"
+	"github.com/astsu-dev/md2jira/jiraescape"
+)
+
+// pullURLRe matches a GitHub pull request URL, capturing the host, repository and number
+var pullURLRe = regexp.MustCompile(`^https?://([^/]+)/([^/]+/[^/]+)/pull/(\d+)`)
+
+// issueKeyRe matches a JIRA issue key such as PROJ-123
+var issueKeyRe = regexp.MustCompile(`\b[A-Z][A-Z0-9_]+-[1-9][0-9]*\b`)
+
+// pullRequest is the subset of a GitHub pull request used by from-pr
+type pullRequest struct {
+	Title   string `json:"title"`
+	Body    string `json:"body"`
+	HTMLURL string `json:"html_url"`
+	Head    struct {
+		Ref string `json:"ref"`
+	} `json:"head"`
+	User struct {
+		Login string `json:"login"`
+	} `json:"user"`
+}
+
+// pullCommit is the subset of a pull request commit used by from-pr
+type pullCommit struct {
+	SHA    string `json:"sha"`
+	Commit struct {
+		Message string `json:"message"`
+	} `json:"commit"`
+}
+
+// runFromPR runs the from-pr subcommand and returns the exit code
+func runFromPR(args []string) int {
+	fs := flag.NewFlagSet("from-pr", flag.ContinueOnError)
+	issue := fs.String("issue", "", "Issue to post to (default: the first issue key in the PR title, branch or body)")
+	commits := fs.Bool("commits", false, "Append the list of commits")
+	description := fs.Bool("description", false, "Replace the issue description instead of adding a comment")
+	configFile := fs.String("config", "", "Configuration file")
+	remote := addRemoteFlags(fs)
+	dryRun := fs.Bool("dry-run", false, "Print the converted description without contacting JIRA")
+	apiURL := fs.String("github-api-url", os.Getenv("GITHUB_API_URL"), "GitHub API of the pull request, which GITHUB_TOKEN is sent to")
+	fs.Usage = func() {
+		fmt.Fprintf(os.Stderr, `Usage:
+  md2jira from-pr [options] https://github.com/org/repo/pull/123
+
+Fetches the pull request title and body from the GitHub API, converts them
+and adds them to the linked JIRA issue as a comment. GITHUB_TOKEN is used for
+private repositories, and only sent to api.github.com and the API given with
+--github-api-url. JIRA_URL, JIRA_USER and JIRA_TOKEN configure the JIRA
+connection.
+
+Options:
+  --issue string
+                Issue to post to (default: the first issue key found in the
+                PR title, branch name or body)
+  --commits     Append the list of commits
+  --description Replace the issue description instead of adding a comment
+  --github-api-url string
+                API of a GitHub Enterprise host, which GITHUB_TOKEN is also
+                sent to (default: $GITHUB_API_URL, or https://<host>/api/v3
+                without the token); only taken from the command line
+`+remoteUsage+`  --config string
+                Read defaults from this file instead of .md2jira.yaml/.md2jira.toml
+  --dry-run     Print the converted description without contacting JIRA
+`)
+	}
+	positional, err := parseInterspersed(fs, args)
+	if err != nil {
+		if err == flag.ErrHelp {
+			return exitOK
+		}
+		return exitUsage
+	}
+	if len(positional) != 1 {
+		fs.Usage()
+		return exitUsage
+	}
+	cfg, err := loadFlagConfig(fs, *configFile)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error reading config: %v\n", err)
+		return exitUsage
+	}
+
+	m := pullURLRe.FindStringSubmatch(positional[0])
+	if m == nil {
+		fmt.Fprintf(os.Stderr, "Error: %s is not a GitHub pull request URL\n", positional[0])
+		return exitUsage
+	}
+	github, err := remote.httpClient(30 * time.Second)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		return exitUsage
+	}
+	api := githubAPI(m[1], *apiURL)
+	token := githubToken(api, *apiURL)
+	var pr pullRequest
+	if err := githubGet(github, api+"/repos/"+m[2]+"/pulls/"+m[3], token, &pr); err != nil {
+		fmt.Fprintf(os.Stderr, "Error fetching pull request: %v\n", err)
+		return exitIO
+	}
+
+	var source strings.Builder
+	source.WriteString(pr.Body)
+	if *commits {
+		var list []pullCommit
+		if err := githubGet(github, api+"/repos/"+m[2]+"/pulls/"+m[3]+"/commits?per_page=100", token, &list); err != nil {
+			fmt.Fprintf(os.Stderr, "Error fetching commits: %v\n", err)
+			return exitIO
+		}
+		source.WriteString("\n\n### Commits\n\n")
+		for _, c := range list {
+			subject, _, _ := strings.Cut(c.Commit.Message, "\n")
+			fmt.Fprintf(&source, "- `%.7s` %s\n", c.SHA, subject)
+		}
+	}
+
+	result, err := converter.ConvertWithOptions(source.String(), converter.Options{
+		WarnOnUnsupported: true,
+		LanguageMap:       cfg.languages,
+		Header:            cfg.header,
+		Footer:            cfg.footer,
+	})
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error converting: %v\n", err)
+		return exitConversion
+	}
+	for _, w := range result.Warnings {
+		fmt.Fprintln(os.Stderr, formatWarning(pr.HTMLURL, w))
+	}
+	// Titles and logins are plain text; issue keys in the title stay linked
+	text := "h2. [" + jiraescape.Escape(pr.Title, jiraescape.LinkLabel, jiraescape.Minimal) + "|" + pr.HTMLURL + "]\n\n"
+	if pr.User.Login != "" {
+		text += "_Pull request by " + jiraescape.Escape(pr.User.Login, 0, jiraescape.Minimal) + "_\n\n"
+	}
+	text += result.Output
+
+	if *issue == "" {
+		for _, candidate := range []string{pr.Title, pr.Head.Ref, pr.Body} {
+			if key := issueKeyRe.FindString(candidate); key != "" {
+				*issue = key
+				break
+			}
+		}
+	}
+	if *dryRun {
+		if *issue != "" {
+			fmt.Fprintf(os.Stderr, "Linked issue: %s\n", *issue)
+		}
+		fmt.Println(text)
+		return exitOK
+	}
+	if *issue == "" {
+		fmt.Fprintln(os.Stderr, "Error: no issue key found in the pull request; use --issue")
+		return exitUsage
+	}
+
+	client, err := remote.jiraClient()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		return exitUsage
+	}
+	if *description {
+		err = client.UpdateDescription(*issue, text)
+	} else {
+		err = client.AddComment(*issue, text)
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		return exitIO
+	}
+	fmt.Fprintf(os.Stderr, "Posted %s to %s\n", pr.HTMLURL, *issue)
+	return exitOK
+}
+
+// githubAPI returns the REST API root for a GitHub host: apiURL when set
+func githubAPI(host, apiURL string) string {
+	if apiURL != "" {
+		return strings.TrimRight(apiURL, "/")
+	}
+	if host == "github.com" || host == "www.github.com" {
+		return "https://api.github.com"
+	}
+	return "https://" + host + "/api/v3"
+}
+
+// githubToken returns GITHUB_TOKEN when api is the API of github.com or the
+// one given with --github-api-url; the host of a pull request URL alone is
+// not trusted with it
+func githubToken(api, apiURL string) string {
+	if api != "https://api.github.com" && apiURL == "" {
+		return ""
+	}
+	return os.Getenv("GITHUB_TOKEN")
+}
+
+// githubGet fetches a GitHub API resource into out, authorized with token
+// unless it is empty
+func githubGet(client *http.Client, url, token string, out interface{}) error {
+	req, err := http.NewRequest(http.MethodGet, url, nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Accept", "application/vnd.github+json")
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("GET %s: %s", url, resp.Status)
+	}
+	return json.NewDecoder(resp.Body).Decode(out)
+}
@@ -0,0 +1,118 @@
+package main
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// fakeGitHub serves one pull request from a TLS test server, recording the
+// Authorization headers it receives
+type fakeGitHub struct {
+	*httptest.Server
+	mu    sync.Mutex
+	auths []string
+}
+
+func newFakeGitHub(t *testing.T) *fakeGitHub {
+	t.Helper()
+	f := &fakeGitHub{}
+	f.Server = httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		f.mu.Lock()
+		f.auths = append(f.auths, r.Header.Get("Authorization"))
+		f.mu.Unlock()
+		switch r.URL.Path {
+		case "/api/v3/repos/org/repo/pulls/5":
+			fmt.Fprintf(w, `{"title":"PROJ-7: fix [x|y] *b*","body":"Fixes *it*.","html_url":%q,"user":{"login":"dependabot[bot]"}}`, f.URL+"/org/repo/pull/5")
+		case "/api/v3/repos/org/repo/pulls/5/commits":
+			fmt.Fprint(w, `[{"sha":"0123456789abcdef","commit":{"message":"Fix it\n\nDetails"}}]`)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	t.Cleanup(f.Close)
+	return f
+}
+
+// authorizations returns the Authorization headers of the requests so far
+func (f *fakeGitHub) authorizations() []string {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return append([]string(nil), f.auths...)
+}
+
+func TestFromPRPostsEscapedComment(t *testing.T) {
+	jira := newFakeJIRA(t)
+	jira.addIssue("PROJ-7", map[string]any{"summary": "Fix"})
+	github := newFakeGitHub(t)
+	ca := writePEM(t, t.TempDir(), "ca.pem", "CERTIFICATE", github.Certificate().Raw)
+	t.Setenv("GITHUB_TOKEN", "secret")
+	t.Setenv("GITHUB_API_URL", "")
+
+	args := []string{"--ca-cert", ca, "--commits", "--github-api-url", github.URL + "/api/v3/", github.URL + "/org/repo/pull/5"}
+	if code := runFromPR(args); code != exitOK {
+		t.Fatalf("from-pr exited with %d", code)
+	}
+	comments := jira.commentsOf("PROJ-7")
+	if len(comments) != 1 {
+		t.Fatalf("comments = %q, want one", comments)
+	}
+	want := "h2. [PROJ-7: fix \\[x\\|y\\] \\*b*|" + github.URL + "/org/repo/pull/5]\n\n" +
+		"_Pull request by dependabot\\[bot]_\n\n" +
+		"Fixes _it_.\n\nh3. Commits\n\n* {{0123456}} Fix it"
+	if comments[0] != want {
+		t.Errorf("comment = %q, want %q", comments[0], want)
+	}
+	for _, auth := range github.authorizations() {
+		if auth != "Bearer secret" {
+			t.Errorf("Authorization = %q, want GITHUB_TOKEN for --github-api-url", auth)
+		}
+	}
+}
+
+func TestFromPRKeepsTokenFromUnnamedHosts(t *testing.T) {
+	jira := newFakeJIRA(t)
+	jira.addIssue("PROJ-7", map[string]any{"summary": "Fix"})
+	github := newFakeGitHub(t)
+	ca := writePEM(t, t.TempDir(), "ca.pem", "CERTIFICATE", github.Certificate().Raw)
+	t.Setenv("GITHUB_TOKEN", "secret")
+	t.Setenv("GITHUB_API_URL", "")
+
+	// The API of the pull request's host is derived from its URL
+	if code := runFromPR([]string{"--ca-cert", ca, "--description", github.URL + "/org/repo/pull/5"}); code != exitOK {
+		t.Fatalf("from-pr exited with %d", code)
+	}
+	if got := jira.issue("PROJ-7")["description"]; !strings.HasSuffix(fmt.Sprint(got), "Fixes _it_.") {
+		t.Errorf("description = %q", got)
+	}
+	auths := github.authorizations()
+	if len(auths) == 0 {
+		t.Fatal("the pull request was not fetched")
+	}
+	for _, auth := range auths {
+		if auth != "" {
+			t.Errorf("Authorization = %q sent to a host not named with --github-api-url", auth)
+		}
+	}
+}
+
+func TestGitHubToken(t *testing.T) {
+	t.Setenv("GITHUB_TOKEN", "secret")
+	tests := []struct {
+		api    string
+		apiURL string
+		want   string
+	}{
+		{"https://api.github.com", "", "secret"},
+		{"https://git.example.com/api/v3", "", ""},
+		{"https://git.example.com/api/v3", "https://git.example.com/api/v3/", "secret"},
+	}
+	for _, tt := range tests {
+		if got := githubToken(tt.api, tt.apiURL); got != tt.want {
+			t.Errorf("githubToken(%q, %q) = %q, want %q", tt.api, tt.apiURL, got, tt.want)
+		}
+	}
+}
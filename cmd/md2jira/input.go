@@ -0,0 +1,29 @@
+// Reading Markdown input, whatever encoding the editor saved it in
+
+package main
+
+import (
+	"io"
+	"os"
+
+	"github.com/astsu-dev/md2jira/converter"
+)
+
+// readSource reads a Markdown file, stripping its byte order mark and
+// transcoding UTF-16
+func readSource(path string) ([]byte, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	return converter.DecodeSource(data)
+}
+
+// readStdin reads Markdown from standard input as readSource does
+func readStdin() ([]byte, error) {
+	data, err := io.ReadAll(os.Stdin)
+	if err != nil {
+		return nil, err
+	}
+	return converter.DecodeSource(data)
+}
@@ -0,0 +1,129 @@
+// md2jira lint reports constructs that will not convert cleanly, without converting
+
+package main
+
+import (
+	"flag"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/astsu-dev/md2jira/converter"
+)
+
+// runLint runs the lint subcommand and returns the exit code: exitOK when every
+// input converts cleanly and exitWarnings when anything was reported
+func runLint(args []string) int {
+	fs := flag.NewFlagSet("lint", flag.ContinueOnError)
+	format := fs.String("format", "wiki", "Output format to lint for: wiki or adf")
+	checkLinks := fs.Bool("check-links", false, "Report links to missing local files")
+	checkRemote := fs.Bool("check-links-remote", false, "Also check absolute URLs with HEAD requests")
+	remote := addRemoteFlags(fs)
+	configFile := fs.String("config", "", "Configuration file")
+	spellDict := fs.String("spellcheck-dict", "", "Comma-separated hunspell .dic files to spellcheck against")
+	fs.Usage = func() {
+		fmt.Fprintf(os.Stderr, `Usage:
+  md2jira lint [options] input.md...
+  cat file.md | md2jira lint
+
+Reports every construct that will not convert cleanly as
+file:line:column: severity code: message, without producing output.
+Exits with 4 if anything was reported (see md2jira --help for exit codes).
+
+Options:
+  --format string
+                Output format to lint for: wiki (default) or adf
+  --check-links Also report links to missing local files
+  --check-links-remote
+                Also check absolute URLs with HEAD requests (implies --check-links)
+`+remoteUsage+`  --config string
+                Read defaults from this file instead of .md2jira.yaml/.md2jira.toml
+  --spellcheck-dict string
+                Also report words missing from the given comma-separated .dic files
+                (with the word forms of the .aff file next to each)
+`)
+	}
+	files, err := parseInterspersed(fs, args)
+	if err != nil {
+		if err == flag.ErrHelp {
+			return exitOK
+		}
+		return exitUsage
+	}
+	cfg, err := loadFlagConfig(fs, *configFile)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error reading config: %v\n", err)
+		return exitUsage
+	}
+
+	var transport http.RoundTripper
+	if *checkRemote {
+		if transport, err = remote.transport(); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			return exitUsage
+		}
+	}
+	opts := converter.Options{
+		WarnOnUnsupported: true,
+		CheckLinks:        *checkLinks || *checkRemote,
+		CheckRemoteLinks:  *checkRemote,
+		HTTPTransport:     transport,
+		LanguageMap:       cfg.languages,
+	}
+	if *spellDict != "" {
+		dict, err := converter.LoadDictionary(strings.Split(*spellDict, ",")...)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error loading dictionary: %v\n", err)
+			return exitIO
+		}
+		opts.SpellChecker = dict
+	}
+
+	if len(files) == 0 {
+		files = []string{"-"}
+	}
+
+	found := false
+	for _, file := range files {
+		var input []byte
+		name := file
+		opts.BaseDir = filepath.Dir(file)
+		if file == "-" {
+			name, opts.BaseDir = "<stdin>", "."
+			input, err = readStdin()
+		} else {
+			input, err = readSource(file)
+		}
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error reading %s: %v\n", name, err)
+			return exitIO
+		}
+
+		var result converter.Result
+		switch *format {
+		case "wiki":
+			result, err = converter.ConvertWithOptions(string(input), opts)
+		case "adf":
+			result, err = converter.ConvertToADFWithOptions(string(input), opts)
+		default:
+			fmt.Fprintf(os.Stderr, "Unknown format: %s\n", *format)
+			return exitUsage
+		}
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error converting %s: %v\n", name, err)
+			return exitConversion
+		}
+
+		for _, w := range result.Warnings {
+			fmt.Println(formatWarning(name, w))
+			found = true
+		}
+	}
+
+	if found {
+		return exitWarnings
+	}
+	return exitOK
+}
@@ -0,0 +1,905 @@
+// Command md2jira converts Markdown-formatted text into JIRA Text Formatting Notation
+package main
+
+import (
+	"bufio"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"maps"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"text/template"
+	"time"
+
+	"github.com/astsu-dev/md2jira/converter"
+	"github.com/astsu-dev/md2jira/jira"
+)
+
+// Exit codes
+const (
+	exitOK = 0
+	// exitUsage reports invalid flags, options or missing input
+	exitUsage = 1
+	// exitIO reports unreadable input, dictionaries or unwritable output
+	exitIO = 2
+	// exitConversion reports a document that could not be converted
+	exitConversion = 3
+	// exitWarnings reports a conversion that produced warnings (--fail-on-warning, lint)
+	exitWarnings = 4
+)
+
+// formatWarning formats a warning as "file:line:column: severity code: message"
+func formatWarning(name string, w converter.Warning) string {
+	if w.Line == 0 {
+		return fmt.Sprintf("%s: %s", name, w)
+	}
+	return fmt.Sprintf("%s:%s", name, w)
+}
+
+// jsonResult is the --json output document
+type jsonResult struct {
+	Output      string                 `json:"output"`
+	Warnings    []converter.Warning    `json:"warnings"`
+	Stats       converter.Stats        `json:"stats"`
+	ActionItems []converter.ActionItem `json:"action_items,omitempty"`
+	Metadata    map[string]any         `json:"metadata,omitempty"`
+	Profile     *converter.Profile     `json:"profile,omitempty"`
+	Attachments []converter.Attachment `json:"attachments,omitempty"`
+}
+
+// CLI entry point
+func main() {
+	if len(os.Args) > 1 {
+		switch os.Args[1] {
+		case "lint":
+			os.Exit(runLint(os.Args[2:]))
+		case "release":
+			os.Exit(runRelease(os.Args[2:]))
+		case "from-pr":
+			os.Exit(runFromPR(os.Args[2:]))
+		case "meeting":
+			os.Exit(runMeeting(os.Args[2:]))
+		case "template":
+			os.Exit(runTemplate(os.Args[2:]))
+		case "score":
+			os.Exit(runScore(os.Args[2:]))
+		case "capabilities":
+			os.Exit(runCapabilities(os.Args[2:]))
+		case "push":
+			os.Exit(runPush(os.Args[2:]))
+		case "sync":
+			os.Exit(runSync(os.Args[2:]))
+		case "update":
+			os.Exit(runUpdate(os.Args[2:]))
+		case "version-notes":
+			os.Exit(runVersionNotes(os.Args[2:]))
+		case "split":
+			os.Exit(runSplit(os.Args[2:]))
+		case "install-context-menu":
+			os.Exit(runInstallContextMenu(os.Args[2:]))
+		}
+	}
+
+	// Define flags
+	outputFile := flag.String("o", "", "Output file (default: stdout)")
+	clipboard := flag.Bool("clipboard", false, "Copy the output to the clipboard instead of printing it")
+	configFile := flag.String("config", "", "Configuration file (default: .md2jira.yaml or .md2jira.toml in . or ~)")
+	noConfig := flag.Bool("no-config", false, "Do not read a configuration file")
+	outDir := flag.String("out-dir", "", "Directory for converted files when converting several inputs")
+	watch := flag.Bool("watch", false, "Re-convert whenever an input file changes")
+	inPlace := flag.Bool("w", false, "Write each output next to its input (foo.md -> foo.jira)")
+	ext := flag.String("ext", "", "Extension of files written with -w or --out-dir (default: .jira, or .json for adf/--json)")
+	recursive := flag.Bool("r", false, "Convert the Markdown files under the input directories")
+	include := flag.String("include", "", "Comma-separated globs of files to convert with -r (default: *.md,*.markdown)")
+	exclude := flag.String("exclude", "", "Comma-separated globs of files and directories to skip with -r")
+	format := flag.String("format", "wiki", "Output format: wiki or adf")
+	eol := flag.String("eol", "lf", "Line endings of the output: lf or crlf")
+	verbose := flag.Bool("verbose", false, "Show conversion warnings")
+	failOnWarning := flag.Bool("fail-on-warning", false, "Exit with code 4 if any warnings were generated")
+	interactive := flag.Bool("interactive", false, "Ask how to render raw HTML and large tables, saving the answers next to the input")
+	jsonOutput := flag.Bool("json", false, "Emit output, warnings and stats as a JSON document")
+	profileBlocks := flag.Bool("profile-blocks", false, "Report the rendering time and output size of each block")
+	stats := flag.Bool("stats", false, "Report the converted constructs and the node kinds md2jira does not know")
+	thumbnail := flag.Bool("thumbnail", false, "Render images as thumbnails")
+	imageWidth := flag.Int("image-width", 0, "Render images with the given width in pixels")
+	attachTo := flag.String("attach-to", "", "Attach local images to this issue and reference them by name")
+	altTextCmd := flag.String("alt-text-cmd", "", "Command printing the alt text of an image given as its last argument")
+	checkLinks := flag.Bool("check-links", false, "Report links to missing local files")
+	checkRemote := flag.Bool("check-links-remote", false, "Also check absolute URLs with HEAD requests")
+	remote := addRemoteFlags(flag.CommandLine)
+	joinLines := flag.Bool("join-lines", false, "Join one-sentence-per-line paragraphs into single lines")
+	hardBreak := flag.String("hard-break", "backslash", "Write hard line breaks as backslash (\\\\) or blank (a blank line)")
+	softBreak := flag.String("soft-break", "preserve", "Keep the newlines inside paragraphs (preserve) or join the lines (join)")
+	mediaMacro := flag.String("media-macro", "", "Render media embeds with the given macro instead of links")
+	inlineFootnotes := flag.Bool("inline-footnotes", false, "Inline footnote content in parentheses at the reference")
+	inlineFootnoteMax := flag.Int("inline-footnote-max", 0, "Only inline footnotes up to this many characters (0 = no limit)")
+	escape := flag.String("escape", "minimal", "Escaping of JIRA markup characters: none, minimal or aggressive")
+	escapeStyle := flag.String("escape-style", "backslash", "How escaped characters are neutralized: backslash, zero-width or empty-group")
+	detectTraces := flag.Bool("detect-traces", false, "Render unfenced stack traces and compiler output as {noformat}")
+	collapseCode := flag.Int("collapse-code-over", 0, "Collapse code blocks longer than this many lines (0 = never)")
+	jqlBaseURL := flag.String("jql-base-url", "", "Render jql fences as issue navigator links on this JIRA site")
+	issueBaseURL := flag.String("issue-base-url", "", "Render bare issue keys (PROJ-123) as links to this JIRA site")
+	issueProjects := flag.String("issue-projects", "", "Comma-separated projects whose keys --issue-base-url links (default: all)")
+	stripTitle := flag.Bool("strip-title", false, "Remove the first H1 and report it as the title in --json metadata")
+	headingOffset := flag.Int("heading-offset", 0, "Shift headings down this many levels (1: H1 becomes h2.)")
+	toc := flag.Bool("toc", false, "Start the output with a {toc} macro unless the document has a [TOC] marker")
+	tocMin := flag.Int("toc-min-level", 0, "Lowest heading level listed by {toc} macros (0 = all)")
+	tocMax := flag.Int("toc-max-level", 0, "Highest heading level listed by {toc} macros (0 = all)")
+	roadmap := flag.Bool("roadmap", false, "Render task/start/end/owner tables as a {roadmap} macro")
+	roadmapTemplate := flag.String("roadmap-template", "", "Go template file rendering roadmap tables (implies --roadmap)")
+	mermaid := flag.String("mermaid", "code", "Render mermaid fences as code, macro or image")
+	mermaidMacro := flag.String("mermaid-macro", "", "Macro of the mermaid plugin (default mermaid)")
+	plantUML := flag.String("plantuml", "code", "Render plantuml fences as code, macro or image")
+	plantUMLMacro := flag.String("plantuml-macro", "", "Macro of the PlantUML plugin (default plantuml)")
+	diagramRenderer := flag.String("diagram-renderer", "kroki", "Draw image diagrams with kroki or mmdc")
+	krokiURL := flag.String("kroki-url", "", "Kroki server for image diagrams (default https://kroki.io)")
+	diagramDir := flag.String("diagram-dir", "", "Directory for images drawn with mmdc (default: the working directory)")
+	mathMacro := flag.String("math-macro", "", "Render $math$ with this macro (e.g. mathjax or latex) instead of as code")
+	dialect := flag.String("dialect", "server", "Jira deployment: server, datacenter or cloud")
+	compat := flag.String("compat", "latest", "Pin the rendering behavior of compatibility level N")
+	linkStyle := flag.String("link-style", "inline", "Link rendering: inline or endnotes")
+	enrichLinks := flag.Bool("enrich-links", false, "Give bare links to known systems a readable title")
+	linkState := flag.String("link-state", "", "Sync state file whose pushed documents links are rewritten to")
+	normalizePunct := flag.Bool("normalize-punctuation", false, "Normalize non-English spaces and punctuation next to emphasis and links")
+	emoticons := flag.Bool("emoticons", false, "Translate common emoji (✅, ⚠️, ❌, 👍) into JIRA emoticons")
+	symbols := flag.String("symbols", "emoticons", "Write checkboxes and emoji as emoticons, text labels or the original characters")
+	taskStyle := flag.String("task-style", "auto", "Write checkboxes as emoticons, text ([x]), unicode (☑) or none (default: as --symbols)")
+	safeMode := flag.Bool("safe-mode", false, "Neutralize script links, data URLs and injected macros in untrusted input")
+	sanitize := flag.Bool("sanitize-html", false, "Remove scripts, event handlers and tracking pixels from raw HTML")
+	provenance := flag.Bool("provenance", false, "Append a trailer with the md2jira version and source SHA-256")
+	preserveSpacers := flag.Bool("preserve-spacers", false, "Render empty spacer paragraphs as forced line breaks")
+	runbook := flag.Bool("runbook", false, "Render a procedure as a runbook with numbered steps and phases")
+	timeline := flag.Bool("timeline", false, "Render \"12:03 UTC — event\" lists as time | event tables")
+	meetingNotes := flag.Bool("meeting-notes", false, "Render \"Action items\" lists as checkboxes with mentions and due dates")
+	mentions := flag.String("mentions", "", "YAML or TOML file mapping @handles to JIRA users; converts every @mention")
+	timelineTZ := flag.String("timeline-tz", "", "Convert timeline times into this zone (e.g. UTC or Europe/Berlin)")
+	spellDict := flag.String("spellcheck-dict", "", "Comma-separated hunspell .dic files to spellcheck against")
+	version := flag.Bool("version", false, "Show version information")
+	help := flag.Bool("help", false, "Show help")
+	flag.BoolVar(help, "h", false, "Show help")
+
+	flag.Usage = func() {
+		fmt.Fprintf(os.Stderr, `md2jira - Markdown to JIRA Markup Converter
+
+Usage:
+  md2jira [options] [input.md]
+  md2jira [options] --out-dir dir input.md...
+  md2jira [options] -r --out-dir dir docs...
+  cat file.md | md2jira
+  md2jira lint [options] input.md...
+  md2jira score [options] corpus/...
+  md2jira capabilities --target dc|cloud|jsm [options] input.md...
+  md2jira release --version 1.4.0 --project PROJ [options]
+  md2jira version-notes --version 1.4.0 --project PROJ [options] [CHANGELOG.md]
+  md2jira from-pr [options] https://github.com/org/repo/pull/123
+  md2jira meeting [options] notes.md
+  md2jira push [options] doc.md
+  md2jira split [options] bundle.md
+  md2jira sync --config sync.yaml [options]
+  md2jira update --issue PROJ-123 [options] file.md
+  md2jira template list|new|render [options] [name]
+  md2jira install-context-menu [--uninstall] [options]
+
+Options:
+  -o string     Output file (default: stdout)
+  --clipboard   Copy the output of a single input to the clipboard instead
+                of printing it (pbcopy, clip, or wl-copy/xclip/xsel)
+  --config string
+                Read defaults from this file instead of .md2jira.yaml/.md2jira.toml
+                in the working or home directory
+  --no-config   Do not read a configuration file, e.g. when converting files
+                of unknown origin
+  --out-dir string
+                Write one .jira file per input into this directory
+  --watch       Re-convert whenever an input file changes (stop with Ctrl-C)
+  -w            Write each output next to its input (foo.md -> foo.jira)
+  --ext string  Extension of files written with -w or --out-dir
+                (default: .jira, or .json for --format adf and --json)
+  -r            Convert every .md/.markdown file under the input directories,
+                mirroring the directory structure in --out-dir
+  --include string
+                Comma-separated globs of files to convert with -r
+  --exclude string
+                Comma-separated globs of files and directories to skip with -r
+  --format string
+                Output format: wiki (JIRA markup) or adf (Jira Cloud JSON)
+  --eol string  Line endings of the output written to files and stdout: lf or
+                crlf (default: lf). Input line endings are always normalized
+  --verbose     Show conversion warnings
+  --fail-on-warning
+                Exit with code 4 if the conversion produced any warnings
+  --interactive Ask on stderr how to render each raw HTML block and large table,
+                reading answers from stdin and saving them to input.directives.yaml,
+                which later runs apply without asking
+  --json        Emit {"output", "warnings", "stats"} as JSON instead of plain output
+  --profile-blocks
+                Report the slowest top-level blocks, with their output size, and
+                the time spent in each node kind (in "profile" with --json)
+  --stats       Report the counts of converted constructs and warnings, and the
+                node kinds rendered only through their children, e.g. of
+                goldmark extensions (in "stats" with --json)
+  --thumbnail   Render images as thumbnails
+  --image-width int
+                Render images with the given width in pixels
+  --attach-to string
+                Upload local images as attachments of this issue (e.g. PROJ-123)
+                and render them as !name.png!; JIRA_URL, JIRA_USER and JIRA_TOKEN
+                configure the connection
+  --alt-text-cmd string
+                Generate the alt text of images that have none with this command,
+                such as an OCR or captioning script; it gets the image URL or path
+                as its last argument and prints the text; only taken from the
+                command line
+  --check-links Report links to missing local files as warnings
+  --check-links-remote
+                Also check absolute URLs with HEAD requests (implies --check-links)
+`+remoteUsage+`  --join-lines  Join one-sentence-per-line paragraphs into single lines
+  --hard-break string
+                Write hard line breaks (a trailing backslash or two spaces) as
+                backslash, a \\ forced break, or blank, a blank line; list
+                items keep \\ (default: backslash)
+  --soft-break string
+                Keep the newlines inside paragraphs (preserve) or join the lines
+                with spaces (join), for renderers that break at every newline
+                (default: preserve)
+  --media-macro string
+                Render <video>/<audio>/<iframe> with the given macro (e.g. widget)
+  --inline-footnotes
+                Inline footnote content in parentheses at the reference site
+  --inline-footnote-max int
+                Only inline footnotes up to this many characters (0 = no limit)
+  --escape string
+                Escaping of JIRA markup characters: none, minimal (default) or aggressive
+  --escape-style string
+                Neutralize escaped text effect markers and ?? with a backslash
+                (default), an invisible zero-width space (zero-width) or an
+                empty {} group (empty-group); [, {, | and ! always take a backslash
+  --detect-traces
+                Render stack traces and compiler output pasted as plain
+                paragraphs as {noformat} blocks
+  --collapse-code-over int
+                Collapse code blocks longer than this many lines (0 = never)
+  --jql-base-url string
+                Render jql fences as links to the issue navigator of this site
+                (e.g. https://example.atlassian.net) instead of {jql} macros
+  --issue-base-url string
+                Render bare issue keys such as PROJ-123 as
+                [PROJ-123|<url>/browse/PROJ-123] links to this site
+  --issue-projects string
+                Comma-separated project keys whose issue keys --issue-base-url
+                links, so terms such as UTF-8 are not (default: all)
+  --strip-title Remove the first H1, which pasting into an issue or page
+                with a title of its own would repeat; with --json, its text
+                is the title field of the metadata
+  --heading-offset int
+                Shift headings down this many levels, e.g. 1 when the H1 is the
+                title of an issue that has a summary already (# Title -> h2.)
+  --toc         Start the output with a {toc} macro, unless the document has
+                a [TOC], [[toc]] or <!-- toc --> marker, which always becomes one
+  --toc-min-level int
+                Only list headings of this level and below in {toc} macros
+  --toc-max-level int
+                Only list headings of this level and above in {toc} macros
+  --roadmap     Render tables with task, start, end and owner columns as a
+                {roadmap} macro
+  --roadmap-template string
+                Render roadmap tables with this Go template file instead, for the
+                roadmap macro of your site (implies --roadmap)
+  --mermaid string
+                Render mermaid fences as code (default), macro ({mermaid}, for
+                sites with the plugin) or image
+  --mermaid-macro string
+                Macro of the mermaid plugin (default: mermaid)
+  --plantuml string
+                Render plantuml fences as code (default), macro ({plantuml}, for
+                sites with the plugin) or image
+  --plantuml-macro string
+                Macro of the PlantUML plugin (default: plantuml)
+  --diagram-renderer string
+                Draw image diagrams as kroki links (default) or as PNG files
+                with the mermaid CLI (mmdc), to attach to the issue
+  --kroki-url string
+                Kroki server for image diagrams (default: https://kroki.io)
+  --diagram-dir string
+                Directory for images drawn with mmdc (default: the working directory)
+  --math-macro string
+                Render $inline$ and $$display$$ math with this macro (e.g.
+                mathjax or latex, for sites with a math plugin) instead of as
+                monospace and {noformat}
+  --dialect string
+                Jira deployment the markup targets: server (default), datacenter
+                or cloud; adjusts code languages and checkboxes
+  --compat string
+                Render as compatibility level N, so output does not change when
+                md2jira is upgraded (default: latest)
+  --link-style string
+                Link rendering: inline (default) or endnotes (numbered references
+                with a trailing Links section)
+  --enrich-links
+                Title bare GitHub, GitLab, Confluence, Google Docs and JIRA links
+  --link-state string
+                Rewrite links to documents pushed by md2jira sync, such as
+                [design](design.md#api), to their issues, using the sync state
+                file (e.g. sync.state.json)
+  --normalize-punctuation
+                Normalize no-break and ideographic spaces and full-width
+                punctuation next to emphasis and links, and use {*}bold{*} where
+                emphasis touches guillemets, CJK text or letters
+  --emoticons   Translate common emoji into JIRA emoticons (✅ -> (/), ⚠️ -> (!),
+                ❌ -> (x), 👍 -> (y), ...); other emoji are kept
+  --symbols string
+                Write checkboxes and emoji as emoticons (default), text for
+                instances without graphical emoticons ([done], [blocked], ->), or
+                original to keep emoji as written and draw checkboxes as ☑ and ☐
+  --task-style string
+                Write checkboxes as emoticons ((/) and ( )), text ([x] and
+                [ ]), unicode (☑ and ☐) or none, whatever --symbols is
+                (default: auto, as --symbols)
+  --sanitize-html
+                Remove scripts, event handlers, script URLs and tracking pixels from raw HTML
+  --safe-mode   Convert untrusted input: drop javascript:, vbscript: and data: links
+                and images, always sanitize and escape, and break up content that
+                would close {code} and {noformat} blocks (implies --sanitize-html)
+  --provenance  Append a trailer with the md2jira version and source SHA-256
+  --preserve-spacers
+                Render &nbsp;-only and <p><br></p> spacers as forced line breaks
+  --runbook     Render a procedure as a runbook: ordered list items become
+                steps numbered across sections with ( ) checkpoints, and each
+                ## section becomes an {expand} phase
+  --meeting-notes
+                Render "Action items" lists as checkbox lines, with configured
+                @mentions and "by Friday" due dates converted
+  --mentions file
+                Convert every @mention into a JIRA mention ([~user]) with the
+                YAML or TOML mapping of @handles to user names or accountid:...
+                in file; unmapped handles are reported
+  --timeline    Render "- 12:03 UTC — detected" lists as Time | Event tables
+  --timeline-tz string
+                Convert timeline times into this zone (e.g. UTC or Europe/Berlin;
+                implies --timeline)
+  --spellcheck-dict string
+                Report words missing from the given comma-separated .dic files
+                (with the word forms of the .aff file next to each)
+  --version     Show version information
+  -h, --help    Show this help
+
+Examples:
+  md2jira input.md                  Convert file to stdout
+  md2jira input.md -o output.txt    Convert file to output file
+  cat README.md | md2jira           Convert from stdin
+  md2jira --verbose input.md        Convert with warnings
+  md2jira --format adf input.md     Convert to ADF JSON
+  md2jira --out-dir build docs/*.md Convert many files into build/
+  md2jira -r docs --out-dir build  Convert a directory tree into build/
+  md2jira -w docs/*.md              Write docs/foo.jira next to each docs/foo.md
+  md2jira lint input.md             Report constructs that won't convert cleanly
+  md2jira score corpus/             Compare conversions with expected outputs
+
+Exit codes:
+  0  Success
+  1  Invalid flags or options, or no input
+  2  I/O error reading input or dictionaries, or writing output
+  3  Conversion failure
+  4  Warnings were generated (--fail-on-warning and lint), or the score
+     is below --fail-under
+
+`)
+	}
+
+	// Invalid flags exit with exitUsage rather than the flag package's 2
+	flag.CommandLine.Init(os.Args[0], flag.ContinueOnError)
+	args, err := parseInterspersed(flag.CommandLine, os.Args[1:])
+	if err != nil {
+		os.Exit(exitUsage)
+	}
+
+	// Defaults from the configuration file; flags on the command line win
+	cfg := &config{}
+	if *noConfig && *configFile != "" {
+		fmt.Fprintln(os.Stderr, "Error: --config cannot be combined with --no-config")
+		os.Exit(exitUsage)
+	}
+	if !*noConfig {
+		if cfg, err = loadFlagConfig(flag.CommandLine, *configFile); err != nil {
+			fmt.Fprintf(os.Stderr, "Error reading config: %v\n", err)
+			os.Exit(exitUsage)
+		}
+	}
+
+	if *version {
+		fmt.Printf("md2jira version %s\n", converter.Version)
+		os.Exit(exitOK)
+	}
+
+	if *help {
+		flag.Usage()
+		os.Exit(exitOK)
+	}
+
+	var transport http.RoundTripper
+	if *checkRemote {
+		if transport, err = remote.transport(); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(exitUsage)
+		}
+	}
+
+	// Conversion options
+	opts := converter.Options{
+		WarnOnUnsupported:    *verbose || *jsonOutput || *failOnWarning,
+		Verbose:              *verbose,
+		ImageThumbnail:       *thumbnail,
+		ImageWidth:           *imageWidth,
+		CheckLinks:           *checkLinks || *checkRemote,
+		CheckRemoteLinks:     *checkRemote,
+		HTTPTransport:        transport,
+		AttachLocalImages:    *attachTo != "",
+		JoinSentenceLines:    *joinLines,
+		MediaMacro:           *mediaMacro,
+		InlineFootnotes:      *inlineFootnotes,
+		InlineFootnoteMaxLen: *inlineFootnoteMax,
+		EnrichLinks:          *enrichLinks,
+		Emoticons:            *emoticons,
+		NormalizePunctuation: *normalizePunct,
+		SafeMode:             *safeMode,
+		ProfileBlocks:        *profileBlocks,
+		PreserveSpacers:      *preserveSpacers,
+		DetectTraces:         *detectTraces,
+		CollapseCodeOver:     *collapseCode,
+		JQLBaseURL:           *jqlBaseURL,
+		IssueBaseURL:         *issueBaseURL,
+		IssueProjects:        splitList(*issueProjects),
+		StripTitle:           *stripTitle,
+		HeadingOffset:        *headingOffset,
+		TOC:                  *toc,
+		TOCMinLevel:          *tocMin,
+		TOCMaxLevel:          *tocMax,
+		Runbook:              *runbook,
+		TimelineTables:       *timeline || *timelineTZ != "",
+		MeetingNotes:         *meetingNotes,
+		Mentions:             cfg.mentions,
+		ProvenanceTrailer:    *provenance,
+		LanguageMap:          cfg.languages,
+		Header:               cfg.header,
+		Footer:               cfg.footer,
+	}
+	if *headingOffset < 0 || *headingOffset > 5 {
+		fmt.Fprintln(os.Stderr, "Error: --heading-offset must be from 0 to 5")
+		os.Exit(exitUsage)
+	}
+	if *tocMin < 0 || *tocMin > 6 || *tocMax < 0 || *tocMax > 6 {
+		fmt.Fprintln(os.Stderr, "Error: --toc-min-level and --toc-max-level must be heading levels from 1 to 6")
+		os.Exit(exitUsage)
+	}
+	opts.EscapeMode, err = converter.ParseEscapeMode(*escape)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(exitUsage)
+	}
+	if opts.EscapeStyle, err = converter.ParseEscapeStyle(*escapeStyle); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(exitUsage)
+	}
+
+	if *linkState != "" {
+		if opts.DocumentLinks, err = loadDocumentLinks(*linkState); err != nil {
+			fmt.Fprintf(os.Stderr, "Error reading link state: %v\n", err)
+			os.Exit(exitIO)
+		}
+	}
+
+	if *mentions != "" {
+		mapping, err := loadMentions(*mentions)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error reading mentions: %v\n", err)
+			os.Exit(exitIO)
+		}
+		// The file wins over the mentions of the configuration file
+		opts.Mentions = make(map[string]string, len(cfg.mentions)+len(mapping))
+		maps.Copy(opts.Mentions, cfg.mentions)
+		maps.Copy(opts.Mentions, mapping)
+		opts.ConvertMentions = true
+	}
+
+	if opts.Symbols, err = converter.ParseSymbolSet(*symbols); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(exitUsage)
+	}
+	if opts.TaskStyle, err = converter.ParseTaskStyle(*taskStyle); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(exitUsage)
+	}
+	if opts.HardBreak, err = converter.ParseHardBreak(*hardBreak); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(exitUsage)
+	}
+	if opts.SoftBreak, err = converter.ParseSoftBreak(*softBreak); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(exitUsage)
+	}
+
+	opts.LinkStyle, err = converter.ParseLinkStyle(*linkStyle)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(exitUsage)
+	}
+
+	opts.Dialect, err = converter.ParseDialect(*dialect)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(exitUsage)
+	}
+
+	opts.Mermaid, err = converter.ParseDiagramMode(*mermaid)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(exitUsage)
+	}
+	opts.MermaidMacro = *mermaidMacro
+	opts.PlantUML, err = converter.ParseDiagramMode(*plantUML)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(exitUsage)
+	}
+	opts.PlantUMLMacro = *plantUMLMacro
+	opts.MathMacro = *mathMacro
+	switch *diagramRenderer {
+	case "kroki":
+		opts.DiagramRenderer = converter.KrokiRenderer{BaseURL: *krokiURL}
+	case "mmdc":
+		opts.DiagramRenderer = converter.MermaidCLIRenderer{Dir: *diagramDir}
+	default:
+		fmt.Fprintf(os.Stderr, "Error: unknown diagram renderer %q (want kroki or mmdc)\n", *diagramRenderer)
+		os.Exit(exitUsage)
+	}
+
+	opts.CompatLevel, err = converter.ParseCompatLevel(*compat)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(exitUsage)
+	}
+
+	if *timelineTZ != "" {
+		opts.TimelineZone, err = time.LoadLocation(*timelineTZ)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: unknown timeline zone %q\n", *timelineTZ)
+			os.Exit(exitUsage)
+		}
+	}
+
+	if command := strings.Fields(*altTextCmd); len(command) > 0 {
+		opts.AltText = converter.CommandAltText{Command: command[0], Args: command[1:]}
+	}
+
+	if *sanitize {
+		opts.HTMLSanitizer = converter.NewBasicSanitizer()
+	}
+
+	if *roadmapTemplate != "" {
+		opts.RoadmapTemplate, err = template.ParseFiles(*roadmapTemplate)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error reading roadmap template: %v\n", err)
+			os.Exit(exitUsage)
+		}
+	} else if *roadmap {
+		opts.RoadmapTemplate = converter.DefaultRoadmapTemplate
+	}
+
+	if *spellDict != "" {
+		dict, err := converter.LoadDictionary(strings.Split(*spellDict, ",")...)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error loading dictionary: %v\n", err)
+			os.Exit(exitIO)
+		}
+		opts.SpellChecker = dict
+	}
+
+	if *format != "wiki" && *format != "adf" {
+		fmt.Fprintf(os.Stderr, "Unknown format: %s\n", *format)
+		os.Exit(exitUsage)
+	}
+	if *eol != "lf" && *eol != "crlf" {
+		fmt.Fprintf(os.Stderr, "Unknown line ending: %s (want lf or crlf)\n", *eol)
+		os.Exit(exitUsage)
+	}
+	conv := conversion{
+		format:        *format,
+		crlf:          *eol == "crlf",
+		json:          *jsonOutput,
+		showWarnings:  *verbose || *failOnWarning || opts.CheckLinks || opts.SpellChecker != nil || opts.AttachLocalImages || opts.ConvertMentions,
+		failOnWarning: *failOnWarning,
+		ext:           *ext,
+		interactive:   *interactive,
+		stats:         *stats,
+		clipboard:     *clipboard,
+	}
+	if conv.interactive {
+		conv.answers = bufio.NewReader(os.Stdin)
+	}
+	if *attachTo != "" {
+		if *format != "wiki" {
+			fmt.Fprintln(os.Stderr, "Error: --attach-to requires --format wiki")
+			os.Exit(exitUsage)
+		}
+		if *recursive || *inPlace || *outDir != "" || len(args) > 1 {
+			fmt.Fprintln(os.Stderr, "Error: --attach-to converts a single input")
+			os.Exit(exitUsage)
+		}
+		if conv.client, err = remote.jiraClient(); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(exitUsage)
+		}
+		conv.attachTo = *attachTo
+	}
+	if conv.ext != "" && !strings.HasPrefix(conv.ext, ".") {
+		conv.ext = "." + conv.ext
+	}
+	if *clipboard && (*recursive || *inPlace || *watch || *outDir != "" || *outputFile != "" || len(args) > 1) {
+		fmt.Fprintln(os.Stderr, "Error: --clipboard copies the output of a single input and cannot be combined with -o, -w, -r, --out-dir or --watch")
+		os.Exit(exitUsage)
+	}
+	if *inPlace && (*outDir != "" || *outputFile != "") {
+		fmt.Fprintln(os.Stderr, "Error: -w cannot be combined with -o or --out-dir")
+		os.Exit(exitUsage)
+	}
+
+	// run converts the inputs once and returns the exit code
+	var run func() int
+	var watched []string
+	if *recursive {
+		if (*outDir == "" && !*inPlace) || len(args) == 0 {
+			fmt.Fprintln(os.Stderr, "Error: -r requires input directories and --out-dir or -w")
+			os.Exit(exitUsage)
+		}
+		inputs, err := walkInputs(args, splitList(*include), splitList(*exclude), conv)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error reading directory: %v\n", err)
+			os.Exit(exitIO)
+		}
+		if *inPlace {
+			inputs = siblingInputs(inputs, conv)
+		}
+		opts.WarnOnUnsupported = true
+		run = func() int { return runBatch(inputs, *outDir, opts, conv) }
+		for _, in := range inputs {
+			watched = append(watched, in.path)
+		}
+	} else {
+		files, err := expandInputs(args)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(exitUsage)
+		}
+		watched = files
+		switch {
+		case *inPlace && len(files) > 0:
+			opts.WarnOnUnsupported = true
+			run = func() int { return runBatch(siblingInputs(flatInputs(files, conv), conv), "", opts, conv) }
+		case len(files) > 1 || *outDir != "":
+			if *outputFile != "" {
+				fmt.Fprintln(os.Stderr, "Error: -o cannot be used with several inputs; use --out-dir")
+				os.Exit(exitUsage)
+			}
+			if *outDir == "" {
+				fmt.Fprintln(os.Stderr, "Error: converting several inputs requires --out-dir")
+				os.Exit(exitUsage)
+			}
+			// The per-file summary counts warnings even when they are not shown
+			opts.WarnOnUnsupported = true
+			run = func() int { return runBatch(flatInputs(files, conv), *outDir, opts, conv) }
+		case len(files) == 1:
+			run = func() int {
+				input, err := readSource(files[0])
+				if err != nil {
+					fmt.Fprintf(os.Stderr, "Error reading file: %v\n", err)
+					return exitIO
+				}
+				opts.BaseDir = filepath.Dir(files[0])
+				opts.SourcePath = files[0]
+				opts.Directives, err = conv.directives(files[0], input, opts)
+				if err != nil {
+					fmt.Fprintf(os.Stderr, "Error reading directives: %v\n", err)
+					return exitIO
+				}
+				return convertOne(files[0], input, *outputFile, opts, conv)
+			}
+		default:
+			// Check if stdin has data
+			stat, _ := os.Stdin.Stat()
+			if *interactive {
+				fmt.Fprintln(os.Stderr, "Error: --interactive requires input files, as answers are read from stdin")
+				os.Exit(exitUsage)
+			}
+			if (stat.Mode()&os.ModeCharDevice) != 0 || *watch || *inPlace {
+				// No input provided
+				flag.Usage()
+				os.Exit(exitUsage)
+			}
+			// Read from stdin
+			input, err := readStdin()
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "Error reading stdin: %v\n", err)
+				os.Exit(exitIO)
+			}
+			opts.BaseDir = "."
+			run = func() int { return convertOne("<stdin>", input, *outputFile, opts, conv) }
+		}
+	}
+
+	if *watch {
+		os.Exit(runWatch(watched, run))
+	}
+	os.Exit(run())
+}
+
+// convertOne converts a single input, writing it to outputFile, stdout or
+// the clipboard, and returns the exit code
+func convertOne(inputName string, input []byte, outputFile string, opts converter.Options, conv conversion) int {
+	output, result, err := conv.run(input, opts)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error converting: %v\n", err)
+		return exitConversion
+	}
+	if conv.showWarnings && !conv.json {
+		for _, w := range result.Warnings {
+			fmt.Fprintln(os.Stderr, formatWarning(inputName, w))
+		}
+	}
+	if !conv.json {
+		printProfile(os.Stderr, inputName, result.Profile)
+		if conv.stats {
+			printStats(os.Stderr, inputName, result.Stats)
+		}
+	}
+	if conv.attachTo != "" {
+		if err := uploadAttachments(conv.client, conv.attachTo, result.Attachments); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			return exitIO
+		}
+	}
+
+	// Write output
+	if conv.clipboard {
+		if err := copyToClipboard(output); err != nil {
+			fmt.Fprintf(os.Stderr, "Error copying to the clipboard: %v\n", err)
+			return exitIO
+		}
+		fmt.Fprintf(os.Stderr, "Copied %s to the clipboard\n", inputName)
+	} else if outputFile != "" {
+		err = os.WriteFile(outputFile, []byte(conv.lineEndings(output)), 0644)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error writing output file: %v\n", err)
+			return exitIO
+		}
+	} else {
+		fmt.Print(conv.lineEndings(output + "\n"))
+	}
+
+	if conv.failOnWarning && len(result.Warnings) > 0 {
+		return exitWarnings
+	}
+	return exitOK
+}
+
+// conversion holds the output settings shared by single and batch conversion
+type conversion struct {
+	// format is "wiki" or "adf"
+	format string
+	// json wraps the output, warnings and stats in a JSON document
+	json bool
+	// showWarnings prints warnings to stderr
+	showWarnings bool
+	// stats prints the conversion statistics to stderr
+	stats bool
+	// clipboard copies the output of a single input to the clipboard
+	clipboard bool
+	// failOnWarning exits with exitWarnings when warnings were generated
+	failOnWarning bool
+	// ext overrides the extension of files written by batch conversion
+	ext string
+	// crlf ends the lines of written output in \r\n
+	crlf bool
+	// interactive asks how to render lossy constructs, reading answers from answers
+	interactive bool
+	answers     *bufio.Reader
+	// attachTo is the issue the local images of a single input are attached
+	// to with client
+	attachTo string
+	client   *jira.Client
+}
+
+// run converts input and encodes the output
+func (c conversion) run(input []byte, opts converter.Options) (string, converter.Result, error) {
+	var result converter.Result
+	var err error
+	if c.format == "adf" {
+		result, err = converter.ConvertToADFWithOptions(string(input), opts)
+	} else {
+		result, err = converter.ConvertWithOptions(string(input), opts)
+	}
+	if err != nil {
+		return "", result, err
+	}
+	if !c.json {
+		return result.Output, result, nil
+	}
+
+	// Warnings are reported in the document rather than on stderr
+	doc := jsonResult{Output: result.Output, Warnings: result.Warnings, Stats: result.Stats, ActionItems: result.ActionItems, Metadata: result.Metadata, Profile: result.Profile, Attachments: result.Attachments}
+	if doc.Warnings == nil {
+		doc.Warnings = []converter.Warning{}
+	}
+	var data strings.Builder
+	enc := json.NewEncoder(&data)
+	enc.SetEscapeHTML(false)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(doc); err != nil {
+		return "", result, err
+	}
+	return strings.TrimSuffix(data.String(), "\n"), result, nil
+}
+
+// lineEndings converts the line endings of output written to files and
+// stdout with --eol crlf
+func (c conversion) lineEndings(output string) string {
+	if !c.crlf {
+		return output
+	}
+	return strings.ReplaceAll(output, "\n", "\r\n")
+}
+
+// extension returns the file extension for converted output
+func (c conversion) extension() string {
+	if c.ext != "" {
+		return c.ext
+	}
+	if c.json || c.format == "adf" {
+		return ".json"
+	}
+	return ".jira"
+}
+
+// parseInterspersed parses flags that may appear before or after the
+// positional arguments (md2jira input.md -o out.txt), returning the positionals.
+// Arguments after "--" are always positional.
+func parseInterspersed(fs *flag.FlagSet, args []string) ([]string, error) {
+	var positional []string
+	for {
+		if err := fs.Parse(args); err != nil {
+			return nil, err
+		}
+		rest := fs.Args()
+		if len(rest) == 0 {
+			return positional, nil
+		}
+		if len(args) > len(rest) && args[len(args)-len(rest)-1] == "--" {
+			return append(positional, rest...), nil
+		}
+		positional = append(positional, rest[0])
+		args = rest[1:]
+	}
+}
+
+// expandInputs expands glob patterns in the input arguments, for shells
+// (and quoted arguments) that leave them unexpanded
+func expandInputs(args []string) ([]string, error) {
+	var files []string
+	for _, arg := range args {
+		if !strings.ContainsAny(arg, "*?[") {
+			files = append(files, arg)
+			continue
+		}
+		matches, err := filepath.Glob(arg)
+		if err != nil {
+			return nil, fmt.Errorf("invalid pattern %q: %v", arg, err)
+		}
+		if len(matches) == 0 {
+			return nil, fmt.Errorf("no files match %q", arg)
+		}
+		files = append(files, matches...)
+	}
+	return files, nil
+}
@@ -0,0 +1,20 @@
+package main
+
+import "testing"
+
+func TestLineEndings(t *testing.T) {
+	tests := []struct {
+		crlf bool
+		in   string
+		want string
+	}{
+		{false, "h1. T\n\ntext\n", "h1. T\n\ntext\n"},
+		{true, "h1. T\n\ntext\n", "h1. T\r\n\r\ntext\r\n"},
+		{true, "", ""},
+	}
+	for _, tt := range tests {
+		if got := (conversion{crlf: tt.crlf}).lineEndings(tt.in); got != tt.want {
+			t.Errorf("lineEndings(%q) with crlf %v = %q, want %q", tt.in, tt.crlf, got, tt.want)
+		}
+	}
+}
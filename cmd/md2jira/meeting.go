@@ -0,0 +1,164 @@
+// md2jira meeting posts meeting notes and creates tasks for their action items
+
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/astsu-dev/md2jira/converter"
+	"github.com/astsu-dev/md2jira/jira"
+)
+
+// runMeeting runs the meeting subcommand and returns the exit code
+func runMeeting(args []string) int {
+	fs := flag.NewFlagSet("meeting", flag.ContinueOnError)
+	issue := fs.String("issue", "", "Add the notes to this issue as a comment")
+	project := fs.String("create-tasks", "", "Create a task in this project for each open action item")
+	issueType := fs.String("issue-type", "Task", "Issue type of created tasks")
+	linkType := fs.String("link-type", "Relates", "Link type between created tasks and --issue")
+	date := fs.String("meeting-date", "", "Date relative due dates are resolved against, as YYYY-MM-DD (default: today)")
+	configFile := fs.String("config", "", "Configuration file")
+	remote := addRemoteFlags(fs)
+	dryRun := fs.Bool("dry-run", false, "Print the notes and the tasks without contacting JIRA")
+	fs.Usage = func() {
+		fmt.Fprintf(os.Stderr, `Usage:
+  md2jira meeting [options] notes.md
+
+Converts meeting notes, rendering the lists of "Action items" sections as
+checkbox lines: @handles listed under mentions in the configuration file
+become JIRA mentions and "by Friday" becomes a due date. The notes are added
+to --issue as a comment, and --create-tasks creates a task for each open
+action item, assigned to its owner and linked to --issue. Without either the
+notes are printed. JIRA_URL, JIRA_USER and JIRA_TOKEN configure the connection.
+
+Options:
+  --issue string
+                Add the notes to this issue as a comment
+  --create-tasks string
+                Create a task in this project for each open action item
+  --issue-type string
+                Issue type of created tasks (default: Task)
+  --link-type string
+                Link type between created tasks and --issue (default: Relates)
+  --meeting-date string
+                Resolve due dates against this YYYY-MM-DD date (default: today)
+`+remoteUsage+`  --config string
+                Read defaults and mentions from this file instead of
+                .md2jira.yaml/.md2jira.toml
+  --dry-run     Print the notes and the tasks without contacting JIRA
+`)
+	}
+	files, err := parseInterspersed(fs, args)
+	if err != nil {
+		if err == flag.ErrHelp {
+			return exitOK
+		}
+		return exitUsage
+	}
+	if len(files) != 1 {
+		fs.Usage()
+		return exitUsage
+	}
+	cfg, err := loadFlagConfig(fs, *configFile)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error reading config: %v\n", err)
+		return exitUsage
+	}
+	opts := converter.Options{
+		WarnOnUnsupported: true,
+		BaseDir:           filepath.Dir(files[0]),
+		LanguageMap:       cfg.languages,
+		MeetingNotes:      true,
+		Mentions:          cfg.mentions,
+		Header:            cfg.header,
+		Footer:            cfg.footer,
+		SourcePath:        files[0],
+	}
+	if *date != "" {
+		if opts.MeetingDate, err = time.Parse("2006-01-02", *date); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: --meeting-date %q is not a YYYY-MM-DD date\n", *date)
+			return exitUsage
+		}
+	}
+
+	source, err := readSource(files[0])
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error reading input: %v\n", err)
+		return exitIO
+	}
+	result, err := converter.ConvertWithOptions(string(source), opts)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error converting: %v\n", err)
+		return exitConversion
+	}
+	for _, w := range result.Warnings {
+		fmt.Fprintln(os.Stderr, formatWarning(files[0], w))
+	}
+
+	if *dryRun || (*issue == "" && *project == "") {
+		fmt.Println(result.Output)
+		if *project != "" {
+			for _, item := range result.ActionItems {
+				if !item.Done {
+					fmt.Fprintf(os.Stderr, "Would create %s in %s: %s\n", *issueType, *project, item.Text)
+				}
+			}
+		}
+		return exitOK
+	}
+
+	client, err := remote.jiraClient()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		return exitUsage
+	}
+	if *issue != "" {
+		if err := client.AddComment(*issue, result.Output); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			return exitIO
+		}
+		fmt.Fprintf(os.Stderr, "Posted %s to %s\n", files[0], *issue)
+	}
+	if *project != "" {
+		if err := createActionTasks(client, result.ActionItems, *project, *issueType, *issue, *linkType); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			return exitIO
+		}
+	}
+	return exitOK
+}
+
+// createActionTasks creates a task for each open action item, linked to issue if set
+func createActionTasks(client *jira.Client, items []converter.ActionItem, project, issueType, issue, linkType string) error {
+	for _, item := range items {
+		if item.Done {
+			continue
+		}
+		fields := map[string]interface{}{
+			"project":   map[string]string{"key": project},
+			"issuetype": map[string]string{"name": issueType},
+			"summary":   item.Text,
+		}
+		if item.Assignee != "" {
+			fields["assignee"] = jira.UserField(item.Assignee)
+		}
+		if item.Due != "" {
+			fields["duedate"] = item.Due
+		}
+		key, err := client.CreateIssueFields(fields)
+		if err != nil {
+			return err
+		}
+		fmt.Fprintf(os.Stderr, "Created %s: %s\n", key, item.Text)
+		if issue != "" {
+			if err := client.LinkIssues(linkType, issue, key); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,54 @@
+// Block profiling report printed with --profile-blocks
+
+package main
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"text/tabwriter"
+	"time"
+
+	"github.com/astsu-dev/md2jira/converter"
+)
+
+// profileTop is the number of slowest blocks and node kinds reported
+const profileTop = 10
+
+// printProfile reports the slowest top-level blocks and node kinds of a conversion
+func printProfile(w io.Writer, name string, p *converter.Profile) {
+	if p == nil {
+		return
+	}
+	var render time.Duration
+	for _, b := range p.Blocks {
+		render += b.Duration
+	}
+	fmt.Fprintf(w, "%s: parsed in %s, rendered %d top-level blocks in %s\n",
+		name, p.Parse.Round(time.Microsecond), len(p.Blocks), render.Round(time.Microsecond))
+
+	blocks := append([]converter.BlockProfile(nil), p.Blocks...)
+	sort.SliceStable(blocks, func(i, j int) bool { return blocks[i].Duration > blocks[j].Duration })
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', tabwriter.AlignRight)
+	fmt.Fprintln(tw, "  line\tblock\ttime\tshare\toutput\t")
+	for _, b := range blocks[:min(len(blocks), profileTop)] {
+		fmt.Fprintf(tw, "  %d\t%s\t%s\t%s\t%d B\t\n", b.Line, b.Kind, b.Duration.Round(time.Microsecond), share(b.Duration, render), b.OutputBytes)
+	}
+	tw.Flush()
+
+	// Kinds are sorted slowest first already
+	tw = tabwriter.NewWriter(w, 0, 0, 2, ' ', tabwriter.AlignRight)
+	fmt.Fprintln(tw, "  node kind\tcount\tself time\tshare\t")
+	for _, k := range p.Kinds[:min(len(p.Kinds), profileTop)] {
+		fmt.Fprintf(tw, "  %s\t%d\t%s\t%s\t\n", k.Kind, k.Count, k.Duration.Round(time.Microsecond), share(k.Duration, render))
+	}
+	tw.Flush()
+}
+
+// share formats part as a percentage of total
+func share(part, total time.Duration) string {
+	if total <= 0 {
+		return "-"
+	}
+	return fmt.Sprintf("%.1f%%", 100*float64(part)/float64(total))
+}
@@ -0,0 +1,334 @@
+// md2jira push creates or updates the issue a document describes in its front matter
+
+package main
+
+import (
+	"cmp"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/astsu-dev/md2jira/converter"
+	"github.com/astsu-dev/md2jira/jira"
+)
+
+// pushFields are the issue fields read from the front matter of a document
+type pushFields struct {
+	key       string
+	project   string
+	issueType string
+	summary   string
+	labels    []string
+	// name is the summary of a created issue when no summary is set
+	name string
+}
+
+// runPush runs the push subcommand and returns the exit code
+func runPush(args []string) int {
+	fs := flag.NewFlagSet("push", flag.ContinueOnError)
+	project := fs.String("project", "", "Project of a new issue when the front matter names none")
+	issueType := fs.String("issue-type", "Task", "Issue type of a new issue when the front matter names none")
+	noWrite := fs.Bool("no-write-key", false, "Do not add the key of a created issue to the front matter")
+	attach := fs.Bool("attach-images", false, "Attach local images to the issue and reference them by name")
+	stripTitle := fs.Bool("strip-title", false, "Remove the first H1 from the description and use it as the summary")
+	configFile := fs.String("config", "", "Configuration file")
+	remote := addRemoteFlags(fs)
+	dryRun := fs.Bool("dry-run", false, "Print the fields and the description without contacting JIRA")
+	force := fs.Bool("force", false, "Overwrite a description that was edited in JIRA since the last push")
+	fs.Usage = func() {
+		fmt.Fprintf(os.Stderr, `Usage:
+  md2jira push [options] doc.md
+
+Creates or updates a JIRA issue from a document: the front matter fields
+project, issuetype, summary and labels set the issue fields, and the rest of
+the document, converted, its description. A document whose front matter has
+a key updates that issue; otherwise an issue is created and its key added to
+the front matter, so the next push updates it. The summary defaults to the
+title field, or with --strip-title the first H1; a created issue without
+either is named after the file. The description ends in a provenance trailer,
+and an issue whose description was edited in JIRA since it was pushed is
+only overwritten with --force.
+JIRA_URL, JIRA_USER and JIRA_TOKEN configure the connection.
+
+    ---
+    project: PROJ
+    issuetype: Story
+    summary: Export reports as CSV
+    labels: [reports, export]
+    ---
+
+Options:
+  --project string
+                Project of a new issue when the front matter names none
+  --issue-type string
+                Issue type of a new issue when the front matter names none
+                (default: Task)
+  --no-write-key
+                Do not add the key of a created issue to the front matter
+  --attach-images
+                Upload the local images of the document as attachments of the
+                issue and render them as !name.png!
+  --strip-title Remove the first H1 from the description and use its text as
+                the summary, unless the front matter sets one
+  --dry-run     Print the fields and the description without contacting JIRA
+  --force       Overwrite a description that was edited in JIRA since the
+                last push
+`+remoteUsage+`  --config string
+                Read rendering defaults from this file instead of
+                .md2jira.yaml/.md2jira.toml
+`)
+	}
+	files, err := parseInterspersed(fs, args)
+	if err != nil {
+		if err == flag.ErrHelp {
+			return exitOK
+		}
+		return exitUsage
+	}
+	if len(files) != 1 {
+		fs.Usage()
+		return exitUsage
+	}
+	cfg, err := loadFlagConfig(fs, *configFile)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error reading config: %v\n", err)
+		return exitUsage
+	}
+
+	path := files[0]
+	source, err := readSource(path)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error reading input: %v\n", err)
+		return exitIO
+	}
+	opts := documentOptions(path, cfg, *attach, *stripTitle)
+	opts.ProvenanceTrailer = true
+	result, err := convertDocument(path, source, opts)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error converting: %v\n", err)
+		return exitConversion
+	}
+
+	fields, err := frontMatterFields(result.Metadata, path)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %s: %v\n", path, err)
+		return exitUsage
+	}
+	if fields.project == "" {
+		fields.project = *project
+	}
+	if fields.issueType == "" {
+		fields.issueType = *issueType
+	}
+	if fields.key == "" && fields.project == "" {
+		fmt.Fprintf(os.Stderr, "Error: %s names no key or project; set one in the front matter or use --project\n", path)
+		return exitUsage
+	}
+	if *dryRun {
+		if fields.key != "" {
+			fmt.Fprintf(os.Stderr, "Would update %s\n", fields.key)
+		} else {
+			fmt.Fprintf(os.Stderr, "Would create %s in %s: %s\n", fields.issueType, fields.project, cmp.Or(fields.summary, fields.name))
+		}
+		if fields.summary != "" {
+			fmt.Fprintf(os.Stderr, "Summary: %s\n", fields.summary)
+		}
+		if len(fields.labels) > 0 {
+			fmt.Fprintf(os.Stderr, "Labels: %s\n", strings.Join(fields.labels, ", "))
+		}
+		printAttachments(result.Attachments)
+		fmt.Println(result.Output)
+		return exitOK
+	}
+
+	client, err := remote.jiraClient()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		return exitUsage
+	}
+	key, created, err := pushIssue(client, fields, result.Output, *force)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		return exitIO
+	}
+	if err := uploadAttachments(client, key, result.Attachments); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		return exitIO
+	}
+	if !created || *noWrite {
+		return exitOK
+	}
+	if err := os.WriteFile(path, []byte(setFrontMatterKey(string(source), key, converter.HasFrontMatter(string(source)))), 0o644); err != nil {
+		fmt.Fprintf(os.Stderr, "Error writing key: %v\n", err)
+		return exitIO
+	}
+	return exitOK
+}
+
+// convertDocument converts a document for its issue description with the
+// options of documentOptions and prints the warnings
+func convertDocument(path string, source []byte, opts converter.Options) (converter.Result, error) {
+	result, err := converter.ConvertWithOptions(string(source), opts)
+	if err != nil {
+		return result, err
+	}
+	for _, w := range result.Warnings {
+		fmt.Fprintln(os.Stderr, formatWarning(path, w))
+	}
+	return result, nil
+}
+
+// documentOptions are the conversion options of an issue description; with
+// attach, local images are listed for uploading, and with stripTitle, the
+// first H1 is removed and returned as the title
+func documentOptions(path string, cfg *config, attach, stripTitle bool) converter.Options {
+	return converter.Options{
+		WarnOnUnsupported: true,
+		BaseDir:           filepath.Dir(path),
+		AttachLocalImages: attach,
+		StripTitle:        stripTitle,
+		LanguageMap:       cfg.languages,
+		Header:            cfg.header,
+		Footer:            cfg.footer,
+		SourcePath:        path,
+	}
+}
+
+// pushIssue updates the issue named by fields.key, or creates one, and
+// returns its key and whether it was created. Unless force is set, an issue
+// whose description was edited in JIRA since md2jira wrote it is left alone.
+func pushIssue(client *jira.Client, fields pushFields, description string, force bool) (string, bool, error) {
+	issue := map[string]interface{}{"description": description}
+	if fields.summary != "" {
+		issue["summary"] = fields.summary
+	}
+	if fields.labels != nil {
+		issue["labels"] = fields.labels
+	}
+	if fields.key != "" {
+		if !force {
+			if err := checkNotEdited(client, fields.key); err != nil {
+				return "", false, err
+			}
+		}
+		if err := client.UpdateIssueFields(fields.key, issue); err != nil {
+			return "", false, err
+		}
+		fmt.Fprintf(os.Stderr, "Updated %s\n", fields.key)
+		return fields.key, false, nil
+	}
+	if fields.summary == "" {
+		fields.summary = fields.name
+		issue["summary"] = fields.summary
+	}
+	issue["project"] = map[string]string{"key": fields.project}
+	issue["issuetype"] = map[string]string{"name": fields.issueType}
+	key, err := client.CreateIssueFields(issue)
+	if err != nil {
+		return "", false, err
+	}
+	fmt.Fprintf(os.Stderr, "Created %s: %s\n", key, fields.summary)
+	return key, true, nil
+}
+
+// checkNotEdited fetches the description of an issue and returns an error if
+// it no longer matches the provenance trailer md2jira wrote with it.
+// Descriptions without a trailer were not written by md2jira and pass.
+func checkNotEdited(client *jira.Client, key string) error {
+	issue, err := client.GetIssue(key)
+	if err != nil {
+		return err
+	}
+	if body, p, ok := converter.ParseProvenance(issue.Fields.Description); ok && !p.MatchesOutput(body) {
+		return fmt.Errorf("the description of %s was edited in JIRA since md2jira wrote it; use --force to overwrite it", key)
+	}
+	return nil
+}
+
+// frontMatterFields reads the issue fields from the front matter of path
+func frontMatterFields(meta map[string]any, path string) (pushFields, error) {
+	var fields pushFields
+	for name, target := range map[string]*string{
+		"key":       &fields.key,
+		"project":   &fields.project,
+		"issuetype": &fields.issueType,
+		"summary":   &fields.summary,
+	} {
+		value, ok := meta[name]
+		if !ok {
+			continue
+		}
+		s, ok := value.(string)
+		if !ok {
+			return fields, fmt.Errorf("front matter field %s must be a string", name)
+		}
+		*target = strings.TrimSpace(s)
+	}
+	if fields.summary == "" {
+		if title, ok := meta["title"].(string); ok {
+			fields.summary = strings.TrimSpace(title)
+		}
+	}
+	fields.name = strings.TrimSuffix(filepath.Base(path), filepath.Ext(path))
+
+	switch labels := meta["labels"].(type) {
+	case nil:
+	case string:
+		// labels: a, b
+		fields.labels = []string{}
+		for _, label := range strings.Split(labels, ",") {
+			if label = strings.TrimSpace(label); label != "" {
+				fields.labels = append(fields.labels, label)
+			}
+		}
+	case []any:
+		fields.labels = []string{}
+		for _, label := range labels {
+			s, ok := label.(string)
+			if !ok {
+				return fields, fmt.Errorf("front matter labels must be strings")
+			}
+			fields.labels = append(fields.labels, s)
+		}
+	default:
+		return fields, fmt.Errorf("front matter labels must be a list or a comma-separated string")
+	}
+	for _, label := range fields.labels {
+		// JIRA labels cannot contain spaces
+		if strings.ContainsAny(label, " \t") {
+			return fields, fmt.Errorf("label %q contains a space", label)
+		}
+	}
+	return fields, nil
+}
+
+// tomlTableRe matches a TOML [table] or [[array]] header line; keys after it
+// belong to the table
+var tomlTableRe = regexp.MustCompile(`^\s*\[\[?\s*[A-Za-z0-9_."' -]+\]\]?\s*(?:#.*)?$`)
+
+// setFrontMatterKey adds a key field to the end of the front matter of a
+// document, before the first table header of TOML front matter, or starts
+// the document with front matter holding only the key
+func setFrontMatterKey(source, key string, hasFrontMatter bool) string {
+	lines := strings.SplitAfter(source, "\n")
+	if fence := strings.TrimRight(lines[0], " \t\r\n"); hasFrontMatter {
+		field := "key: " + key
+		if fence == "+++" {
+			field = `key = "` + key + `"`
+		}
+		newline := "\n"
+		if strings.HasSuffix(lines[0], "\r\n") {
+			newline = "\r\n"
+		}
+		for i := 1; i < len(lines); i++ {
+			line := strings.TrimRight(lines[i], " \t\r\n")
+			if line == fence || (fence == "---" && line == "...") || (fence == "+++" && tomlTableRe.MatchString(line)) {
+				return strings.Join(lines[:i], "") + field + newline + strings.Join(lines[i:], "")
+			}
+		}
+	}
+	return "---\nkey: " + key + "\n---\n" + source
+}
@@ -0,0 +1,257 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/astsu-dev/md2jira/converter"
+)
+
+func TestSetFrontMatterKey(t *testing.T) {
+	tests := []struct {
+		name   string
+		source string
+		want   string
+	}{
+		{
+			name:   "yaml",
+			source: "---\ntitle: T\n---\nbody\n",
+			want:   "---\ntitle: T\nkey: PROJ-1\n---\nbody\n",
+		},
+		{
+			name:   "yaml with CRLF",
+			source: "---\r\ntitle: T\r\n---\r\nbody\r\n",
+			want:   "---\r\ntitle: T\r\nkey: PROJ-1\r\n---\r\nbody\r\n",
+		},
+		{
+			name:   "toml",
+			source: "+++\ntitle = \"T\"\n+++\nbody\n",
+			want:   "+++\ntitle = \"T\"\nkey = \"PROJ-1\"\n+++\nbody\n",
+		},
+		{
+			name:   "toml ending in a table",
+			source: "+++\ntitle = \"T\"\n\n[extra]\nowner = \"a\"\n+++\nbody\n",
+			want:   "+++\ntitle = \"T\"\n\nkey = \"PROJ-1\"\n[extra]\nowner = \"a\"\n+++\nbody\n",
+		},
+		{
+			name:   "toml array of tables",
+			source: "+++\n[[links]]  # first\nurl = \"u\"\n+++\nbody\n",
+			want:   "+++\nkey = \"PROJ-1\"\n[[links]]  # first\nurl = \"u\"\n+++\nbody\n",
+		},
+		{
+			name:   "toml array values",
+			source: "+++\ntags = [\n  \"a\",\n]\n+++\nbody\n",
+			want:   "+++\ntags = [\n  \"a\",\n]\nkey = \"PROJ-1\"\n+++\nbody\n",
+		},
+		{
+			name:   "no front matter",
+			source: "body\n",
+			want:   "---\nkey: PROJ-1\n---\nbody\n",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := setFrontMatterKey(tt.source, "PROJ-1", tt.source != "body\n")
+			if got != tt.want {
+				t.Errorf("got %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestPushCreatesThenUpdatesIssue(t *testing.T) {
+	srv := newFakeJIRA(t)
+	dir := t.TempDir()
+	path := filepath.Join(dir, "export.md")
+	writeTestFile(t, path, "+++\nproject = \"PROJ\"\nsummary = \"Export\"\n\n[extra]\nowner = \"a\"\n+++\n# Export\n\nAs *CSV*.\n")
+
+	if code := runPush([]string{path}); code != exitOK {
+		t.Fatalf("first push exited with %d", code)
+	}
+	issue := srv.issue("PROJ-1")
+	if issue == nil || issue["summary"] != "Export" || srv.descriptionBody("PROJ-1") != "h1. Export\n\nAs _CSV_." {
+		t.Fatalf("created issue = %v", issue)
+	}
+	project, _ := issue["project"].(map[string]any)
+	if project["key"] != "PROJ" {
+		t.Errorf("project = %v, want PROJ", issue["project"])
+	}
+	source, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(source), "key = \"PROJ-1\"\n[extra]") {
+		t.Fatalf("key not written before the table:\n%s", source)
+	}
+
+	writeTestFile(t, path, strings.Replace(string(source), "As *CSV*.", "As *JSON*.", 1))
+	if code := runPush([]string{path}); code != exitOK {
+		t.Fatalf("second push exited with %d", code)
+	}
+	if got := srv.descriptionBody("PROJ-1"); got != "h1. Export\n\nAs _JSON_." {
+		t.Errorf("updated description = %q", got)
+	}
+	if n := srv.created(); n != 1 {
+		t.Errorf("%d issues created, want 1", n)
+	}
+}
+
+func TestPushKeepsDescriptionsEditedInJIRA(t *testing.T) {
+	srv := newFakeJIRA(t)
+	path := filepath.Join(t.TempDir(), "doc.md")
+	writeTestFile(t, path, "---\nproject: PROJ\n---\nFirst.\n")
+	if code := runPush([]string{path}); code != exitOK {
+		t.Fatalf("push exited with %d", code)
+	}
+	source, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	writeTestFile(t, path, strings.Replace(string(source), "First.", "Second.", 1))
+
+	// A colleague adds a line in JIRA, above the trailer
+	edited := strings.Replace(srv.issue("PROJ-1")["description"].(string), "First.", "First.\n\nEdited in JIRA.", 1)
+	srv.setDescription("PROJ-1", edited)
+	if code := runPush([]string{path}); code != exitIO {
+		t.Fatalf("push over an edited description exited with %d, want %d", code, exitIO)
+	}
+	if got := srv.issue("PROJ-1")["description"]; got != edited {
+		t.Errorf("edited description overwritten with %q", got)
+	}
+
+	if code := runPush([]string{"--force", path}); code != exitOK {
+		t.Fatalf("push --force exited with %d", code)
+	}
+	if got := srv.descriptionBody("PROJ-1"); got != "Second." {
+		t.Errorf("description after --force = %q, want Second.", got)
+	}
+
+	// Descriptions without a trailer were not written by md2jira
+	srv.setDescription("PROJ-1", "Written by hand")
+	if code := runPush([]string{path}); code != exitOK {
+		t.Fatalf("push over a description without a trailer exited with %d", code)
+	}
+}
+
+// fakeJIRA is an in-memory JIRA REST API for the issue endpoints, which the
+// JIRA_URL and JIRA_TOKEN of the test point to
+type fakeJIRA struct {
+	mu     sync.Mutex
+	issues map[string]map[string]any
+	// puts counts the updates of each issue
+	puts map[string]int
+	// comments lists the comments added to each issue
+	comments map[string][]string
+}
+
+// newFakeJIRA starts a fakeJIRA for the duration of the test
+func newFakeJIRA(t *testing.T) *fakeJIRA {
+	t.Helper()
+	f := &fakeJIRA{issues: map[string]map[string]any{}, puts: map[string]int{}, comments: map[string][]string{}}
+	srv := httptest.NewServer(http.HandlerFunc(f.serve))
+	t.Cleanup(srv.Close)
+	t.Setenv("JIRA_URL", srv.URL)
+	t.Setenv("JIRA_USER", "")
+	t.Setenv("JIRA_TOKEN", "token")
+	return f
+}
+
+func (f *fakeJIRA) serve(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if r.Header.Get("Authorization") != "Bearer token" {
+		http.Error(w, `{"errorMessages":["unauthorized"]}`, http.StatusUnauthorized)
+		return
+	}
+	var body struct {
+		Fields map[string]any `json:"fields"`
+		Body   string         `json:"body"`
+	}
+	if r.Body != nil && r.Method != http.MethodGet {
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+			http.Error(w, `{"errorMessages":["invalid JSON"]}`, http.StatusBadRequest)
+			return
+		}
+	}
+	key, _ := strings.CutPrefix(r.URL.Path, "/rest/api/2/issue/")
+	key, comment := strings.CutSuffix(key, "/comment")
+	switch {
+	case r.Method == http.MethodPost && comment && f.issues[key] != nil:
+		f.comments[key] = append(f.comments[key], body.Body)
+		fmt.Fprint(w, `{"id":"1"}`)
+	case r.Method == http.MethodPost && r.URL.Path == "/rest/api/2/issue":
+		key = fmt.Sprintf("PROJ-%d", len(f.issues)+1)
+		f.issues[key] = body.Fields
+		fmt.Fprintf(w, `{"key":%q}`, key)
+	case r.Method == http.MethodPut && f.issues[key] != nil:
+		for name, value := range body.Fields {
+			f.issues[key][name] = value
+		}
+		f.puts[key]++
+		w.WriteHeader(http.StatusNoContent)
+	case r.Method == http.MethodGet && f.issues[key] != nil:
+		json.NewEncoder(w).Encode(map[string]any{"key": key, "fields": f.issues[key]})
+	default:
+		http.Error(w, `{"errorMessages":["Issue does not exist"]}`, http.StatusNotFound)
+	}
+}
+
+// commentsOf returns the comments added to an issue
+func (f *fakeJIRA) commentsOf(key string) []string {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.comments[key]
+}
+
+// addIssue adds an issue with the given fields, as one created in JIRA
+func (f *fakeJIRA) addIssue(key string, fields map[string]any) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.issues[key] = fields
+}
+
+// updates returns the number of times an issue was updated
+func (f *fakeJIRA) updates(key string) int {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.puts[key]
+}
+
+// issue returns the fields of an issue, or nil
+func (f *fakeJIRA) issue(key string) map[string]any {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.issues[key]
+}
+
+// setDescription replaces the description of an issue, as an edit in JIRA
+func (f *fakeJIRA) setDescription(key, description string) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.issues[key]["description"] = description
+}
+
+// descriptionBody returns the description of an issue without its
+// provenance trailer, or a note that it has none
+func (f *fakeJIRA) descriptionBody(key string) string {
+	description, _ := f.issue(key)["description"].(string)
+	body, _, ok := converter.ParseProvenance(description)
+	if !ok {
+		return "no trailer in " + description
+	}
+	return body
+}
+
+// created returns the number of issues created
+func (f *fakeJIRA) created() int {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return len(f.issues)
+}
@@ -0,0 +1,218 @@
+// md2jira release publishes a changelog section to a release ticket or fix version
+
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/astsu-dev/md2jira/converter"
+	"github.com/astsu-dev/md2jira/jira"
+)
+
+// runRelease runs the release subcommand and returns the exit code
+func runRelease(args []string) int {
+	fs := flag.NewFlagSet("release", flag.ContinueOnError)
+	changelog := fs.String("changelog", "CHANGELOG.md", "Changelog file")
+	version := fs.String("version", "", "Version whose section is published")
+	project := fs.String("project", "", "Project key")
+	issue := fs.String("issue", "", "Update this issue instead of searching for the release ticket")
+	summary := fs.String("summary", "", `Release ticket summary (default "Release <version>")`)
+	issueType := fs.String("issue-type", "Task", "Issue type of a new release ticket")
+	fixVersion := fs.Bool("fix-version", false, "Update the fix version description instead of a ticket")
+	configFile := fs.String("config", "", "Configuration file")
+	remote := addRemoteFlags(fs)
+	dryRun := fs.Bool("dry-run", false, "Print the converted section without contacting JIRA")
+	fs.Usage = func() {
+		fmt.Fprintf(os.Stderr, `Usage:
+  md2jira release --version 1.4.0 --project PROJ [options]
+
+Extracts the section for a version from a changelog, converts it and creates
+or updates the release ticket "Release <version>" (or the fix version
+description with --fix-version). JIRA_URL, JIRA_USER and JIRA_TOKEN
+configure the connection; leave JIRA_USER empty to use a personal access token.
+
+Options:
+  --changelog string
+                Changelog file (default: CHANGELOG.md)
+  --version string
+                Version whose section is published (required)
+  --project string
+                Project key (required unless --issue is set)
+  --issue string
+                Update this issue instead of searching for the release ticket
+  --summary string
+                Release ticket summary (default: "Release <version>")
+  --issue-type string
+                Issue type of a new release ticket (default: Task)
+  --fix-version Update the fix version description instead of a ticket
+  --dry-run     Print the converted section without contacting JIRA
+`+remoteUsage+`  --config string
+                Read rendering defaults from this file instead of
+                .md2jira.yaml/.md2jira.toml
+`)
+	}
+	if _, err := parseInterspersed(fs, args); err != nil {
+		if err == flag.ErrHelp {
+			return exitOK
+		}
+		return exitUsage
+	}
+	cfg, err := loadFlagConfig(fs, *configFile)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error reading config: %v\n", err)
+		return exitUsage
+	}
+	if *version == "" || (*project == "" && (*issue == "" || *fixVersion) && !*dryRun) {
+		fs.Usage()
+		return exitUsage
+	}
+	if *summary == "" {
+		*summary = "Release " + *version
+	}
+
+	notes, code := releaseNotes(*changelog, *version, cfg)
+	if code != exitOK {
+		return code
+	}
+	if *dryRun {
+		fmt.Println(notes)
+		return exitOK
+	}
+
+	client, err := remote.jiraClient()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		return exitUsage
+	}
+	if err := publishRelease(client, notes, *project, *issue, *summary, *issueType, *version, *fixVersion); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		return exitIO
+	}
+	return exitOK
+}
+
+// releaseNotes extracts and converts the section of a changelog for a
+// version, printing the warnings; on failure it prints the error and
+// returns the exit code
+func releaseNotes(changelog, version string, cfg *config) (string, int) {
+	source, err := readSource(changelog)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error reading changelog: %v\n", err)
+		return "", exitIO
+	}
+	section, ok := changelogSection(string(source), version)
+	if !ok {
+		fmt.Fprintf(os.Stderr, "Error: %s has no section for version %s\n", changelog, version)
+		return "", exitConversion
+	}
+
+	result, err := converter.ConvertWithOptions(section, converter.Options{
+		WarnOnUnsupported: true,
+		BaseDir:           filepath.Dir(changelog),
+		LanguageMap:       cfg.languages,
+		Header:            cfg.header,
+		Footer:            cfg.footer,
+		SourcePath:        changelog,
+	})
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error converting: %v\n", err)
+		return "", exitConversion
+	}
+	for _, w := range result.Warnings {
+		fmt.Fprintln(os.Stderr, formatWarning(changelog, w))
+	}
+	return result.Output, exitOK
+}
+
+// publishRelease writes the converted release notes to JIRA
+func publishRelease(client *jira.Client, notes, project, issue, summary, issueType, version string, fixVersion bool) error {
+	if fixVersion {
+		v, err := client.FindVersion(project, version)
+		if err != nil {
+			return err
+		}
+		if v == nil {
+			return fmt.Errorf("project %s has no version %s", project, version)
+		}
+		if err := client.UpdateVersionDescription(v.ID, notes); err != nil {
+			return err
+		}
+		fmt.Fprintf(os.Stderr, "Updated description of %s %s\n", project, version)
+		return nil
+	}
+
+	if issue == "" {
+		issues, err := client.SearchIssues("project = "+jira.QuoteJQL(project)+" AND summary ~ "+jira.QuoteJQL(jira.QuoteJQL(summary)), 20)
+		if err != nil {
+			return err
+		}
+		// summary ~ is a text search; only an exact summary is the release ticket
+		for _, found := range issues {
+			if found.Fields.Summary == summary {
+				issue = found.Key
+				break
+			}
+		}
+	}
+	if issue == "" {
+		key, err := client.CreateIssue(project, issueType, summary, notes)
+		if err != nil {
+			return err
+		}
+		fmt.Fprintf(os.Stderr, "Created %s: %s\n", key, summary)
+		return nil
+	}
+	if err := client.UpdateDescription(issue, notes); err != nil {
+		return err
+	}
+	fmt.Fprintf(os.Stderr, "Updated %s\n", issue)
+	return nil
+}
+
+// atxHeadingRe matches an ATX heading, capturing its level and text
+var atxHeadingRe = regexp.MustCompile(`^ {0,3}(#{1,6})(?:[ \t]+(.*?))?(?:[ \t]+#+)?[ \t]*$`)
+
+// changelogSection returns the body of the heading naming version, up to the
+// next heading of the same or a higher level. Headings such as "## 1.4.0",
+// "## [1.4.0] - 2024-05-01" and "## v1.4.0" are recognized.
+func changelogSection(markdown, version string) (string, bool) {
+	versionRe := regexp.MustCompile(`(?:^|[\s\[(])v?` + regexp.QuoteMeta(version) + `(?:$|[\s\]),:])`)
+	lines := strings.SplitAfter(markdown, "\n")
+
+	level, start := 0, -1
+	fence := ""
+	for i, line := range lines {
+		trimmed := strings.TrimSpace(line)
+		// Headings inside fenced code blocks do not count
+		if fence != "" {
+			if strings.HasPrefix(trimmed, fence) {
+				fence = ""
+			}
+			continue
+		}
+		if strings.HasPrefix(trimmed, "```") || strings.HasPrefix(trimmed, "~~~") {
+			fence = trimmed[:3]
+			continue
+		}
+
+		m := atxHeadingRe.FindStringSubmatch(strings.TrimRight(line, "\r\n"))
+		if m == nil {
+			continue
+		}
+		if start >= 0 && len(m[1]) <= level {
+			return strings.TrimSpace(strings.Join(lines[start:i], "")), true
+		}
+		if start < 0 && versionRe.MatchString(m[2]) {
+			level, start = len(m[1]), i+1
+		}
+	}
+	if start < 0 {
+		return "", false
+	}
+	return strings.TrimSpace(strings.Join(lines[start:], "")), true
+}
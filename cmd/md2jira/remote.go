@@ -0,0 +1,111 @@
+// Outbound connections: proxies, certificate authorities and client
+// certificates for JIRA, GitHub, webhooks and remote link checks
+
+package main
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"flag"
+	"fmt"
+	"net/http"
+	"os"
+	"time"
+
+	"github.com/astsu-dev/md2jira/jira"
+)
+
+// remoteUsage documents the flags added by addRemoteFlags
+const remoteUsage = `  --ca-cert file
+                Also trust the certificate authorities in this PEM file,
+                e.g. an internal CA
+  --client-cert file
+                Present this PEM client certificate (with --client-key)
+  --client-key file
+                Private key of --client-cert
+  --insecure-skip-verify
+                Do not verify server certificates; for testing only
+                (these four are only taken from the command line)
+`
+
+// remoteFlags configure the HTTP transport of outbound requests. Proxies are
+// taken from HTTP_PROXY, HTTPS_PROXY and NO_PROXY. None of the flags are
+// configFlags, so a configuration file cannot weaken certificate checks.
+type remoteFlags struct {
+	caCert     *string
+	clientCert *string
+	clientKey  *string
+	insecure   *bool
+}
+
+// addRemoteFlags defines the connection flags on fs
+func addRemoteFlags(fs *flag.FlagSet) *remoteFlags {
+	return &remoteFlags{
+		caCert:     fs.String("ca-cert", "", "PEM file of additional certificate authorities"),
+		clientCert: fs.String("client-cert", "", "PEM client certificate"),
+		clientKey:  fs.String("client-key", "", "PEM private key of --client-cert"),
+		insecure:   fs.Bool("insecure-skip-verify", false, "Do not verify server certificates"),
+	}
+}
+
+// transport returns the HTTP transport for outbound requests
+func (f *remoteFlags) transport() (*http.Transport, error) {
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.Proxy = http.ProxyFromEnvironment
+	if *f.caCert == "" && *f.clientCert == "" && *f.clientKey == "" && !*f.insecure {
+		return transport, nil
+	}
+
+	config := &tls.Config{InsecureSkipVerify: *f.insecure}
+	if *f.caCert != "" {
+		pem, err := os.ReadFile(*f.caCert)
+		if err != nil {
+			return nil, fmt.Errorf("--ca-cert: %v", err)
+		}
+		pool, err := x509.SystemCertPool()
+		if err != nil {
+			pool = x509.NewCertPool()
+		}
+		if !pool.AppendCertsFromPEM(pem) {
+			return nil, fmt.Errorf("--ca-cert: %s holds no PEM certificates", *f.caCert)
+		}
+		config.RootCAs = pool
+	}
+	if *f.clientCert != "" || *f.clientKey != "" {
+		if *f.clientCert == "" || *f.clientKey == "" {
+			return nil, fmt.Errorf("--client-cert and --client-key must be given together")
+		}
+		cert, err := tls.LoadX509KeyPair(*f.clientCert, *f.clientKey)
+		if err != nil {
+			return nil, fmt.Errorf("--client-cert: %v", err)
+		}
+		config.Certificates = []tls.Certificate{cert}
+	}
+	if *f.insecure {
+		fmt.Fprintln(os.Stderr, "Warning: server certificates are not verified (--insecure-skip-verify)")
+	}
+	transport.TLSClientConfig = config
+	return transport, nil
+}
+
+// httpClient returns a client for outbound requests with a timeout
+func (f *remoteFlags) httpClient(timeout time.Duration) (*http.Client, error) {
+	transport, err := f.transport()
+	if err != nil {
+		return nil, err
+	}
+	return &http.Client{Timeout: timeout, Transport: transport}, nil
+}
+
+// jiraClient creates a JIRA client from the environment that connects
+// through the configured transport
+func (f *remoteFlags) jiraClient() (*jira.Client, error) {
+	client, err := jira.NewClientFromEnv()
+	if err != nil {
+		return nil, err
+	}
+	if client.HTTPClient, err = f.httpClient(30 * time.Second); err != nil {
+		return nil, err
+	}
+	return client, nil
+}
@@ -0,0 +1,217 @@
+package main
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/pem"
+	"flag"
+	"io"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// testRemoteFlags parses args as the connection flags
+func testRemoteFlags(t *testing.T, args ...string) *remoteFlags {
+	t.Helper()
+	fs := flag.NewFlagSet("test", flag.ContinueOnError)
+	remote := addRemoteFlags(fs)
+	if err := fs.Parse(args); err != nil {
+		t.Fatal(err)
+	}
+	return remote
+}
+
+// writePEM writes a PEM block of the given type to a file in dir
+func writePEM(t *testing.T, dir, name, blockType string, der []byte) string {
+	t.Helper()
+	path := filepath.Join(dir, name)
+	if err := os.WriteFile(path, pem.EncodeToMemory(&pem.Block{Type: blockType, Bytes: der}), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+// captureStderr returns what fn writes to os.Stderr
+func captureStderr(t *testing.T, fn func()) string {
+	t.Helper()
+	r, w, err := os.Pipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+	stderr := os.Stderr
+	os.Stderr = w
+	defer func() { os.Stderr = stderr }()
+	fn()
+	w.Close()
+	out, _ := io.ReadAll(r)
+	return string(out)
+}
+
+func TestRemoteCertificateAuthority(t *testing.T) {
+	srv := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer srv.Close()
+	dir := t.TempDir()
+	ca := writePEM(t, dir, "ca.pem", "CERTIFICATE", srv.Certificate().Raw)
+	notPEM := filepath.Join(dir, "empty.pem")
+	writeTestFile(t, notPEM, "not a certificate")
+
+	tests := []struct {
+		name    string
+		args    []string
+		wantErr string
+	}{
+		{"system roots only", nil, "certificate"},
+		{"trusted CA", []string{"--ca-cert", ca}, ""},
+		{"file without certificates", []string{"--ca-cert", notPEM}, "no PEM certificates"},
+		{"client certificate without key", []string{"--client-cert", ca}, "given together"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client, err := testRemoteFlags(t, tt.args...).httpClient(5 * time.Second)
+			if err == nil {
+				var resp *http.Response
+				if resp, err = client.Get(srv.URL); err == nil {
+					resp.Body.Close()
+				}
+			}
+			if tt.wantErr == "" && err != nil {
+				t.Fatalf("request failed: %v", err)
+			}
+			if tt.wantErr != "" && (err == nil || !strings.Contains(err.Error(), tt.wantErr)) {
+				t.Fatalf("error = %v, want one containing %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestRemoteInsecureSkipVerifyWarns(t *testing.T) {
+	srv := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer srv.Close()
+
+	var client *http.Client
+	stderr := captureStderr(t, func() {
+		var err error
+		if client, err = testRemoteFlags(t, "--insecure-skip-verify").httpClient(5 * time.Second); err != nil {
+			t.Fatal(err)
+		}
+	})
+	if !strings.Contains(stderr, "not verified") {
+		t.Errorf("stderr = %q, want a warning that certificates are not verified", stderr)
+	}
+	resp, err := client.Get(srv.URL)
+	if err != nil {
+		t.Fatalf("request failed: %v", err)
+	}
+	resp.Body.Close()
+
+	if stderr := captureStderr(t, func() { testRemoteFlags(t).httpClient(time.Second) }); stderr != "" {
+		t.Errorf("stderr = %q without --insecure-skip-verify", stderr)
+	}
+}
+
+func TestRemoteClientCertificate(t *testing.T) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	dir := t.TempDir()
+	certFile := writePEM(t, dir, "client.pem", "CERTIFICATE", der)
+	keyFile := writePEM(t, dir, "client.key", "EC PRIVATE KEY", keyDER)
+
+	var presented int
+	srv := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		presented = len(r.TLS.PeerCertificates)
+	}))
+	srv.TLS = &tls.Config{ClientAuth: tls.RequireAnyClientCert}
+	srv.StartTLS()
+	defer srv.Close()
+	ca := writePEM(t, dir, "ca.pem", "CERTIFICATE", srv.Certificate().Raw)
+
+	client, err := testRemoteFlags(t, "--ca-cert", ca, "--client-cert", certFile, "--client-key", keyFile).httpClient(5 * time.Second)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp, err := client.Get(srv.URL)
+	if err != nil {
+		t.Fatalf("request failed: %v", err)
+	}
+	resp.Body.Close()
+	if presented != 1 {
+		t.Errorf("server saw %d client certificates, want 1", presented)
+	}
+}
+
+func TestRemoteProxyFromEnvironment(t *testing.T) {
+	// http.ProxyFromEnvironment reads the environment once per process, so
+	// the requests are made by a copy of the test binary
+	if os.Getenv("MD2JIRA_TEST_PROXY") == "1" {
+		client, err := testRemoteFlags(t).httpClient(5 * time.Second)
+		if err != nil {
+			t.Fatal(err)
+		}
+		resp, err := client.Get("http://jira.example/rest/api/2/myself")
+		if err != nil {
+			t.Fatalf("request through %s failed: %v", os.Getenv("HTTP_PROXY"), err)
+		}
+		resp.Body.Close()
+		if resp.Header.Get("Via-Test-Proxy") != "yes" {
+			t.Error("the request did not go through HTTP_PROXY")
+		}
+		req, _ := http.NewRequest(http.MethodGet, "http://direct.example/", nil)
+		transport, err := testRemoteFlags(t).transport()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if u, err := transport.Proxy(req); u != nil || err != nil {
+			t.Errorf("proxy for a NO_PROXY host = %v, %v", u, err)
+		}
+		return
+	}
+
+	var proxied []string
+	proxy := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		proxied = append(proxied, r.URL.String())
+		w.Header().Set("Via-Test-Proxy", "yes")
+	}))
+	defer proxy.Close()
+	cmd := exec.Command(os.Args[0], "-test.run=^TestRemoteProxyFromEnvironment$")
+	cmd.Env = append(os.Environ(), "MD2JIRA_TEST_PROXY=1", "HTTP_PROXY="+proxy.URL, "http_proxy="+proxy.URL, "NO_PROXY=direct.example", "no_proxy=direct.example")
+	if out, err := cmd.CombinedOutput(); err != nil {
+		t.Fatalf("%v\n%s", err, out)
+	}
+	if len(proxied) != 1 || proxied[0] != "http://jira.example/rest/api/2/myself" {
+		t.Errorf("proxy saw %q, want the JIRA request", proxied)
+	}
+}
+
+// writeTestFile writes a test file, failing the test on errors
+func writeTestFile(t *testing.T, path, data string) {
+	t.Helper()
+	if err := os.WriteFile(path, []byte(data), 0o644); err != nil {
+		t.Fatal(err)
+	}
+}
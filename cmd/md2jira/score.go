@@ -0,0 +1,396 @@
+// md2jira score measures conversion fidelity against a corpus of expected outputs
+
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/astsu-dev/md2jira/converter"
+)
+
+// scoreConstructs are the constructs scored separately, in report order
+var scoreConstructs = []string{"heading", "text", "list", "table", "code", "quote", "callout", "expand", "image", "rule"}
+
+// scoreDocument is the score of one corpus document
+type scoreDocument struct {
+	Path string `json:"path"`
+	// Score is the percentage of matching lines
+	Score float64 `json:"score"`
+	// Expected and Actual count the non-blank lines of each output, and
+	// Matched the lines they have in common, in order
+	Expected int `json:"expected"`
+	Actual   int `json:"actual"`
+	Matched  int `json:"matched"`
+	// diff lists the lines that differ, prefixed with - (expected) or + (actual)
+	diff []string
+}
+
+// scoreConstruct is the score of one construct across the corpus
+type scoreConstruct struct {
+	Construct string  `json:"construct"`
+	Score     float64 `json:"score"`
+	Expected  int     `json:"expected"`
+	Actual    int     `json:"actual"`
+	Matched   int     `json:"matched"`
+}
+
+// scoreReport is the --json output of md2jira score
+type scoreReport struct {
+	Score      float64          `json:"score"`
+	Documents  []scoreDocument  `json:"documents"`
+	Constructs []scoreConstruct `json:"constructs"`
+	// Missing lists documents without an expected output
+	Missing []string `json:"missing,omitempty"`
+}
+
+// runScore runs the score subcommand and returns the exit code: exitOK, or
+// exitWarnings when the overall score is below --fail-under
+func runScore(args []string) int {
+	fs := flag.NewFlagSet("score", flag.ContinueOnError)
+	update := fs.Bool("update", false, "Write the current output as the expected output")
+	jsonOutput := fs.Bool("json", false, "Emit the report as JSON")
+	showDiff := fs.Bool("diff", false, "Show the lines that differ")
+	failUnder := fs.Float64("fail-under", 0, "Exit with code 4 if the overall score is below this percentage")
+	configFile := fs.String("config", "", "Configuration file")
+	compat := fs.String("compat", "latest", "Compatibility level to render at")
+	escape := fs.String("escape", "minimal", "Escaping of JIRA markup characters: none, minimal or aggressive")
+	dialect := fs.String("dialect", "server", "Jira deployment the markup targets: server, datacenter or cloud")
+	fs.Usage = func() {
+		fmt.Fprintf(os.Stderr, `Usage:
+  md2jira score [options] corpus/...
+
+Converts every .md file under the corpus directories and compares the result
+with the expected output stored next to it (foo.md -> foo.jira). Reports the
+percentage of matching lines per document, per construct (headings, lists,
+tables, code, ...) and overall, so conversions can be compared between
+releases. Blank lines are ignored.
+
+Options:
+  --update      Write the current output as the expected output of every
+                document instead of scoring
+  --json        Emit the report as JSON
+  --diff        Show the lines that differ, - expected and + actual
+  --fail-under float
+                Exit with 4 if the overall score is below this percentage
+  --config string
+                Read defaults from this file instead of .md2jira.yaml/.md2jira.toml
+  --compat string
+                Render at this compatibility level (default: latest)
+  --escape string
+                Escaping of JIRA markup characters: none, minimal (default) or aggressive
+  --dialect string
+                Jira deployment the markup targets: server (default), datacenter or cloud
+`)
+	}
+	roots, err := parseInterspersed(fs, args)
+	if err != nil {
+		if err == flag.ErrHelp {
+			return exitOK
+		}
+		return exitUsage
+	}
+	if len(roots) == 0 {
+		fs.Usage()
+		return exitUsage
+	}
+	cfg, err := loadFlagConfig(fs, *configFile)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error reading config: %v\n", err)
+		return exitUsage
+	}
+
+	opts := converter.Options{
+		LanguageMap: cfg.languages,
+		Mentions:    cfg.mentions,
+		Header:      cfg.header,
+		Footer:      cfg.footer,
+	}
+	if opts.CompatLevel, err = converter.ParseCompatLevel(*compat); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		return exitUsage
+	}
+	if opts.EscapeMode, err = converter.ParseEscapeMode(*escape); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		return exitUsage
+	}
+	if opts.Dialect, err = converter.ParseDialect(*dialect); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		return exitUsage
+	}
+
+	var files []string
+	for _, root := range roots {
+		err := filepath.WalkDir(root, func(path string, d os.DirEntry, err error) error {
+			if err != nil {
+				return err
+			}
+			if !d.IsDir() && matchesAny(d.Name(), markdownPatterns) {
+				files = append(files, path)
+			}
+			return nil
+		})
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error reading %s: %v\n", root, err)
+			return exitIO
+		}
+	}
+	sort.Strings(files)
+
+	report := scoreReport{}
+	totals := make(map[string]*scoreConstruct)
+	for _, name := range scoreConstructs {
+		totals[name] = &scoreConstruct{Construct: name}
+	}
+	var expected, actual, matched int
+	for _, file := range files {
+		input, err := readSource(file)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error reading %s: %v\n", file, err)
+			return exitIO
+		}
+		opts.BaseDir = filepath.Dir(file)
+		opts.SourcePath = file
+		result, err := converter.ConvertWithOptions(string(input), opts)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error converting %s: %v\n", file, err)
+			return exitConversion
+		}
+
+		expectedPath := strings.TrimSuffix(file, filepath.Ext(file)) + ".jira"
+		if *update {
+			if err := os.WriteFile(expectedPath, []byte(result.Output+"\n"), 0o644); err != nil {
+				fmt.Fprintf(os.Stderr, "Error writing %s: %v\n", expectedPath, err)
+				return exitIO
+			}
+			continue
+		}
+		want, err := os.ReadFile(expectedPath)
+		if os.IsNotExist(err) {
+			report.Missing = append(report.Missing, file)
+			continue
+		}
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error reading %s: %v\n", expectedPath, err)
+			return exitIO
+		}
+
+		doc := scoreOutput(file, string(want), result.Output, totals)
+		report.Documents = append(report.Documents, doc)
+		expected += doc.Expected
+		actual += doc.Actual
+		matched += doc.Matched
+	}
+	if *update {
+		fmt.Fprintf(os.Stderr, "Wrote the expected output of %d documents\n", len(files))
+		return exitOK
+	}
+
+	report.Score = scorePercent(matched, expected, actual)
+	for _, name := range scoreConstructs {
+		if c := totals[name]; c.Expected+c.Actual > 0 {
+			c.Score = scorePercent(c.Matched, c.Expected, c.Actual)
+			report.Constructs = append(report.Constructs, *c)
+		}
+	}
+
+	if *jsonOutput {
+		encoder := json.NewEncoder(os.Stdout)
+		encoder.SetIndent("", "  ")
+		if err := encoder.Encode(report); err != nil {
+			fmt.Fprintf(os.Stderr, "Error writing report: %v\n", err)
+			return exitIO
+		}
+	} else {
+		printScoreReport(report, *showDiff)
+	}
+
+	if len(report.Documents) > 0 && report.Score < *failUnder {
+		return exitWarnings
+	}
+	return exitOK
+}
+
+// printScoreReport writes the plain text report
+func printScoreReport(report scoreReport, showDiff bool) {
+	differ := 0
+	for _, doc := range report.Documents {
+		if doc.Matched < doc.Expected || doc.Matched < doc.Actual {
+			differ++
+		}
+		fmt.Printf("%6.1f%%  %s\n", doc.Score, doc.Path)
+		if showDiff {
+			for _, line := range doc.diff {
+				fmt.Printf("          %s\n", line)
+			}
+		}
+	}
+	for _, file := range report.Missing {
+		fmt.Printf("     -   %s (no expected output, run with --update)\n", file)
+	}
+	if len(report.Documents) == 0 {
+		fmt.Println("No documents with an expected output")
+		return
+	}
+
+	fmt.Printf("\n%-10s %7s %9s %7s\n", "Construct", "Score", "Expected", "Actual")
+	for _, c := range report.Constructs {
+		fmt.Printf("%-10s %6.1f%% %9d %7d\n", c.Construct, c.Score, c.Expected, c.Actual)
+	}
+	fmt.Printf("\nOverall %.1f%% (%d documents, %d differ)\n", report.Score, len(report.Documents), differ)
+}
+
+// scoreOutput compares the expected and actual output of a document line by
+// line, adding the lines of each construct to totals
+func scoreOutput(path, want, got string, totals map[string]*scoreConstruct) scoreDocument {
+	wantLines, gotLines := scoreLines(want), scoreLines(got)
+	wantKinds, gotKinds := classifyLines(wantLines), classifyLines(gotLines)
+	doc := scoreDocument{Path: path, Expected: len(wantLines), Actual: len(gotLines)}
+	for _, kind := range wantKinds {
+		totals[kind].Expected++
+	}
+	for _, kind := range gotKinds {
+		totals[kind].Actual++
+	}
+
+	// Longest common subsequence of the lines
+	n, m := len(wantLines), len(gotLines)
+	lcs := make([][]int32, n+1)
+	for i := range lcs {
+		lcs[i] = make([]int32, m+1)
+	}
+	for i := n - 1; i >= 0; i-- {
+		for j := m - 1; j >= 0; j-- {
+			if wantLines[i] == gotLines[j] {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else {
+				lcs[i][j] = max(lcs[i+1][j], lcs[i][j+1])
+			}
+		}
+	}
+	i, j := 0, 0
+	for i < n || j < m {
+		switch {
+		case i < n && j < m && wantLines[i] == gotLines[j]:
+			doc.Matched++
+			// A matched line may be classified differently by its context; it
+			// counts for the construct expected
+			totals[wantKinds[i]].Matched++
+			i, j = i+1, j+1
+		case i < n && (j == m || lcs[i+1][j] >= lcs[i][j+1]):
+			doc.diff = append(doc.diff, "- "+wantLines[i])
+			i++
+		default:
+			doc.diff = append(doc.diff, "+ "+gotLines[j])
+			j++
+		}
+	}
+	doc.Score = scorePercent(doc.Matched, doc.Expected, doc.Actual)
+	return doc
+}
+
+// scorePercent returns the share of matching lines in both outputs
+func scorePercent(matched, expected, actual int) float64 {
+	if expected+actual == 0 {
+		return 100
+	}
+	return 100 * float64(2*matched) / float64(expected+actual)
+}
+
+// scoreLines splits output into its non-blank lines, without trailing spaces
+func scoreLines(output string) []string {
+	var lines []string
+	for _, line := range strings.Split(strings.ReplaceAll(output, "\r\n", "\n"), "\n") {
+		if line = strings.TrimRight(line, " \t"); line != "" {
+			lines = append(lines, line)
+		}
+	}
+	return lines
+}
+
+var (
+	// scoreHeadingRe matches h1. to h6. headings
+	scoreHeadingRe = regexp.MustCompile(`^h[1-6]\.\s`)
+	// scoreListRe matches bullet and numbered list items
+	scoreListRe = regexp.MustCompile(`^(?:[*#]+|-) `)
+	// scoreImageRe matches a line holding only an image
+	scoreImageRe = regexp.MustCompile(`^![^\s!][^!]*!$`)
+	// scoreMacroRe matches a line opening or closing a block macro
+	scoreMacroRe = regexp.MustCompile(`^\{(code|noformat|quote|panel|info|note|tip|warning|expand)(?::[^}]*)?\}`)
+)
+
+// scoreContainers maps block macros to the construct of their lines
+var scoreContainers = map[string]string{
+	"code":     "code",
+	"noformat": "code",
+	"quote":    "quote",
+	"panel":    "callout",
+	"info":     "callout",
+	"note":     "callout",
+	"tip":      "callout",
+	"warning":  "callout",
+	"expand":   "expand",
+}
+
+// classifyLines returns the construct of each line of JIRA markup. Lines of
+// code blocks, quotes and callouts belong to that construct; the content of
+// an {expand} keeps its own.
+func classifyLines(lines []string) []string {
+	kinds := make([]string, len(lines))
+	var open []string
+	for i, line := range lines {
+		top := ""
+		if len(open) > 0 {
+			top = open[len(open)-1]
+		}
+		inCode := scoreContainers[top] == "code"
+		if m := scoreMacroRe.FindStringSubmatch(line); m != nil && (!inCode || m[1] == top) {
+			kinds[i] = scoreContainers[m[1]]
+			switch {
+			case m[1] == top && line == "{"+top+"}":
+				open = open[:len(open)-1]
+			case !strings.HasSuffix(line, "{"+m[1]+"}") || line == "{"+m[1]+"}":
+				open = append(open, m[1])
+			}
+			continue
+		}
+		if inCode && strings.HasSuffix(line, "{"+top+"}") {
+			// {code} closing after the last line of code
+			kinds[i] = "code"
+			open = open[:len(open)-1]
+			continue
+		}
+
+		container := ""
+		for k := len(open) - 1; k >= 0 && container == ""; k-- {
+			if kind := scoreContainers[open[k]]; kind != "expand" {
+				container = kind
+			}
+		}
+		switch {
+		case container != "":
+			kinds[i] = container
+		case scoreHeadingRe.MatchString(line):
+			kinds[i] = "heading"
+		case strings.HasPrefix(line, "|"):
+			kinds[i] = "table"
+		case scoreListRe.MatchString(line):
+			kinds[i] = "list"
+		case strings.HasPrefix(line, "bq. "):
+			kinds[i] = "quote"
+		case line == "----":
+			kinds[i] = "rule"
+		case scoreImageRe.MatchString(line):
+			kinds[i] = "image"
+		default:
+			kinds[i] = "text"
+		}
+	}
+	return kinds
+}
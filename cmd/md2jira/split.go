@@ -0,0 +1,276 @@
+// md2jira split converts the documents of a bundle, routing each to a file or
+// an issue by its front matter
+
+package main
+
+import (
+	"cmp"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/astsu-dev/md2jira/converter"
+	"github.com/astsu-dev/md2jira/jira"
+)
+
+// bundleDocument is a document of a bundle and where it goes
+type bundleDocument struct {
+	converter.Document
+	// name identifies the document in messages, e.g. bundle.md:12
+	name string
+	// line is the number of bundle lines before the document
+	line int
+	// output is the file the document is written to, if any
+	output string
+	// push is set for documents published to an issue
+	push   bool
+	fields pushFields
+	result converter.Result
+}
+
+// runSplit runs the split subcommand and returns the exit code
+func runSplit(args []string) int {
+	fs := flag.NewFlagSet("split", flag.ContinueOnError)
+	outDir := fs.String("out-dir", "", "Directory output paths are relative to (default: the bundle's directory)")
+	project := fs.String("project", "", "Project of the issues of documents that name no output, key or project")
+	issueType := fs.String("issue-type", "Task", "Issue type of a new issue when the front matter names none")
+	noWrite := fs.Bool("no-write-key", false, "Do not add the keys of created issues to the bundle")
+	attach := fs.Bool("attach-images", false, "Attach local images to the issues and reference them by name")
+	stripTitle := fs.Bool("strip-title", false, "Remove the first H1 of each document and use it as the summary of its issue")
+	configFile := fs.String("config", "", "Configuration file")
+	remote := addRemoteFlags(fs)
+	dryRun := fs.Bool("dry-run", false, "Print where each document would go without writing or contacting JIRA")
+	force := fs.Bool("force", false, "Overwrite descriptions that were edited in JIRA since the last push")
+	fs.Usage = func() {
+		fmt.Fprintf(os.Stderr, `Usage:
+  md2jira split [options] bundle.md
+
+Converts each document of a bundle: a file of several documents that each
+start with their own front matter, as generators emit them. A document whose
+front matter names an output file is written to it; one naming a key or a
+project is pushed to its issue as md2jira push would, and the keys of created
+issues are added to the bundle. A document may name both.
+
+    ---
+    output: api/overview.jira
+    ---
+    # API overview
+    ---
+    project: PROJ
+    summary: Document the export endpoint
+    ---
+    ...
+
+Options:
+  --out-dir string
+                Directory output paths are relative to (default: the
+                directory of the bundle)
+  --project string
+                Project of the issues of documents that name no output, key
+                or project
+  --issue-type string
+                Issue type of a new issue when the front matter names none
+                (default: Task)
+  --no-write-key
+                Do not add the keys of created issues to the bundle
+  --attach-images
+                Upload the local images of pushed documents as attachments of
+                their issues and render them as !name.png!
+  --strip-title Remove the first H1 of each document and use its text as the
+                summary of its issue, unless the front matter sets one
+  --dry-run     Print where each document would go and its conversion,
+                without writing files or contacting JIRA
+  --force       Overwrite descriptions that were edited in JIRA since the
+                last push
+`+remoteUsage+`  --config string
+                Read defaults from this file instead of
+                .md2jira.yaml/.md2jira.toml
+`)
+	}
+	files, err := parseInterspersed(fs, args)
+	if err != nil {
+		if err == flag.ErrHelp {
+			return exitOK
+		}
+		return exitUsage
+	}
+	if len(files) != 1 {
+		fs.Usage()
+		return exitUsage
+	}
+	cfg, err := loadFlagConfig(fs, *configFile)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error reading config: %v\n", err)
+		return exitUsage
+	}
+
+	path := files[0]
+	source, err := readSource(path)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error reading input: %v\n", err)
+		return exitIO
+	}
+	bundle := string(source)
+	dir := cmp.Or(*outDir, filepath.Dir(path))
+	var docs []*bundleDocument
+	for i, doc := range converter.SplitDocuments(bundle) {
+		d, err := routeDocument(doc, bundle, path, i+1, dir, *project)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %s: %v\n", d.name, err)
+			return exitUsage
+		}
+		d.fields.issueType = cmp.Or(d.fields.issueType, *issueType)
+		docs = append(docs, d)
+	}
+	if len(docs) == 0 {
+		fmt.Fprintf(os.Stderr, "Error: %s holds no documents\n", path)
+		return exitUsage
+	}
+
+	// Every document is converted before anything is written, so that an
+	// error does not leave the bundle half published
+	for _, d := range docs {
+		opts := documentOptions(path, cfg, *attach && d.push, *stripTitle)
+		opts.ProvenanceTrailer = d.push
+		d.result, err = converter.ConvertWithOptions(d.Source, opts)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error converting %s: %v\n", d.name, err)
+			return exitConversion
+		}
+		if title, ok := d.result.Metadata["title"].(string); ok && d.fields.summary == "" {
+			d.fields.summary = strings.TrimSpace(title)
+		}
+		for _, w := range d.result.Warnings {
+			if w.Line != 0 {
+				w.Line += d.line
+			}
+			fmt.Fprintln(os.Stderr, formatWarning(path, w))
+		}
+	}
+
+	if *dryRun {
+		for _, d := range docs {
+			printRoute(d)
+			fmt.Println(d.result.Output)
+		}
+		return exitOK
+	}
+
+	var client *jira.Client
+	created := make(map[*bundleDocument]string)
+	for _, d := range docs {
+		if d.output != "" {
+			if err := os.MkdirAll(filepath.Dir(d.output), 0755); err != nil {
+				fmt.Fprintf(os.Stderr, "Error creating output directory: %v\n", err)
+				return exitIO
+			}
+			if err := os.WriteFile(d.output, []byte(d.result.Output), 0644); err != nil {
+				fmt.Fprintf(os.Stderr, "Error writing output file: %v\n", err)
+				return exitIO
+			}
+			fmt.Fprintf(os.Stderr, "Wrote %s\n", d.output)
+		}
+		if !d.push {
+			continue
+		}
+		if client == nil {
+			if client, err = remote.jiraClient(); err != nil {
+				fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+				return exitUsage
+			}
+		}
+		key, isNew, err := pushIssue(client, d.fields, d.result.Output, *force)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %s: %v\n", d.name, err)
+			return exitIO
+		}
+		if err := uploadAttachments(client, key, d.result.Attachments); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			return exitIO
+		}
+		if isNew {
+			created[d] = key
+		}
+	}
+	if len(created) == 0 || *noWrite {
+		return exitOK
+	}
+	if err := os.WriteFile(path, []byte(setBundleKeys(bundle, docs, created)), 0o644); err != nil {
+		fmt.Fprintf(os.Stderr, "Error writing keys: %v\n", err)
+		return exitIO
+	}
+	return exitOK
+}
+
+// routeDocument reads where the nth document of a bundle goes from its front
+// matter. Documents naming neither an output file nor an issue are pushed to
+// an issue in the fallback project, if there is one.
+func routeDocument(doc converter.Document, bundle, path string, n int, dir, project string) (*bundleDocument, error) {
+	line := strings.Count(bundle[:doc.Offset], "\n")
+	d := &bundleDocument{Document: doc, name: fmt.Sprintf("%s:%d", path, line+1), line: line}
+	switch output := doc.Metadata["output"].(type) {
+	case nil:
+	case string:
+		if output = strings.TrimSpace(output); output == "" {
+			return d, fmt.Errorf("front matter field output is empty")
+		}
+		d.output = output
+		if !filepath.IsAbs(output) {
+			d.output = filepath.Join(dir, filepath.FromSlash(output))
+		}
+	default:
+		return d, fmt.Errorf("front matter field output must be a string")
+	}
+
+	fields, err := frontMatterFields(doc.Metadata, path)
+	if err != nil {
+		return d, err
+	}
+	// Created issues without a summary or title are named after the bundle
+	// and the position of the document in it
+	fields.name = fmt.Sprintf("%s %d", fields.name, n)
+	if fields.key == "" && fields.project == "" && d.output == "" {
+		fields.project = project
+	}
+	d.fields = fields
+	d.push = fields.key != "" || fields.project != ""
+	if !d.push && d.output == "" {
+		return d, fmt.Errorf("document names no output, key or project; set one in its front matter or use --project")
+	}
+	return d, nil
+}
+
+// printRoute reports where a dry run would send a document
+func printRoute(d *bundleDocument) {
+	if d.output != "" {
+		fmt.Fprintf(os.Stderr, "%s: would write %s\n", d.name, d.output)
+	}
+	switch {
+	case !d.push:
+	case d.fields.key != "":
+		fmt.Fprintf(os.Stderr, "%s: would update %s\n", d.name, d.fields.key)
+	default:
+		fmt.Fprintf(os.Stderr, "%s: would create %s in %s: %s\n", d.name, d.fields.issueType, d.fields.project, cmp.Or(d.fields.summary, d.fields.name))
+	}
+	printAttachments(d.result.Attachments)
+}
+
+// setBundleKeys adds the keys of created issues to the front matter of their
+// documents, leaving the rest of the bundle as it was
+func setBundleKeys(bundle string, docs []*bundleDocument, created map[*bundleDocument]string) string {
+	var b strings.Builder
+	last := 0
+	for _, d := range docs {
+		key, ok := created[d]
+		if !ok {
+			continue
+		}
+		b.WriteString(bundle[last:d.Offset])
+		b.WriteString(setFrontMatterKey(d.Source, key, d.Metadata != nil))
+		last = d.Offset + len(d.Source)
+	}
+	b.WriteString(bundle[last:])
+	return b.String()
+}
@@ -0,0 +1,38 @@
+// Conversion statistics printed with --stats
+
+package main
+
+import (
+	"fmt"
+	"io"
+	"maps"
+	"slices"
+	"strings"
+
+	"github.com/astsu-dev/md2jira/converter"
+)
+
+// printStats reports the constructs of a conversion, and the node kinds that
+// were rendered only through their children
+func printStats(w io.Writer, name string, s converter.Stats) {
+	fmt.Fprintf(w, "%s: %d lines, %d chars in, %d chars out\n", name, s.InputLines, s.InputChars, s.OutputChars)
+	fmt.Fprintf(w, "  %d headings, %d paragraphs, %d lists, %d code blocks, %d tables, %d links, %d images, %d HTML blocks\n",
+		s.Headings, s.Paragraphs, s.Lists, s.CodeBlocks, s.Tables, s.Links, s.Images, s.HTMLBlocks)
+	if len(s.Warnings) > 0 {
+		fmt.Fprintf(w, "  warnings: %s\n", counts(s.Warnings))
+	}
+	if len(s.UnknownNodes) > 0 {
+		fmt.Fprintf(w, "  unknown nodes: %s\n", counts(s.UnknownNodes))
+	}
+}
+
+// counts formats counts by name as "name 3, other 1", most frequent first
+func counts(m map[string]int) string {
+	names := slices.Sorted(maps.Keys(m))
+	slices.SortStableFunc(names, func(a, b string) int { return m[b] - m[a] })
+	parts := make([]string, len(names))
+	for i, name := range names {
+		parts[i] = fmt.Sprintf("%s %d", name, m[name])
+	}
+	return strings.Join(parts, ", ")
+}
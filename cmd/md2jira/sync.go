@@ -0,0 +1,490 @@
+// md2jira sync keeps documents and their issues in sync on a schedule
+
+package main
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"math/rand/v2"
+	"net/http"
+	"os"
+	"os/exec"
+	"os/signal"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/BurntSushi/toml"
+	"github.com/astsu-dev/md2jira/jira"
+	"gopkg.in/yaml.v3"
+)
+
+// syncJob is a document pushed to its issue on every run, as md2jira push
+// would; the job's fields take precedence over the front matter
+type syncJob struct {
+	Name      string `yaml:"name" toml:"name"`
+	Source    string `yaml:"source" toml:"source"`
+	Issue     string `yaml:"issue" toml:"issue"`
+	Project   string `yaml:"project" toml:"project"`
+	IssueType string `yaml:"issue-type" toml:"issue-type"`
+	// Interval overrides --interval for this job
+	Interval string `yaml:"interval" toml:"interval"`
+
+	interval time.Duration
+}
+
+// syncState is what is remembered about a job between runs
+type syncState struct {
+	// Key is the issue the job syncs to, once known
+	Key string `json:"key,omitempty"`
+	// Source is the job's document, relative to the state file, and URL the
+	// issue, so other documents can link to it (--link-state)
+	Source string `json:"source,omitempty"`
+	URL    string `json:"url,omitempty"`
+	// Hash identifies the last pushed fields and description
+	Hash      string    `json:"hash,omitempty"`
+	LastRun   time.Time `json:"last_run"`
+	LastSync  time.Time `json:"last_sync"`
+	Failures  int       `json:"failures"`
+	LastError string    `json:"last_error,omitempty"`
+}
+
+// syncAlert reports jobs that keep failing, and their recovery
+type syncAlert struct {
+	command []string
+	webhook string
+	after   int
+	client  *http.Client
+}
+
+// syncRunner runs the jobs of a sync configuration
+type syncRunner struct {
+	client    *jira.Client
+	cfg       *config
+	dir       string
+	statePath string
+	states    map[string]*syncState
+	alert     syncAlert
+	// force overwrites descriptions edited in JIRA
+	force bool
+}
+
+// runSync runs the sync subcommand and returns the exit code
+func runSync(args []string) int {
+	fs := flag.NewFlagSet("sync", flag.ContinueOnError)
+	configFile := fs.String("config", "", "Sync configuration file")
+	remote := addRemoteFlags(fs)
+	interval := fs.Duration("interval", 15*time.Minute, "Time between runs of a job")
+	jitter := fs.Duration("jitter", 0, "Random delay added to each interval")
+	statePath := fs.String("state", "", "File the state of the jobs is kept in")
+	alertCmd := fs.String("alert-cmd", "", "Command run when a job keeps failing or recovers")
+	alertWebhook := fs.String("alert-webhook", "", "URL a JSON alert is posted to when a job keeps failing or recovers")
+	alertAfter := fs.Int("alert-after", 1, "Consecutive failures of a job before it is alerted")
+	once := fs.Bool("once", false, "Run every job once and exit")
+	force := fs.Bool("force", false, "Overwrite descriptions that were edited in JIRA since the last push")
+	fs.Usage = func() {
+		fmt.Fprintf(os.Stderr, `Usage:
+  md2jira sync --config sync.yaml [options]
+
+Runs as a long-lived process that pushes documents to their issues on a
+schedule, as md2jira push does. Each job names a source document and
+optionally the issue, project and issue type, which take precedence over its
+front matter; a job whose document has no key remembers the issue it created.
+Documents that have not changed since their last push are skipped, and a
+job whose issue description was edited in JIRA since fails until --force.
+--interval, --jitter and --alert-after can also be set in the configuration
+file. JIRA_URL, JIRA_USER and JIRA_TOKEN configure the connection.
+
+    interval: 15m
+    jobs:
+      - name: runbook
+        source: docs/runbook.md
+        issue: OPS-12
+      - source: docs/csv-export.md
+        project: PROJ
+        interval: 1h
+
+Options:
+  --config string
+                Sync configuration file, which also holds the languages,
+                header and footer (default: .md2jira.yaml/.md2jira.toml)
+  --interval duration
+                Time between runs of a job (default: 15m)
+  --jitter duration
+                Random delay added to each interval, so jobs do not run in
+                lockstep (default: a tenth of the interval)
+  --state string
+                File the state of the jobs is kept in
+                (default: sync.state.json next to the configuration file)
+  --alert-cmd string
+                Command run when a job keeps failing or recovers, with
+                MD2JIRA_JOB, MD2JIRA_SOURCE, MD2JIRA_STATUS (failing or
+                recovered), MD2JIRA_FAILURES and MD2JIRA_ERROR set; only
+                taken from the command line
+  --alert-webhook string
+                URL a JSON alert with the same fields is posted to; only
+                taken from the command line
+  --alert-after int
+                Consecutive failures of a job before it is alerted (default: 1)
+`+remoteUsage+`  --once        Run every job once and exit, failing if a job fails
+  --force       Overwrite descriptions that were edited in JIRA since the
+                last push; only taken from the command line
+`)
+	}
+	if _, err := parseInterspersed(fs, args); err != nil {
+		if err == flag.ErrHelp {
+			return exitOK
+		}
+		return exitUsage
+	}
+	cfg, err := loadFlagConfig(fs, *configFile)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error reading config: %v\n", err)
+		return exitUsage
+	}
+	if cfg.path == "" {
+		fs.Usage()
+		return exitUsage
+	}
+	jobs, err := loadSyncJobs(cfg.path, *interval)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error reading config: %v\n", err)
+		return exitUsage
+	}
+	if *interval <= 0 || *jitter < 0 || *alertAfter < 1 {
+		fmt.Fprintln(os.Stderr, "Error: --interval and --alert-after must be positive, and --jitter not negative")
+		return exitUsage
+	}
+	if *statePath == "" {
+		*statePath = filepath.Join(filepath.Dir(cfg.path), strings.TrimSuffix(filepath.Base(cfg.path), filepath.Ext(cfg.path))+".state.json")
+	}
+
+	client, err := remote.jiraClient()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		return exitUsage
+	}
+	r := &syncRunner{
+		client:    client,
+		cfg:       cfg,
+		dir:       filepath.Dir(cfg.path),
+		statePath: *statePath,
+		force:     *force,
+		alert:     syncAlert{command: strings.Fields(*alertCmd), webhook: *alertWebhook, after: *alertAfter, client: client.HTTPClient},
+	}
+	if r.states, err = loadSyncState(*statePath); err != nil {
+		fmt.Fprintf(os.Stderr, "Error reading state: %v\n", err)
+		return exitIO
+	}
+
+	if *once {
+		code := exitOK
+		for i := range jobs {
+			if !r.run(&jobs[i]) {
+				code = exitIO
+			}
+		}
+		return code
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	fmt.Fprintf(os.Stderr, "Syncing %d job(s)\n", len(jobs))
+	next := make([]time.Time, len(jobs))
+	for {
+		now := time.Now()
+		earliest := time.Time{}
+		for i := range jobs {
+			if !next[i].After(now) {
+				r.run(&jobs[i])
+				next[i] = time.Now().Add(jobs[i].interval + syncJitter(*jitter, jobs[i].interval))
+			}
+			if earliest.IsZero() || next[i].Before(earliest) {
+				earliest = next[i]
+			}
+		}
+		select {
+		case <-ctx.Done():
+			fmt.Fprintln(os.Stderr, "Stopping sync")
+			return exitOK
+		case <-time.After(time.Until(earliest)):
+		}
+	}
+}
+
+// syncJitter returns a random delay below jitter, or below a tenth of the
+// interval when jitter is not set
+func syncJitter(jitter, interval time.Duration) time.Duration {
+	if jitter == 0 {
+		jitter = interval / 10
+	}
+	if jitter <= 0 {
+		return 0
+	}
+	return rand.N(jitter)
+}
+
+// loadSyncJobs reads the jobs of a YAML or TOML sync configuration
+func loadSyncJobs(path string, interval time.Duration) ([]syncJob, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var file struct {
+		Jobs []syncJob `yaml:"jobs" toml:"jobs"`
+	}
+	if strings.EqualFold(filepath.Ext(path), ".toml") {
+		err = toml.Unmarshal(data, &file)
+	} else {
+		err = yaml.Unmarshal(data, &file)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("%s: %v", path, err)
+	}
+	if len(file.Jobs) == 0 {
+		return nil, fmt.Errorf("%s: no jobs configured", path)
+	}
+	names := make(map[string]bool, len(file.Jobs))
+	for i := range file.Jobs {
+		job := &file.Jobs[i]
+		if job.Source == "" {
+			return nil, fmt.Errorf("%s: job %d has no source", path, i+1)
+		}
+		if job.Name == "" {
+			job.Name = job.Source
+		}
+		// The state is kept by name
+		if names[job.Name] {
+			return nil, fmt.Errorf("%s: job name %q is used twice", path, job.Name)
+		}
+		names[job.Name] = true
+		job.interval = interval
+		if job.Interval != "" {
+			if job.interval, err = time.ParseDuration(job.Interval); err != nil || job.interval <= 0 {
+				return nil, fmt.Errorf("%s: job %s: interval %q is not a positive duration", path, job.Name, job.Interval)
+			}
+		}
+	}
+	return file.Jobs, nil
+}
+
+// loadSyncState reads the job states, which are empty before the first run
+func loadSyncState(path string) (map[string]*syncState, error) {
+	states := make(map[string]*syncState)
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return states, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(data, &states); err != nil {
+		return nil, fmt.Errorf("%s: %v", path, err)
+	}
+	return states, nil
+}
+
+// statePath returns the path of a document relative to the directory of the
+// state file, or its absolute path if it has none
+func statePath(stateFile, path string) string {
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		return path
+	}
+	dir, err := filepath.Abs(filepath.Dir(stateFile))
+	if err != nil {
+		return abs
+	}
+	rel, err := filepath.Rel(dir, abs)
+	if err != nil {
+		return abs
+	}
+	return filepath.ToSlash(rel)
+}
+
+// documentLinks maps the absolute paths of the documents of the job states
+// to the URLs of their issues, resolving them against dir, the directory of
+// the state file
+func documentLinks(states map[string]*syncState, dir string) map[string]string {
+	links := make(map[string]string)
+	for _, state := range states {
+		if state.Source == "" || state.URL == "" {
+			continue
+		}
+		path := filepath.FromSlash(state.Source)
+		if !filepath.IsAbs(path) {
+			path = filepath.Join(dir, path)
+		}
+		if abs, err := filepath.Abs(path); err == nil {
+			links[abs] = state.URL
+		}
+	}
+	return links
+}
+
+// loadDocumentLinks reads the document links of a sync state file
+func loadDocumentLinks(path string) (map[string]string, error) {
+	if _, err := os.Stat(path); err != nil {
+		return nil, err
+	}
+	states, err := loadSyncState(path)
+	if err != nil {
+		return nil, err
+	}
+	return documentLinks(states, filepath.Dir(path)), nil
+}
+
+// saveState writes the job states, replacing the file atomically so an
+// interrupted write does not lose them
+func (r *syncRunner) saveState() error {
+	data, err := json.MarshalIndent(r.states, "", "  ")
+	if err != nil {
+		return err
+	}
+	tmp := r.statePath + ".tmp"
+	if err := os.WriteFile(tmp, append(data, '\n'), 0o644); err != nil {
+		return err
+	}
+	return os.Rename(tmp, r.statePath)
+}
+
+// run runs a job once, records its state and reports whether it succeeded
+func (r *syncRunner) run(job *syncJob) bool {
+	state := r.states[job.Name]
+	if state == nil {
+		state = &syncState{}
+		r.states[job.Name] = state
+	}
+	state.LastRun = time.Now()
+	err := r.push(job, state)
+	if err != nil {
+		state.Failures++
+		state.LastError = err.Error()
+		fmt.Fprintf(os.Stderr, "[%s] %s: %v\n", time.Now().Format("15:04:05"), job.Name, err)
+		if state.Failures == r.alert.after {
+			r.alert.send(job, state, "failing")
+		}
+	} else {
+		if state.Failures >= r.alert.after {
+			r.alert.send(job, state, "recovered")
+		}
+		state.Failures = 0
+		state.LastError = ""
+	}
+	if err := r.saveState(); err != nil {
+		fmt.Fprintf(os.Stderr, "Error writing state: %v\n", err)
+	}
+	return err == nil
+}
+
+// push converts a job's document and pushes it to its issue, unless it has
+// not changed since the last push
+func (r *syncRunner) push(job *syncJob, state *syncState) error {
+	path := job.Source
+	if !filepath.IsAbs(path) {
+		path = filepath.Join(r.dir, path)
+	}
+	source, err := readSource(path)
+	if err != nil {
+		return err
+	}
+	opts := documentOptions(path, r.cfg, false, false)
+	opts.ProvenanceTrailer = true
+	opts.DocumentLinks = documentLinks(r.states, filepath.Dir(r.statePath))
+	result, err := convertDocument(path, source, opts)
+	if err != nil {
+		return fmt.Errorf("converting: %v", err)
+	}
+	fields, err := frontMatterFields(result.Metadata, path)
+	if err != nil {
+		return err
+	}
+	if job.Issue != "" {
+		fields.key = job.Issue
+	}
+	if job.Project != "" {
+		fields.project = job.Project
+	}
+	if job.IssueType != "" {
+		fields.issueType = job.IssueType
+	}
+	if fields.issueType == "" {
+		fields.issueType = "Task"
+	}
+	if fields.key == "" {
+		fields.key = state.Key
+	}
+	state.Source = statePath(r.statePath, path)
+	if fields.key == "" && fields.project == "" {
+		return fmt.Errorf("%s names no issue or project", job.Source)
+	}
+
+	if fields.key != "" && syncHash(fields, result.Output) == state.Hash {
+		state.URL = r.client.BaseURL + "/browse/" + fields.key
+		return nil
+	}
+	fmt.Fprintf(os.Stderr, "[%s] %s: pushing %s\n", time.Now().Format("15:04:05"), job.Name, job.Source)
+	key, _, err := pushIssue(r.client, fields, result.Output, r.force)
+	if err != nil {
+		return err
+	}
+	fields.key = key
+	state.Key = key
+	state.URL = r.client.BaseURL + "/browse/" + key
+	state.Hash = syncHash(fields, result.Output)
+	state.LastSync = time.Now()
+	return nil
+}
+
+// syncHash identifies the fields and description pushed to an issue
+func syncHash(fields pushFields, description string) string {
+	sum := sha256.Sum256([]byte(fmt.Sprintf("%s\x00%s\x00%q\x00%s", fields.key, fields.summary, fields.labels, description)))
+	return hex.EncodeToString(sum[:])
+}
+
+// send runs the alert command and posts to the alert webhook; failures to
+// alert are reported but do not stop the runner
+func (a syncAlert) send(job *syncJob, state *syncState, status string) {
+	fmt.Fprintf(os.Stderr, "[%s] %s: %s after %d failure(s)\n", time.Now().Format("15:04:05"), job.Name, status, state.Failures)
+	if len(a.command) > 0 {
+		cmd := exec.Command(a.command[0], a.command[1:]...)
+		cmd.Env = append(os.Environ(),
+			"MD2JIRA_JOB="+job.Name,
+			"MD2JIRA_SOURCE="+job.Source,
+			"MD2JIRA_STATUS="+status,
+			"MD2JIRA_FAILURES="+strconv.Itoa(state.Failures),
+			"MD2JIRA_ERROR="+state.LastError,
+		)
+		cmd.Stdout = os.Stderr
+		cmd.Stderr = os.Stderr
+		if err := cmd.Run(); err != nil {
+			fmt.Fprintf(os.Stderr, "Error running alert command: %v\n", err)
+		}
+	}
+	if a.webhook != "" {
+		body, _ := json.Marshal(map[string]interface{}{
+			"job":      job.Name,
+			"source":   job.Source,
+			"issue":    state.Key,
+			"status":   status,
+			"failures": state.Failures,
+			"error":    state.LastError,
+		})
+		resp, err := a.client.Post(a.webhook, "application/json", bytes.NewReader(body))
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error posting alert: %v\n", err)
+			return
+		}
+		resp.Body.Close()
+		if resp.StatusCode < 200 || resp.StatusCode > 299 {
+			fmt.Fprintf(os.Stderr, "Error posting alert: %s\n", resp.Status)
+		}
+	}
+}
@@ -0,0 +1,76 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestDocumentLinksFromState(t *testing.T) {
+	dir := t.TempDir()
+	stateFile := filepath.Join(dir, "sync.state.json")
+	design := filepath.Join(dir, "docs", "design.md")
+	if got := statePath(stateFile, design); got != "docs/design.md" {
+		t.Errorf("statePath = %q, want docs/design.md", got)
+	}
+
+	data := `{
+  "design": {"key": "PROJ-7", "source": "docs/design.md", "url": "https://jira.example.com/browse/PROJ-7"},
+  "old": {"key": "PROJ-8"}
+}`
+	if err := os.WriteFile(stateFile, []byte(data), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	links, err := loadDocumentLinks(stateFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(links) != 1 || links[design] != "https://jira.example.com/browse/PROJ-7" {
+		t.Errorf("links = %v, want %s mapped to PROJ-7", links, design)
+	}
+	if _, err := loadDocumentLinks(filepath.Join(dir, "missing.json")); err == nil {
+		t.Error("loadDocumentLinks of a missing file succeeded")
+	}
+}
+
+func TestSyncPushesAndKeepsEditedDescriptions(t *testing.T) {
+	srv := newFakeJIRA(t)
+	dir := t.TempDir()
+	doc := filepath.Join(dir, "runbook.md")
+	writeTestFile(t, doc, "# Runbook\n\nRestart it.\n")
+	config := filepath.Join(dir, "sync.yaml")
+	writeTestFile(t, config, "jobs:\n  - name: runbook\n    source: runbook.md\n    project: OPS\n")
+	sync := func(args ...string) int {
+		return runSync(append([]string{"--config", config, "--once"}, args...))
+	}
+
+	if code := sync(); code != exitOK {
+		t.Fatalf("first sync exited with %d", code)
+	}
+	if got := srv.descriptionBody("PROJ-1"); got != "h1. Runbook\n\nRestart it." {
+		t.Fatalf("description = %q", got)
+	}
+	if code := sync(); code != exitOK || srv.updates("PROJ-1") != 0 {
+		t.Fatalf("unchanged document: exit %d, %d updates", code, srv.updates("PROJ-1"))
+	}
+
+	writeTestFile(t, doc, "# Runbook\n\nRestart it twice.\n")
+	edited := strings.Replace(srv.issue("PROJ-1")["description"].(string), "Restart it.", "Call Sam first.", 1)
+	srv.setDescription("PROJ-1", edited)
+	if code := sync(); code != exitIO {
+		t.Fatalf("sync over an edited description exited with %d, want %d", code, exitIO)
+	}
+	if got := srv.issue("PROJ-1")["description"]; got != edited {
+		t.Errorf("edited description overwritten with %q", got)
+	}
+	if code := sync("--force"); code != exitOK {
+		t.Fatalf("sync --force exited with %d", code)
+	}
+	if got := srv.descriptionBody("PROJ-1"); got != "h1. Runbook\n\nRestart it twice." {
+		t.Errorf("description after --force = %q", got)
+	}
+	if n := srv.created(); n != 1 {
+		t.Errorf("%d issues created, want 1", n)
+	}
+}
@@ -0,0 +1,439 @@
+// md2jira template manages a directory of parameterized Markdown issue templates
+
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"text/template"
+
+	"github.com/astsu-dev/md2jira/converter"
+	"gopkg.in/yaml.v3"
+)
+
+// defaultTemplateDir is where issue templates are kept unless --template-dir is set
+const defaultTemplateDir = ".md2jira/templates"
+
+// issueTemplate is a Markdown issue template: YAML front matter describing the
+// issue and its variables, followed by a Go template of the description
+type issueTemplate struct {
+	Description string             `yaml:"description"`
+	Summary     string             `yaml:"summary"`
+	IssueType   string             `yaml:"issue-type"`
+	Variables   []templateVariable `yaml:"variables"`
+	// body is the Markdown template after the front matter
+	body string
+}
+
+// templateVariable is a value prompted for when a template is rendered
+type templateVariable struct {
+	Name    string `yaml:"name"`
+	Prompt  string `yaml:"prompt"`
+	Default string `yaml:"default"`
+}
+
+// builtinTemplates are the templates md2jira template new starts from
+var builtinTemplates = map[string]string{
+	"bug": `---
+description: Bug report
+summary: "{{.title}}"
+issue-type: Bug
+variables:
+  - name: title
+    prompt: One-line summary
+  - name: version
+    prompt: Affected version
+  - name: steps
+    prompt: Steps to reproduce
+  - name: expected
+    prompt: Expected behavior
+  - name: actual
+    prompt: Actual behavior
+---
+**Version:** {{.version}}
+
+## Steps to reproduce
+
+{{.steps}}
+
+## Expected
+
+{{.expected}}
+
+## Actual
+
+{{.actual}}
+`,
+	"incident": `---
+description: Incident report
+summary: "Incident: {{.title}}"
+issue-type: Task
+variables:
+  - name: title
+    prompt: What broke
+  - name: severity
+    prompt: Severity (SEV1-SEV4)
+    default: SEV3
+  - name: impact
+    prompt: Customer impact
+  - name: commander
+    prompt: Incident commander
+---
+**Severity:** {{.severity}} | **Commander:** {{.commander}}
+
+## Impact
+
+{{.impact}}
+
+## Timeline
+
+- HH:MM UTC — Incident declared
+
+## Action items
+
+- [ ] Write the postmortem
+`,
+	"rfc": `---
+description: Request for comments
+summary: "RFC: {{.title}}"
+issue-type: Story
+variables:
+  - name: title
+    prompt: Proposal title
+  - name: author
+    prompt: Author
+  - name: problem
+    prompt: Problem statement
+---
+**Author:** {{.author}} | **Status:** Draft
+
+## Problem
+
+{{.problem}}
+
+## Proposal
+
+## Alternatives considered
+
+## Open questions
+`,
+}
+
+// runTemplate runs the template subcommand and returns the exit code
+func runTemplate(args []string) int {
+	usage := func() {
+		fmt.Fprintf(os.Stderr, `Usage:
+  md2jira template list [options]
+  md2jira template new [options] name
+  md2jira template render [options] name
+
+Manages a directory of Markdown issue templates (default: %s).
+A template starts with YAML front matter giving its description, the issue
+summary and type, and the variables it uses; the rest is the description as
+a Go template ({{.name}}). new creates a template from a built-in bug,
+incident or rfc skeleton; render prompts for the variables not given with
+--set and prints the JIRA markup, or creates the issue with --push.
+
+Options:
+  --template-dir string
+                Directory of templates (default: %s)
+  --from string
+                Built-in skeleton for new: bug, incident or rfc (default: the
+                name, if it is one)
+  --force       Let new overwrite an existing template
+  --set name=value
+                Set a variable instead of prompting (repeatable)
+  --push        Create the rendered issue in --project
+  --project string
+                Project key for --push
+  --issue-type string
+                Issue type for --push (default: the template's, or Task)
+`+remoteUsage+`  --config string
+                Read rendering defaults from this file instead of
+                .md2jira.yaml/.md2jira.toml
+`, defaultTemplateDir, defaultTemplateDir)
+	}
+	if len(args) == 0 || args[0] == "-h" || args[0] == "--help" {
+		usage()
+		if len(args) == 0 {
+			return exitUsage
+		}
+		return exitOK
+	}
+	command := args[0]
+
+	fs := flag.NewFlagSet("template "+command, flag.ContinueOnError)
+	dir := fs.String("template-dir", defaultTemplateDir, "Directory of templates")
+	from := fs.String("from", "", "Built-in skeleton for new")
+	force := fs.Bool("force", false, "Overwrite an existing template")
+	var sets templateValues
+	fs.Var(&sets, "set", "Set a variable (name=value)")
+	push := fs.Bool("push", false, "Create the rendered issue")
+	project := fs.String("project", "", "Project key for --push")
+	issueType := fs.String("issue-type", "", "Issue type for --push")
+	configFile := fs.String("config", "", "Configuration file")
+	remote := addRemoteFlags(fs)
+	fs.Usage = usage
+	names, err := parseInterspersed(fs, args[1:])
+	if err != nil {
+		if err == flag.ErrHelp {
+			return exitOK
+		}
+		return exitUsage
+	}
+	cfg, err := loadFlagConfig(fs, *configFile)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error reading config: %v\n", err)
+		return exitUsage
+	}
+
+	switch {
+	case command == "list" && len(names) == 0:
+		return listTemplates(*dir)
+	case command == "new" && len(names) == 1:
+		return newTemplate(*dir, names[0], *from, *force)
+	case command == "render" && len(names) == 1:
+		if *push && *project == "" {
+			fmt.Fprintln(os.Stderr, "Error: --push requires --project")
+			return exitUsage
+		}
+		return renderTemplate(*dir, names[0], sets, cfg, remote, *push, *project, *issueType)
+	}
+	usage()
+	return exitUsage
+}
+
+// templateValues collects --set name=value flags
+type templateValues map[string]string
+
+// String implements flag.Value
+func (v *templateValues) String() string {
+	return fmt.Sprint(map[string]string(*v))
+}
+
+// Set implements flag.Value
+func (v *templateValues) Set(value string) error {
+	name, val, ok := strings.Cut(value, "=")
+	if !ok || name == "" {
+		return fmt.Errorf("%q is not name=value", value)
+	}
+	if *v == nil {
+		*v = make(templateValues)
+	}
+	(*v)[name] = val
+	return nil
+}
+
+// loadIssueTemplate reads a template and its front matter
+func loadIssueTemplate(path string) (*issueTemplate, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	return parseIssueTemplate(string(data))
+}
+
+// parseIssueTemplate splits a template into its front matter and body
+func parseIssueTemplate(data string) (*issueTemplate, error) {
+	t := &issueTemplate{body: data}
+	data = strings.ReplaceAll(data, "\r\n", "\n")
+	if !strings.HasPrefix(data, "---\n") {
+		return t, nil
+	}
+	end := strings.Index(data[4:], "\n---\n")
+	if end < 0 {
+		return nil, errors.New("front matter is not closed with ---")
+	}
+	if err := yaml.Unmarshal([]byte(data[4:4+end]), t); err != nil {
+		return nil, fmt.Errorf("front matter: %v", err)
+	}
+	t.body = data[4+end+5:]
+	return t, nil
+}
+
+// templateNames returns the names of the templates in dir
+func templateNames(dir string) ([]string, error) {
+	paths, err := filepath.Glob(filepath.Join(dir, "*.md"))
+	if err != nil {
+		return nil, err
+	}
+	names := make([]string, len(paths))
+	for i, path := range paths {
+		names[i] = strings.TrimSuffix(filepath.Base(path), ".md")
+	}
+	sort.Strings(names)
+	return names, nil
+}
+
+// listTemplates prints the templates in dir with their descriptions
+func listTemplates(dir string) int {
+	names, err := templateNames(dir)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		return exitIO
+	}
+	if len(names) == 0 {
+		fmt.Fprintf(os.Stderr, "No templates in %s; create one with md2jira template new bug\n", dir)
+		return exitOK
+	}
+	for _, name := range names {
+		t, err := loadIssueTemplate(filepath.Join(dir, name+".md"))
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error reading %s: %v\n", name, err)
+			continue
+		}
+		fmt.Printf("%-20s %s\n", name, t.Description)
+	}
+	return exitOK
+}
+
+// newTemplate writes a template to dir from a built-in skeleton
+func newTemplate(dir, name, from string, force bool) int {
+	if from == "" {
+		from = name
+		if _, ok := builtinTemplates[from]; !ok {
+			from = "bug"
+		}
+	}
+	skeleton, ok := builtinTemplates[from]
+	if !ok {
+		fmt.Fprintf(os.Stderr, "Error: unknown skeleton %q (bug, incident or rfc)\n", from)
+		return exitUsage
+	}
+	path := filepath.Join(dir, name+".md")
+	if _, err := os.Stat(path); err == nil && !force {
+		fmt.Fprintf(os.Stderr, "Error: %s exists; use --force to overwrite it\n", path)
+		return exitUsage
+	}
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		return exitIO
+	}
+	if err := os.WriteFile(path, []byte(skeleton), 0644); err != nil {
+		fmt.Fprintf(os.Stderr, "Error writing template: %v\n", err)
+		return exitIO
+	}
+	fmt.Fprintf(os.Stderr, "Created %s\n", path)
+	return exitOK
+}
+
+// renderTemplate fills in a template, converts it and prints or pushes it
+func renderTemplate(dir, name string, values templateValues, cfg *config, remote *remoteFlags, push bool, project, issueType string) int {
+	path := filepath.Join(dir, name+".md")
+	t, err := loadIssueTemplate(path)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error reading template: %v\n", err)
+		return exitIO
+	}
+	if values == nil {
+		values = make(templateValues)
+	}
+	if err := promptVariables(t.Variables, values, os.Stdin, os.Stderr); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		return exitUsage
+	}
+	markdown, err := executeTemplate(name, t.body, values)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error rendering template: %v\n", err)
+		return exitConversion
+	}
+	summary, err := executeTemplate(name+" summary", t.Summary, values)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error rendering template: %v\n", err)
+		return exitConversion
+	}
+
+	result, err := converter.ConvertWithOptions(markdown, converter.Options{
+		WarnOnUnsupported: true,
+		BaseDir:           dir,
+		LanguageMap:       cfg.languages,
+		Header:            cfg.header,
+		Footer:            cfg.footer,
+		SourcePath:        path,
+	})
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error converting: %v\n", err)
+		return exitConversion
+	}
+	for _, w := range result.Warnings {
+		fmt.Fprintln(os.Stderr, formatWarning(path, w))
+	}
+	if !push {
+		fmt.Println(result.Output)
+		return exitOK
+	}
+
+	if summary = strings.TrimSpace(summary); summary == "" {
+		summary = name
+	}
+	if issueType == "" {
+		if issueType = t.IssueType; issueType == "" {
+			issueType = "Task"
+		}
+	}
+	client, err := remote.jiraClient()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		return exitUsage
+	}
+	key, err := client.CreateIssue(project, issueType, summary, result.Output)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		return exitIO
+	}
+	fmt.Fprintf(os.Stderr, "Created %s: %s\n", key, summary)
+	return exitOK
+}
+
+// promptVariables asks for the variables that are not set yet, one line each,
+// falling back to their defaults on an empty answer or at the end of input
+func promptVariables(vars []templateVariable, values templateValues, in io.Reader, out io.Writer) error {
+	reader := bufio.NewReader(in)
+	for _, v := range vars {
+		if _, ok := values[v.Name]; ok {
+			continue
+		}
+		prompt := v.Prompt
+		if prompt == "" {
+			prompt = v.Name
+		}
+		if v.Default != "" {
+			prompt += " [" + v.Default + "]"
+		}
+		fmt.Fprintf(out, "%s: ", prompt)
+		line, err := reader.ReadString('\n')
+		if err != nil && err != io.EOF {
+			return err
+		}
+		if line = strings.TrimSpace(line); line == "" {
+			if err == io.EOF && v.Default == "" {
+				fmt.Fprintln(out)
+				return fmt.Errorf("no value for %s; pass --set %s=...", v.Name, v.Name)
+			}
+			line = v.Default
+		}
+		values[v.Name] = line
+	}
+	return nil
+}
+
+// executeTemplate fills in a Go template; unknown variables are errors
+func executeTemplate(name, text string, values templateValues) (string, error) {
+	t, err := template.New(name).Option("missingkey=error").Parse(text)
+	if err != nil {
+		return "", err
+	}
+	var out bytes.Buffer
+	if err := t.Execute(&out, map[string]string(values)); err != nil {
+		return "", err
+	}
+	return out.String(), nil
+}
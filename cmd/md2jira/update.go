@@ -0,0 +1,120 @@
+// md2jira update replaces a text field of an issue with a converted document
+
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"regexp"
+	"strings"
+)
+
+// textFieldRe matches the fields update can set: the system text fields and
+// custom fields by ID
+var textFieldRe = regexp.MustCompile(`^(description|environment|customfield_[0-9]+)$`)
+
+// runUpdate runs the update subcommand and returns the exit code
+func runUpdate(args []string) int {
+	fs := flag.NewFlagSet("update", flag.ContinueOnError)
+	issue := fs.String("issue", "", "Issue to update")
+	field := fs.String("field", "description", "Field to replace: description, environment or a custom field ID")
+	attach := fs.Bool("attach-images", false, "Attach local images to the issue and reference them by name")
+	stripTitle := fs.Bool("strip-title", false, "Remove the first H1 from the document and set it as the summary")
+	configFile := fs.String("config", "", "Configuration file")
+	remote := addRemoteFlags(fs)
+	dryRun := fs.Bool("dry-run", false, "Print the request without contacting JIRA")
+	fs.Usage = func() {
+		fmt.Fprintf(os.Stderr, `Usage:
+  md2jira update --issue PROJ-123 [options] file.md
+
+Converts a document and replaces a text field of an issue with it, so a
+canonical Markdown source can be kept in sync with the issue. Custom fields
+are named by ID, e.g. customfield_10042, and must be multi-line text fields
+using the wiki renderer. JIRA_URL, JIRA_USER and JIRA_TOKEN configure the
+connection.
+
+Options:
+  --issue string
+                Issue to update (required)
+  --field string
+                Field to replace: description (default), environment or a
+                custom field ID
+  --attach-images
+                Upload the local images of the document as attachments of the
+                issue and render them as !name.png!
+  --strip-title Remove the first H1 from the document and set its text, or
+                the title field of the front matter, as the summary
+  --dry-run     Print the request that would be sent without contacting JIRA
+`+remoteUsage+`  --config string
+                Read defaults from this file instead of
+                .md2jira.yaml/.md2jira.toml
+`)
+	}
+	files, err := parseInterspersed(fs, args)
+	if err != nil {
+		if err == flag.ErrHelp {
+			return exitOK
+		}
+		return exitUsage
+	}
+	if len(files) != 1 || *issue == "" {
+		fs.Usage()
+		return exitUsage
+	}
+	if !textFieldRe.MatchString(*field) {
+		fmt.Fprintf(os.Stderr, "Error: --field %q is not description, environment or a custom field ID such as customfield_10042\n", *field)
+		return exitUsage
+	}
+	cfg, err := loadFlagConfig(fs, *configFile)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error reading config: %v\n", err)
+		return exitUsage
+	}
+
+	path := files[0]
+	source, err := readSource(path)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error reading input: %v\n", err)
+		return exitIO
+	}
+	result, err := convertDocument(path, source, documentOptions(path, cfg, *attach, *stripTitle))
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error converting: %v\n", err)
+		return exitConversion
+	}
+	fields := map[string]interface{}{*field: result.Output}
+	if title, ok := result.Metadata["title"].(string); ok && *stripTitle && strings.TrimSpace(title) != "" {
+		fields["summary"] = strings.TrimSpace(title)
+	}
+
+	if *dryRun {
+		body, err := json.MarshalIndent(map[string]interface{}{"fields": fields}, "", "  ")
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			return exitConversion
+		}
+		printAttachments(result.Attachments)
+		fmt.Fprintf(os.Stderr, "Would send PUT /rest/api/2/issue/%s\n", *issue)
+		fmt.Println(string(body))
+		return exitOK
+	}
+
+	client, err := remote.jiraClient()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		return exitUsage
+	}
+	// Uploaded first, so the images show as soon as the field changes
+	if err := uploadAttachments(client, *issue, result.Attachments); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		return exitIO
+	}
+	if err := client.UpdateIssueFields(*issue, fields); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		return exitIO
+	}
+	fmt.Fprintf(os.Stderr, "Updated %s of %s\n", *field, *issue)
+	return exitOK
+}
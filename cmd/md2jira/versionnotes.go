@@ -0,0 +1,105 @@
+// md2jira version-notes writes a changelog section to a fix version description
+
+package main
+
+import (
+	"cmp"
+	"flag"
+	"fmt"
+	"os"
+)
+
+// runVersionNotes runs the version-notes subcommand and returns the exit code
+func runVersionNotes(args []string) int {
+	fs := flag.NewFlagSet("version-notes", flag.ContinueOnError)
+	version := fs.String("version", "", "Version whose section is published")
+	project := fs.String("project", "", "Project key")
+	name := fs.String("name", "", "Name of the JIRA version (default: --version)")
+	create := fs.Bool("create", false, "Create the version if the project has none of that name")
+	configFile := fs.String("config", "", "Configuration file")
+	remote := addRemoteFlags(fs)
+	dryRun := fs.Bool("dry-run", false, "Print the converted section without contacting JIRA")
+	fs.Usage = func() {
+		fmt.Fprintf(os.Stderr, `Usage:
+  md2jira version-notes --version 2.3.1 --project PROJ [options] [CHANGELOG.md]
+
+Extracts the notes of a release from a changelog (CHANGELOG.md by default),
+converts them and writes them to the description of the project's JIRA
+version (release) of that name. JIRA_URL, JIRA_USER and JIRA_TOKEN
+configure the connection.
+
+Options:
+  --version string
+                Version whose section is published (required)
+  --project string
+                Project key (required unless --dry-run is set)
+  --name string
+                Name of the JIRA version, when it differs from the changelog,
+                e.g. "Mobile 2.3.1" (default: --version)
+  --create      Create the version if the project has none of that name
+  --dry-run     Print the converted section without contacting JIRA
+`+remoteUsage+`  --config string
+                Read rendering defaults from this file instead of
+                .md2jira.yaml/.md2jira.toml
+`)
+	}
+	files, err := parseInterspersed(fs, args)
+	if err != nil {
+		if err == flag.ErrHelp {
+			return exitOK
+		}
+		return exitUsage
+	}
+	cfg, err := loadFlagConfig(fs, *configFile)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error reading config: %v\n", err)
+		return exitUsage
+	}
+	if len(files) > 1 || *version == "" || (*project == "" && !*dryRun) {
+		fs.Usage()
+		return exitUsage
+	}
+	changelog := "CHANGELOG.md"
+	if len(files) == 1 {
+		changelog = files[0]
+	}
+	versionName := cmp.Or(*name, *version)
+
+	notes, code := releaseNotes(changelog, *version, cfg)
+	if code != exitOK {
+		return code
+	}
+	if *dryRun {
+		fmt.Println(notes)
+		return exitOK
+	}
+
+	client, err := remote.jiraClient()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		return exitUsage
+	}
+	v, err := client.FindVersion(*project, versionName)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		return exitIO
+	}
+	if v == nil {
+		if !*create {
+			fmt.Fprintf(os.Stderr, "Error: project %s has no version %s; use --create to create it\n", *project, versionName)
+			return exitIO
+		}
+		if _, err := client.CreateVersion(*project, versionName, notes); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			return exitIO
+		}
+		fmt.Fprintf(os.Stderr, "Created %s %s\n", *project, versionName)
+		return exitOK
+	}
+	if err := client.UpdateVersionDescription(v.ID, notes); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		return exitIO
+	}
+	fmt.Fprintf(os.Stderr, "Updated description of %s %s\n", *project, versionName)
+	return exitOK
+}
@@ -0,0 +1,74 @@
+// Watch mode: re-convert inputs whenever they change on disk
+
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+)
+
+// watchDebounce groups the burst of events editors produce for one save
+const watchDebounce = 100 * time.Millisecond
+
+// runWatch converts once, then again after every change to one of files.
+// Parent directories are watched rather than the files themselves, so saves
+// that replace the file (write to a temporary file, then rename) are seen.
+func runWatch(files []string, run func() int) int {
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error starting watcher: %v\n", err)
+		return exitIO
+	}
+	defer watcher.Close()
+
+	watched := make(map[string]bool, len(files))
+	dirs := make(map[string]bool)
+	for _, file := range files {
+		abs, err := filepath.Abs(file)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			return exitIO
+		}
+		watched[abs] = true
+		if dir := filepath.Dir(abs); !dirs[dir] {
+			if err := watcher.Add(dir); err != nil {
+				fmt.Fprintf(os.Stderr, "Error watching %s: %v\n", dir, err)
+				return exitIO
+			}
+			dirs[dir] = true
+		}
+	}
+
+	run()
+	fmt.Fprintf(os.Stderr, "Watching %d file(s) for changes\n", len(watched))
+
+	var pending <-chan time.Time
+	for {
+		select {
+		case event, ok := <-watcher.Events:
+			if !ok {
+				return exitOK
+			}
+			if !event.Has(fsnotify.Write) && !event.Has(fsnotify.Create) && !event.Has(fsnotify.Rename) {
+				continue
+			}
+			if abs, err := filepath.Abs(event.Name); err != nil || !watched[abs] {
+				continue
+			}
+			pending = time.After(watchDebounce)
+		case <-pending:
+			pending = nil
+			fmt.Fprintf(os.Stderr, "[%s] change detected, converting\n", time.Now().Format("15:04:05"))
+			run()
+		case err, ok := <-watcher.Errors:
+			if !ok {
+				return exitOK
+			}
+			fmt.Fprintf(os.Stderr, "Watch error: %v\n", err)
+		}
+	}
+}
@@ -0,0 +1,693 @@
+// Atlassian Document Format (ADF) output
+// Renders the goldmark AST into ADF JSON as accepted by the Jira Cloud v3 REST API
+
+package converter
+
+import (
+	"encoding/json"
+	"maps"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/yuin/goldmark/ast"
+	east "github.com/yuin/goldmark/extension/ast"
+	"github.com/yuin/goldmark/util"
+)
+
+// ADFNode is a single node of an ADF document
+type ADFNode struct {
+	Type    string         `json:"type"`
+	Version int            `json:"version,omitempty"`
+	Attrs   map[string]any `json:"attrs,omitempty"`
+	Content []*ADFNode     `json:"content,omitempty"`
+	Text    string         `json:"text,omitempty"`
+	Marks   []ADFMark      `json:"marks,omitempty"`
+}
+
+// MarshalJSON encodes the node, giving a doc its content even when it is
+// empty, as Jira rejects documents without one
+func (n ADFNode) MarshalJSON() ([]byte, error) {
+	type node ADFNode
+	if n.Type != "doc" || len(n.Content) > 0 {
+		return json.Marshal(node(n))
+	}
+	return json.Marshal(struct {
+		node
+		Content []*ADFNode `json:"content"`
+	}{node(n), []*ADFNode{}})
+}
+
+// ADFMark is a text mark (strong, em, code, link, ...)
+type ADFMark struct {
+	Type  string         `json:"type"`
+	Attrs map[string]any `json:"attrs,omitempty"`
+}
+
+// htmlTagRe matches any HTML tag
+var htmlTagRe = regexp.MustCompile(`<[^>]+>`)
+
+// ADFRenderer renders Markdown AST to ADF nodes
+type ADFRenderer struct {
+	source   []byte
+	warnings []Warning
+	options  Options
+	// Records rendering times with Options.ProfileBlocks
+	profiler *blockProfiler
+	// Nodes rendered by the default cases, for Stats.UnknownNodes
+	unknown unknownNodes
+	// inTaskItem is set while the text of a taskItem is rendered, and tasks
+	// counts the localIds of task lists and items
+	inTaskItem bool
+	tasks      int
+}
+
+// NewADFRenderer creates a new ADF renderer
+func NewADFRenderer(source []byte, opts Options) *ADFRenderer {
+	return &ADFRenderer{
+		source:   source,
+		options:  safeOptions(opts),
+		profiler: newBlockProfiler(source, opts),
+	}
+}
+
+// Render renders the AST to an ADF document node
+func (r *ADFRenderer) Render(doc ast.Node) *ADFNode {
+	root := &ADFNode{Type: "doc", Version: 1}
+	root.Content = r.renderBlocks(doc)
+	if root.Content == nil {
+		root.Content = []*ADFNode{}
+	}
+	return root
+}
+
+// GetWarnings returns any warnings generated during rendering
+func (r *ADFRenderer) GetWarnings() []Warning {
+	return r.warnings
+}
+
+// addWarning adds a warning message positioned at a node
+func (r *ADFRenderer) addWarning(node ast.Node, code WarningCode, msg string) {
+	r.warnings = append(r.warnings, newWarning(r.source, node, code, msg))
+}
+
+// renderBlocks renders all block children of a node
+func (r *ADFRenderer) renderBlocks(node ast.Node) []*ADFNode {
+	var nodes []*ADFNode
+	for child := node.FirstChild(); child != nil; child = child.NextSibling() {
+		if r.profiler == nil || node.Kind() != ast.KindDocument {
+			nodes = append(nodes, r.renderBlock(child)...)
+			continue
+		}
+		start := time.Now()
+		block := r.renderBlock(child)
+		elapsed := time.Since(start)
+		size := 0
+		for _, n := range block {
+			if data, err := json.Marshal(n); err == nil {
+				size += len(data)
+			}
+		}
+		r.profiler.block(child, elapsed, size)
+		nodes = append(nodes, block...)
+	}
+	return nodes
+}
+
+// renderBlock renders a single block node
+func (r *ADFRenderer) renderBlock(node ast.Node) []*ADFNode {
+	defer r.profiler.enter(node)()
+	switch n := node.(type) {
+	case *ast.Heading:
+		return []*ADFNode{{
+			Type:    "heading",
+			Attrs:   map[string]any{"level": r.headingLevel(n)},
+			Content: r.renderInlines(n, nil),
+		}}
+	case *ast.Paragraph, *ast.TextBlock:
+		return r.renderParagraph(n)
+	case *ast.FencedCodeBlock:
+		if diagram := r.diagram(n); diagram != nil {
+			return []*ADFNode{diagram}
+		}
+		if r.options.isMathFence(strings.TrimSpace(string(n.Language(r.source)))) {
+			return []*ADFNode{r.mathBlock(n)}
+		}
+		lang := mapLanguage(string(n.Language(r.source)), r.options.LanguageMap)
+		return []*ADFNode{r.codeBlock(n, lang)}
+	case *ast.CodeBlock:
+		return []*ADFNode{r.codeBlock(n, "")}
+	case *ast.List:
+		if r.options.compat(Compat11) && r.options.adfTasks() && isTaskList(n) {
+			return []*ADFNode{r.renderTaskList(n)}
+		}
+		return []*ADFNode{r.renderList(n)}
+	case *ast.ThematicBreak:
+		return []*ADFNode{{Type: "rule"}}
+	case *ast.Blockquote:
+		return r.renderBlockquote(n)
+	case *ast.HTMLBlock:
+		choice, w := resolveDirective(r.options, r.source, n, WarnHTMLBlock, "HTML block found - converted to plain text", htmlBlockChoices)
+		if w != nil {
+			r.warnings = append(r.warnings, *w)
+		}
+		switch choice {
+		case ChoiceDrop:
+			return nil
+		case ChoiceNoformat:
+			return []*ADFNode{r.sourceBlock(n)}
+		}
+		var html strings.Builder
+		lines := n.Lines()
+		for i := 0; i < lines.Len(); i++ {
+			line := lines.At(i)
+			html.Write(line.Value(r.source))
+		}
+		if n.HasClosure() {
+			html.Write(n.ClosureLine.Value(r.source))
+		}
+		clean, changed := sanitizeHTML(r.options, html.String())
+		if changed && r.options.WarnOnUnsupported {
+			r.addWarning(n, WarnHTMLSanitized, "unsafe HTML removed by the sanitizer")
+		}
+		// Spacer paragraphs carry no text
+		stripped := spacerParagraphRe.ReplaceAllString(clean, "")
+		plain := strings.TrimSpace(htmlTagRe.ReplaceAllString(stripped, ""))
+		if plain == "" {
+			if r.options.PreserveSpacers && stripped != clean {
+				return []*ADFNode{{Type: "paragraph"}}
+			}
+			return nil
+		}
+		return []*ADFNode{{Type: "paragraph", Content: []*ADFNode{textNode(plain, nil)}}}
+	case *east.Table:
+		switch r.tableChoice(n) {
+		case ChoiceNoformat:
+			return []*ADFNode{r.sourceBlock(n)}
+		case ChoiceExpand:
+			// Expands cannot be nested or placed in lists, quotes and panels
+			if _, top := n.Parent().(*ast.Document); top {
+				return []*ADFNode{{Type: "expand", Attrs: map[string]any{"title": "Table"}, Content: []*ADFNode{r.renderTable(n)}}}
+			}
+		}
+		return []*ADFNode{r.renderTable(n)}
+	case *east.FootnoteList:
+		if !r.options.compat(Compat2) {
+			return r.renderBlocks(n)
+		}
+		return r.renderFootnotes(n)
+	case *admonition:
+		var title string
+		if n.Title != nil {
+			title = *n.Title
+		}
+		return []*ADFNode{r.panel(calloutMacro(n.Class), title, n)}
+	case *mathBlock:
+		return []*ADFNode{r.mathBlock(n)}
+	case *details:
+		// Expands cannot be nested or placed in lists, quotes and panels
+		if _, top := n.Parent().(*ast.Document); top {
+			return []*ADFNode{{Type: "expand", Attrs: map[string]any{"title": n.Summary}, Content: r.renderBlocks(n)}}
+		}
+		title := &ADFNode{Type: "paragraph", Content: []*ADFNode{textNode(n.Summary, []ADFMark{{Type: "strong"}})}}
+		return append([]*ADFNode{title}, r.renderBlocks(n)...)
+	case *tocMarker:
+		if r.options.WarnOnUnsupported {
+			r.addWarning(n, WarnTOC, "table of contents dropped: ADF has no table of contents")
+		}
+		return nil
+	default:
+		// For unknown blocks, try to render children
+		r.unknown.add(node)
+		return r.renderBlocks(node)
+	}
+}
+
+// renderParagraph renders a paragraph, lifting standalone images to media nodes
+func (r *ADFRenderer) renderParagraph(n ast.Node) []*ADFNode {
+	if img, ok := n.FirstChild().(*ast.Image); ok && img.NextSibling() == nil {
+		if _, safe := r.options.safeURL(string(img.Destination)); safe {
+			return []*ADFNode{r.mediaSingle(img)}
+		}
+	}
+	if isSpacerParagraph(r.source, n) {
+		if r.options.PreserveSpacers {
+			return []*ADFNode{{Type: "paragraph"}}
+		}
+		return nil
+	}
+	if r.options.DetectTraces {
+		if text, ok := traceText(r.source, n); ok {
+			return []*ADFNode{{Type: "codeBlock", Content: []*ADFNode{textNode(strings.TrimSuffix(text, "\n"), nil)}}}
+		}
+	}
+	content := r.renderInlines(n, nil)
+	if len(content) == 0 {
+		return nil
+	}
+	return []*ADFNode{{Type: "paragraph", Content: content}}
+}
+
+// codeBlock renders a fenced or indented code block
+func (r *ADFRenderer) codeBlock(n ast.Node, lang string) *ADFNode {
+	var code strings.Builder
+	lines := n.Lines()
+	for i := 0; i < lines.Len(); i++ {
+		line := lines.At(i)
+		code.Write(line.Value(r.source))
+	}
+	node := &ADFNode{Type: "codeBlock"}
+	if lang != "" && lang != "none" {
+		node.Attrs = map[string]any{"language": lang}
+	}
+	if text := strings.TrimSuffix(code.String(), "\n"); text != "" {
+		node.Content = []*ADFNode{textNode(text, nil)}
+	}
+	return node
+}
+
+// mediaSingle renders an image as an external media node
+func (r *ADFRenderer) mediaSingle(n *ast.Image) *ADFNode {
+	attrs := map[string]any{
+		"type": "external",
+		"url":  string(n.Destination),
+	}
+	if alt := r.imageAlt(n); alt != "" {
+		attrs["alt"] = alt
+	}
+	if r.options.ImageWidth > 0 {
+		attrs["width"] = r.options.ImageWidth
+	}
+	return &ADFNode{
+		Type:    "mediaSingle",
+		Content: []*ADFNode{{Type: "media", Attrs: attrs}},
+	}
+}
+
+// renderList renders a bullet or ordered list
+func (r *ADFRenderer) renderList(n *ast.List) *ADFNode {
+	list := &ADFNode{Type: "bulletList"}
+	if n.IsOrdered() {
+		list.Type = "orderedList"
+		list.Attrs = map[string]any{"order": n.Start}
+	}
+	for child := n.FirstChild(); child != nil; child = child.NextSibling() {
+		item := &ADFNode{Type: "listItem", Content: r.renderBlocks(child)}
+		if len(item.Content) == 0 {
+			item.Content = []*ADFNode{{Type: "paragraph"}}
+		}
+		list.Content = append(list.Content, item)
+	}
+	return list
+}
+
+// adfQuoteContent are the node types ADF allows in a blockquote
+var adfQuoteContent = map[string]bool{
+	"paragraph":   true,
+	"bulletList":  true,
+	"orderedList": true,
+	"codeBlock":   true,
+	"mediaGroup":  true,
+	"mediaSingle": true,
+}
+
+// renderBlockquote renders a blockquote. ADF blockquotes do not nest, so
+// nested blockquotes are flattened into it; other blocks it cannot hold,
+// such as headings and tables, end it and are rendered after it, and the
+// rest of the blockquote follows in a new one.
+func (r *ADFRenderer) renderBlockquote(n *ast.Blockquote) []*ADFNode {
+	if !r.options.compat(Compat12) {
+		return []*ADFNode{{Type: "blockquote", Content: r.renderBlocks(n)}}
+	}
+	var blocks []*ADFNode
+	for _, block := range r.renderBlocks(n) {
+		if block.Type == "blockquote" {
+			blocks = append(blocks, block.Content...)
+		} else {
+			blocks = append(blocks, block)
+		}
+	}
+	if len(blocks) == 0 {
+		return []*ADFNode{{Type: "blockquote"}}
+	}
+	var nodes []*ADFNode
+	var quote *ADFNode
+	for _, block := range blocks {
+		if !adfQuoteContent[block.Type] {
+			nodes = append(nodes, block)
+			quote = nil
+			continue
+		}
+		if quote == nil {
+			quote = &ADFNode{Type: "blockquote"}
+			nodes = append(nodes, quote)
+		}
+		quote.Content = append(quote.Content, block)
+	}
+	return nodes
+}
+
+// renderTable renders a table
+func (r *ADFRenderer) renderTable(n *east.Table) *ADFNode {
+	table := &ADFNode{Type: "table"}
+	for row := n.FirstChild(); row != nil; row = row.NextSibling() {
+		cellType := "tableCell"
+		if _, ok := row.(*east.TableHeader); ok {
+			cellType = "tableHeader"
+		}
+		tableRow := &ADFNode{Type: "tableRow"}
+		for cell := row.FirstChild(); cell != nil; cell = cell.NextSibling() {
+			paragraph := &ADFNode{Type: "paragraph", Content: r.renderInlines(cell, nil)}
+			if cell, ok := cell.(*east.TableCell); ok {
+				paragraph.Marks = r.cellAlignment(cell)
+			}
+			tableRow.Content = append(tableRow.Content, &ADFNode{
+				Type:    cellType,
+				Content: []*ADFNode{paragraph},
+			})
+		}
+		table.Content = append(table.Content, tableRow)
+	}
+	return table
+}
+
+// htmlAnchor reports whether a node is an inline <a> or </a> tag, and
+// returns the safe href of an opening tag
+func (r *ADFRenderer) htmlAnchor(node ast.Node) (string, bool) {
+	raw, ok := node.(*ast.RawHTML)
+	if !ok || !r.options.compat(Compat9) {
+		return "", false
+	}
+	var html strings.Builder
+	for i := 0; i < raw.Segments.Len(); i++ {
+		segment := raw.Segments.At(i)
+		html.Write(segment.Value(r.source))
+	}
+	m := anchorTagRe.FindStringSubmatch(html.String())
+	if m == nil || m[0] != html.String() {
+		return "", false
+	}
+	if m[1] == "/" {
+		return "", true
+	}
+	url, ok := r.options.safeURL(anchorHref(m[0]))
+	if !ok {
+		return "", true
+	}
+	return url, true
+}
+
+// imageLink renders an image as a link to it, as inline images cannot be
+// media nodes
+func (r *ADFRenderer) imageLink(alt, dest string, marks []ADFMark) []*ADFNode {
+	if _, ok := r.options.safeURL(dest); !ok {
+		if alt == "" {
+			return nil
+		}
+		return []*ADFNode{textNode(alt, marks)}
+	}
+	if alt == "" {
+		alt = dest
+	}
+	link := ADFMark{Type: "link", Attrs: map[string]any{"href": dest}}
+	return []*ADFNode{textNode(alt, withMark(marks, link))}
+}
+
+// renderInlines renders all inline children of a node with the given marks
+func (r *ADFRenderer) renderInlines(node ast.Node, marks []ADFMark) []*ADFNode {
+	var nodes []*ADFNode
+	// Text between inline <a href> and </a> tags is linked; a link without
+	// text shows its target
+	childMarks, href, linkStart := marks, "", 0
+	for child := node.FirstChild(); child != nil; child = child.NextSibling() {
+		if url, ok := r.htmlAnchor(child); ok {
+			if href != "" && len(nodes) == linkStart {
+				nodes = append(nodes, textNode(href, childMarks))
+			}
+			childMarks, href, linkStart = marks, url, len(nodes)
+			if url != "" {
+				childMarks = withMark(marks, ADFMark{Type: "link", Attrs: map[string]any{"href": url}})
+			}
+			continue
+		}
+		for _, inline := range r.renderInline(child, childMarks) {
+			// Merge adjacent text runs that carry the same marks
+			if last := len(nodes) - 1; last >= 0 && inline.Type == "text" &&
+				nodes[last].Type == "text" && sameMarks(nodes[last].Marks, inline.Marks) {
+				nodes[last].Text += inline.Text
+				continue
+			}
+			nodes = append(nodes, inline)
+		}
+	}
+	return nodes
+}
+
+// renderInline renders a single inline node
+func (r *ADFRenderer) renderInline(node ast.Node, marks []ADFMark) []*ADFNode {
+	defer r.profiler.enter(node)()
+	switch n := node.(type) {
+	case *ast.Text:
+		var nodes []*ADFNode
+		if value := unescapeMarkdown(n.Segment.Value(r.source)); value != "" {
+			switch {
+			case hasLinkMark(marks):
+				nodes = append(nodes, textNode(value, marks))
+			case r.options.ConvertMentions:
+				nodes = append(nodes, r.mentionNodes(n, value, marks)...)
+			default:
+				nodes = append(nodes, r.issueNodes(value, marks)...)
+			}
+		}
+		if n.HardLineBreak() {
+			nodes = append(nodes, &ADFNode{Type: "hardBreak"})
+		} else if n.SoftLineBreak() {
+			nodes = append(nodes, textNode(" ", marks))
+		}
+		return nodes
+	case *ast.String:
+		return []*ADFNode{textNode(unescapeMarkdown(n.Value), marks)}
+	case *mathInline:
+		return []*ADFNode{r.mathInline(n, marks)}
+	case *ast.CodeSpan:
+		code := string(n.Text(r.source)) //nolint: staticcheck
+		return []*ADFNode{textNode(code, withMark(marks, ADFMark{Type: "code"}))}
+	case *ast.Emphasis:
+		mark := ADFMark{Type: "em"}
+		if n.Level == 2 {
+			mark.Type = "strong"
+		}
+		return r.renderInlines(n, withMark(marks, mark))
+	case *east.Strikethrough:
+		return r.renderInlines(n, withMark(marks, ADFMark{Type: "strike"}))
+	case *inserted:
+		return r.renderInlines(n, withMark(marks, ADFMark{Type: "underline"}))
+	case *ast.Link:
+		if _, ok := r.options.safeURL(string(n.Destination)); !ok {
+			return r.renderInlines(n, marks)
+		}
+		href := string(n.Destination)
+		if target, ok := r.options.documentURL(href); ok {
+			href = target
+		}
+		link := ADFMark{Type: "link", Attrs: map[string]any{"href": href}}
+		content := r.renderInlines(n, withMark(marks, link))
+		if len(content) == 0 {
+			content = []*ADFNode{textNode(string(n.Destination), withMark(marks, link))}
+		}
+		return content
+	case *ast.AutoLink:
+		url := string(n.URL(r.source))
+		if _, ok := r.options.safeURL(url); !ok {
+			return []*ADFNode{textNode(url, marks)}
+		}
+		link := ADFMark{Type: "link", Attrs: map[string]any{"href": url}}
+		return []*ADFNode{textNode(url, withMark(marks, link))}
+	case *ast.Image:
+		return r.imageLink(r.imageAlt(n), string(n.Destination), marks)
+	case *ast.RawHTML:
+		var html strings.Builder
+		segments := n.Segments
+		for i := 0; i < segments.Len(); i++ {
+			segment := segments.At(i)
+			html.Write(segment.Value(r.source))
+		}
+		if html.String() == "<br>" || html.String() == "<br/>" || html.String() == "<br />" {
+			return []*ADFNode{{Type: "hardBreak"}}
+		}
+		if tag := html.String(); imgRe.FindString(tag) == tag && r.options.compat(Compat9) {
+			alt, src := htmlAttr(tag, "alt"), strings.TrimSpace(htmlAttr(tag, "src"))
+			if src == "" && alt != "" {
+				return []*ADFNode{textNode(alt, marks)}
+			} else if src == "" {
+				return nil
+			}
+			return r.imageLink(alt, src, marks)
+		}
+		return nil
+	case *east.FootnoteLink:
+		if !r.options.compat(Compat2) {
+			return nil
+		}
+		return []*ADFNode{textNode(strconv.Itoa(n.Index), withMark(marks, supMark))}
+	case *east.FootnoteBacklink:
+		// Footnotes are rendered without their backlinks
+		return nil
+	case *east.TaskCheckBox:
+		if r.inTaskItem {
+			// The state of the taskItem is the checkbox
+			return nil
+		}
+		if marker, ok := r.options.taskMarker(n.IsChecked); ok {
+			if marker == "" {
+				return nil
+			}
+			return []*ADFNode{textNode(marker, marks)}
+		}
+		if n.IsChecked {
+			return []*ADFNode{textNode("[x] ", marks)}
+		}
+		return []*ADFNode{textNode("[ ] ", marks)}
+	default:
+		r.unknown.add(node)
+		return r.renderInlines(node, marks)
+	}
+}
+
+// panelTypes maps callout macros to ADF panel types
+var panelTypes = map[string]string{"info": "info", "tip": "success", "note": "note", "warning": "error"}
+
+// panel renders a callout as an ADF panel; an explicit title becomes a
+// leading bold paragraph, since panels have no title
+func (r *ADFRenderer) panel(macro, title string, n ast.Node) *ADFNode {
+	var content []*ADFNode
+	if title != "" {
+		content = append(content, &ADFNode{Type: "paragraph", Content: []*ADFNode{textNode(title, []ADFMark{{Type: "strong"}})}})
+	}
+	content = append(content, r.renderBlocks(n)...)
+	return &ADFNode{Type: "panel", Attrs: map[string]any{"panelType": panelTypes[macro]}, Content: content}
+}
+
+// supMark marks superscript text
+var supMark = ADFMark{Type: "subsup", Attrs: map[string]any{"type": "sup"}}
+
+// renderFootnotes renders the footnote definitions as a Footnotes section of
+// paragraphs led by their superscript number
+func (r *ADFRenderer) renderFootnotes(n *east.FootnoteList) []*ADFNode {
+	nodes := []*ADFNode{{
+		Type:    "heading",
+		Attrs:   map[string]any{"level": 4},
+		Content: []*ADFNode{textNode("Footnotes", nil)},
+	}}
+	for child := n.FirstChild(); child != nil; child = child.NextSibling() {
+		footnote, ok := child.(*east.Footnote)
+		if !ok {
+			continue
+		}
+		content := []*ADFNode{textNode(strconv.Itoa(footnote.Index), []ADFMark{supMark}), textNode(" ", nil)}
+		for block := footnote.FirstChild(); block != nil; block = block.NextSibling() {
+			if len(content) > 2 {
+				content = append(content, textNode(" ", nil))
+			}
+			content = append(content, r.renderInlines(block, nil)...)
+		}
+		nodes = append(nodes, &ADFNode{Type: "paragraph", Content: content})
+	}
+	return nodes
+}
+
+// plainText collects the plain text content of a node
+func (r *ADFRenderer) plainText(node ast.Node) string {
+	var buf strings.Builder
+	for child := node.FirstChild(); child != nil; child = child.NextSibling() {
+		switch n := child.(type) {
+		case *ast.Text:
+			buf.WriteString(unescapeMarkdown(n.Segment.Value(r.source)))
+		case *ast.String:
+			buf.WriteString(unescapeMarkdown(n.Value))
+		default:
+			buf.WriteString(r.plainText(child))
+		}
+	}
+	return buf.String()
+}
+
+// textNode creates an ADF text node
+func textNode(text string, marks []ADFMark) *ADFNode {
+	return &ADFNode{Type: "text", Text: text, Marks: marks}
+}
+
+// sameMarks reports whether two mark lists are identical
+func sameMarks(a, b []ADFMark) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i].Type != b[i].Type || !maps.Equal(a[i].Attrs, b[i].Attrs) {
+			return false
+		}
+	}
+	return true
+}
+
+// withMark returns a copy of marks with mark appended
+func withMark(marks []ADFMark, mark ADFMark) []ADFMark {
+	result := make([]ADFMark, 0, len(marks)+1)
+	result = append(result, marks...)
+	return append(result, mark)
+}
+
+// unescapeMarkdown resolves backslash escapes and character references
+func unescapeMarkdown(value []byte) string {
+	value = util.ResolveNumericReferences(value)
+	value = util.ResolveEntityNames(value)
+	return string(util.UnescapePunctuations(value))
+}
+
+// ConvertToADF converts Markdown to an ADF JSON document
+func ConvertToADF(markdown string) ([]byte, error) {
+	result, err := ConvertToADFWithOptions(markdown, Options{})
+	if err != nil {
+		return nil, err
+	}
+	return []byte(result.Output), nil
+}
+
+// ConvertToADFWithOptions converts Markdown to an ADF JSON document with options
+func ConvertToADFWithOptions(markdown string, opts Options) (Result, error) {
+	start := time.Now()
+	source := normalizeSource([]byte(markdown), opts)
+	if err := checkCompatLevel(opts.CompatLevel); err != nil {
+		return Result{}, err
+	}
+	header, footer, err := executeTemplates(opts)
+	if err != nil {
+		return Result{}, err
+	}
+	parseStart := time.Now()
+	doc := parseMarkdown(source, opts)
+	parsed := time.Since(parseStart)
+
+	renderer := NewADFRenderer(source, opts)
+	root := renderer.Render(doc)
+	if header != "" || footer != "" {
+		content := append(templateADF(header, opts), root.Content...)
+		root.Content = append(content, templateADF(footer, opts)...)
+	}
+	output, err := json.Marshal(root)
+	if err != nil {
+		return Result{}, err
+	}
+
+	warnings := renderer.GetWarnings()
+	result := Result{
+		Output:   string(output),
+		Warnings: warnings,
+		Stats:    collectStats(doc, source, string(output), warnings, renderer.unknown),
+		Metadata: metadata(doc, source, opts),
+		Profile:  renderer.profiler.profile(parsed),
+	}
+	opts.observeConversion("adf", start, len(result.Output), result.Warnings)
+	return result, nil
+}
@@ -0,0 +1,60 @@
+package converter
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestADFBlocks(t *testing.T) {
+	tests := []struct {
+		name     string
+		markdown string
+		// want is the content of the doc
+		want string
+	}{
+		{"heading", "# H", `{"type":"heading","attrs":{"level":1},"content":[{"type":"text","text":"H"}]}`},
+		{"marks", "**b** `c` [l](http://x)", `{"type":"paragraph","content":[{"type":"text","text":"b","marks":[{"type":"strong"}]},{"type":"text","text":" "},` +
+			`{"type":"text","text":"c","marks":[{"type":"code"}]},{"type":"text","text":" "},{"type":"text","text":"l","marks":[{"type":"link","attrs":{"href":"http://x"}}]}]}`},
+		{"code block", "```go\nx\n```", `{"type":"codeBlock","attrs":{"language":"go"},"content":[{"type":"text","text":"x"}]}`},
+		{"ordered list", "1. a", `{"type":"orderedList","attrs":{"order":1},"content":[{"type":"listItem","content":[{"type":"paragraph","content":[{"type":"text","text":"a"}]}]}]}`},
+		{"table", "| h |\n|---|\n| c |", `{"type":"table","content":[{"type":"tableRow","content":[{"type":"tableHeader","content":[{"type":"paragraph","content":[{"type":"text","text":"h"}]}]}]},` +
+			`{"type":"tableRow","content":[{"type":"tableCell","content":[{"type":"paragraph","content":[{"type":"text","text":"c"}]}]}]}]}`},
+		{"rule", "---", `{"type":"rule"}`},
+		{"image", "![alt](a.png)", `{"type":"mediaSingle","content":[{"type":"media","attrs":{"alt":"alt","type":"external","url":"a.png"}}]}`},
+		{"hard break", "a  \nb", `{"type":"paragraph","content":[{"type":"text","text":"a"},{"type":"hardBreak"},{"type":"text","text":"b"}]}`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := ConvertToADFWithOptions(tt.markdown, Options{})
+			if err != nil {
+				t.Fatal(err)
+			}
+			want := `{"type":"doc","version":1,"content":[` + tt.want + `]}`
+			if got := strings.TrimSpace(result.Output); got != want {
+				t.Errorf("got  %s\nwant %s", got, want)
+			}
+		})
+	}
+}
+
+func TestADFEmptyDocuments(t *testing.T) {
+	tests := []struct {
+		markdown string
+		level    CompatLevel
+	}{
+		{"", CompatLatest},
+		{"", Compat1},
+		{"\n\n", CompatLatest},
+		{"<!-- comment -->", CompatLatest},
+		{"---\ntitle: T\n---\n", CompatLatest},
+	}
+	for _, tt := range tests {
+		result, err := ConvertToADFWithOptions(tt.markdown, Options{CompatLevel: tt.level})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := strings.TrimSpace(result.Output); got != `{"type":"doc","version":1,"content":[]}` {
+			t.Errorf("ADF of %q at level %v = %s, want a doc with empty content", tt.markdown, tt.level, got)
+		}
+	}
+}
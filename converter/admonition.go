@@ -0,0 +1,231 @@
+// Admonitions
+// Parses Python-Markdown "!!! note" blocks and GitHub "> [!NOTE]" alerts and
+// renders them as JIRA panels
+
+package converter
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/astsu-dev/md2jira/jiraescape"
+	"github.com/yuin/goldmark/ast"
+	"github.com/yuin/goldmark/parser"
+	"github.com/yuin/goldmark/text"
+	"github.com/yuin/goldmark/util"
+)
+
+// kindAdmonition is the node kind of admonitions
+var kindAdmonition = ast.NewNodeKind("Admonition")
+
+// admonition is a callout block such as "!!! warning" whose indented content
+// is parsed as Markdown
+type admonition struct {
+	ast.BaseBlock
+	// Class is the lower-case admonition type (note, warning, tip, ...)
+	Class string
+	// Title is the explicit title; nil means the capitalized type
+	Title *string
+}
+
+// Kind implements ast.Node
+func (n *admonition) Kind() ast.NodeKind {
+	return kindAdmonition
+}
+
+// Dump implements ast.Node
+func (n *admonition) Dump(source []byte, level int) {
+	ast.DumpHelper(n, source, level, map[string]string{"Class": n.Class}, nil)
+}
+
+// admonitionRe matches an admonition opener: !!! type "optional title"
+var admonitionRe = regexp.MustCompile(`^!!!\s+([A-Za-z][\w-]*)(?:\s+"(.*)")?\s*$`)
+
+// admonitionParser is a goldmark block parser for admonitions
+type admonitionParser struct{}
+
+// Trigger implements parser.BlockParser
+func (p *admonitionParser) Trigger() []byte {
+	return []byte{'!'}
+}
+
+// Open implements parser.BlockParser
+func (p *admonitionParser) Open(parent ast.Node, reader text.Reader, pc parser.Context) (ast.Node, parser.State) {
+	line, _ := reader.PeekLine()
+	pos := pc.BlockOffset()
+	if pos < 0 {
+		return nil, parser.NoChildren
+	}
+	m := admonitionRe.FindSubmatchIndex(util.TrimRightSpace(line[pos:]))
+	if m == nil {
+		return nil, parser.NoChildren
+	}
+	rest := line[pos:]
+	node := &admonition{Class: strings.ToLower(string(rest[m[2]:m[3]]))}
+	if m[4] >= 0 {
+		title := string(rest[m[4]:m[5]])
+		node.Title = &title
+	}
+	reader.Advance(len(util.TrimRightSpace(line)))
+	return node, parser.HasChildren
+}
+
+// Continue implements parser.BlockParser; content lines are indented four spaces
+func (p *admonitionParser) Continue(node ast.Node, reader text.Reader, pc parser.Context) parser.State {
+	line, _ := reader.PeekLine()
+	if util.IsBlank(line) {
+		return parser.Continue | parser.HasChildren
+	}
+	if w, _ := util.IndentWidth(line, reader.LineOffset()); w < 4 {
+		return parser.Close
+	}
+	pos, padding := util.IndentPosition(line, reader.LineOffset(), 4)
+	reader.AdvanceAndSetPadding(pos, padding)
+	return parser.Continue | parser.HasChildren
+}
+
+// Close implements parser.BlockParser
+func (p *admonitionParser) Close(node ast.Node, reader text.Reader, pc parser.Context) {}
+
+// CanInterruptParagraph implements parser.BlockParser
+func (p *admonitionParser) CanInterruptParagraph() bool {
+	return true
+}
+
+// CanAcceptIndentedLine implements parser.BlockParser
+func (p *admonitionParser) CanAcceptIndentedLine() bool {
+	return false
+}
+
+// panelStyle is how a JIRA panel macro is drawn on sites without the macro
+type panelStyle struct {
+	bgColor string
+	border  string
+}
+
+// panelStyles are the Atlassian colors of the callout macros
+var panelStyles = map[string]panelStyle{
+	"info":    {bgColor: "#deebff", border: "#4c9aff"},
+	"tip":     {bgColor: "#e3fcef", border: "#57d9a3"},
+	"note":    {bgColor: "#fffae6", border: "#ffc400"},
+	"warning": {bgColor: "#ffebe6", border: "#ff7452"},
+}
+
+// admonitionMacros maps admonition types to callout macros
+var admonitionMacros = map[string]string{
+	"note": "info", "info": "info", "todo": "info", "abstract": "info",
+	"summary": "info", "tldr": "info", "question": "info", "help": "info",
+	"faq": "info", "example": "info", "quote": "info", "cite": "info",
+	"tip": "tip", "hint": "tip", "important": "tip", "success": "tip",
+	"check": "tip", "done": "tip",
+	"caution": "note", "attention": "note",
+	"warning": "warning", "danger": "warning", "error": "warning",
+	"failure": "warning", "fail": "warning", "missing": "warning", "bug": "warning",
+}
+
+// calloutMacro returns the callout macro of an admonition type (info by default)
+func calloutMacro(kind string) string {
+	if macro, ok := admonitionMacros[kind]; ok {
+		return macro
+	}
+	return "info"
+}
+
+// admonitionTitle returns the title of an admonition
+func admonitionTitle(n *admonition) string {
+	if n.Title != nil {
+		return *n.Title
+	}
+	return strings.ToUpper(n.Class[:1]) + n.Class[1:]
+}
+
+// calloutOpen returns the opening markup of a callout: the macro itself where
+// the dialect has callout macros, otherwise a colored {panel}
+func (r *JIRARenderer) calloutOpen(macro, title string) string {
+	title = jiraescape.MacroParam(title)
+	if r.profile().calloutMacros {
+		if title == "" {
+			return "{" + macro + "}"
+		}
+		return "{" + macro + ":title=" + title + "}"
+	}
+	style := panelStyles[macro]
+	params := []string{"borderColor=" + style.border, "bgColor=" + style.bgColor}
+	if title != "" {
+		params = append([]string{"title=" + title}, params...)
+	}
+	return "{panel:" + strings.Join(params, "|") + "}"
+}
+
+// calloutClose returns the closing markup of a callout
+func (r *JIRARenderer) calloutClose(macro string) string {
+	if r.profile().calloutMacros {
+		return "{" + macro + "}"
+	}
+	return "{panel}"
+}
+
+// renderAdmonition renders an admonition as a callout
+func (r *JIRARenderer) renderAdmonition(buf *strings.Builder, n *admonition, entering bool) {
+	macro := calloutMacro(n.Class)
+	if entering {
+		fmt.Fprintf(buf, "%s\n", r.calloutOpen(macro, admonitionTitle(n)))
+	} else {
+		fmt.Fprintf(buf, "%s\n\n", r.calloutClose(macro))
+	}
+}
+
+// alertRe matches the first line of a GitHub alert: > [!NOTE]
+var alertRe = regexp.MustCompile(`^\[!(?i:(note|tip|important|warning|caution))\]\s*$`)
+
+// alertTransformer turns GitHub alert blockquotes into admonitions, so they
+// render as callouts in both output formats
+type alertTransformer struct{}
+
+// Transform implements parser.ASTTransformer
+func (t *alertTransformer) Transform(doc *ast.Document, reader text.Reader, pc parser.Context) {
+	source := reader.Source()
+	var quotes []*ast.Blockquote
+	ast.Walk(doc, func(n ast.Node, entering bool) (ast.WalkStatus, error) {
+		if quote, ok := n.(*ast.Blockquote); ok && entering {
+			quotes = append(quotes, quote)
+		}
+		return ast.WalkContinue, nil
+	})
+	for _, quote := range quotes {
+		para, ok := quote.FirstChild().(*ast.Paragraph)
+		if !ok || para.Lines().Len() == 0 {
+			continue
+		}
+		first := para.Lines().At(0)
+		m := alertRe.FindSubmatch(first.Value(source))
+		if m == nil {
+			continue
+		}
+		// Drop the [!TYPE] marker, and the paragraph if nothing follows it
+		for child := para.FirstChild(); child != nil; child = para.FirstChild() {
+			if t, ok := child.(*ast.Text); !ok || t.Segment.Start >= first.Stop {
+				break
+			}
+			para.RemoveChild(para, child)
+		}
+		if para.FirstChild() == nil {
+			quote.RemoveChild(quote, para)
+		} else {
+			lines := para.Lines()
+			para.SetLines(text.NewSegments())
+			for i := 1; i < lines.Len(); i++ {
+				para.Lines().Append(lines.At(i))
+			}
+		}
+
+		alert := &admonition{Class: strings.ToLower(string(m[1]))}
+		alert.SetLines(quote.Lines())
+		for child := quote.FirstChild(); child != nil; child = quote.FirstChild() {
+			quote.RemoveChild(quote, child)
+			alert.AppendChild(alert, child)
+		}
+		quote.Parent().ReplaceChild(quote.Parent(), quote, alert)
+	}
+}
@@ -0,0 +1,255 @@
+// Hunspell affix files
+// Expands the words of a .dic file with the prefix and suffix rules of the
+// .aff file next to it, so words/FLAGS entries accept their inflected forms
+
+package converter
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// affixRule is a PFX or SFX rule: strip is removed from the stem and add
+// put in its place when the stem matches the condition
+type affixRule struct {
+	strip string
+	add   string
+	cond  []affixCondition
+	// cross rules combine with the rules of the other kind
+	cross bool
+}
+
+// affixCondition matches one character of a rule condition: ".", a
+// character, or a [set] or [^set]
+type affixCondition struct {
+	any    bool
+	negate bool
+	chars  string
+}
+
+// affixes are the rules of an .aff file, by flag
+type affixes struct {
+	// flagMode is the FLAG setting: "" (one character), long, num or UTF-8
+	flagMode string
+	// aliases are the AF flag sets that numeric flags of words refer to
+	aliases   [][]string
+	prefixes  map[string][]affixRule
+	suffixes  map[string][]affixRule
+	needAffix string
+}
+
+// loadAffixes reads the PFX, SFX, FLAG, AF and NEEDAFFIX settings of an .aff
+// file; other settings, which concern suggestions and compounds, are ignored
+func loadAffixes(path string) (*affixes, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	a := &affixes{prefixes: make(map[string][]affixRule), suffixes: make(map[string][]affixRule)}
+	// cross holds the cross product setting of the rule headers read so far
+	cross := make(map[string]bool)
+	aliasCount := false
+	scanner := bufio.NewScanner(f)
+	for line := 1; scanner.Scan(); line++ {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) < 2 || strings.HasPrefix(fields[0], "#") {
+			continue
+		}
+		switch fields[0] {
+		case "FLAG":
+			a.flagMode = fields[1]
+		case "NEEDAFFIX":
+			a.needAffix = fields[1]
+		case "AF":
+			// The first AF line is the number of aliases
+			if !aliasCount {
+				aliasCount = true
+				if _, err := strconv.Atoi(fields[1]); err == nil {
+					continue
+				}
+			}
+			a.aliases = append(a.aliases, a.parseFlags(fields[1]))
+		case "PFX", "SFX":
+			rules := a.suffixes
+			if fields[0] == "PFX" {
+				rules = a.prefixes
+			}
+			key := fields[0] + " " + fields[1]
+			// A header is PFX flag Y|N count, a rule PFX flag strip add condition
+			if len(fields) == 4 && (fields[2] == "Y" || fields[2] == "N") {
+				if _, err := strconv.Atoi(fields[3]); err == nil {
+					cross[key] = fields[2] == "Y"
+					continue
+				}
+			}
+			if len(fields) < 4 {
+				return nil, fmt.Errorf("%s:%d: %s rule needs a strip, an affix and a condition", path, line, fields[0])
+			}
+			rule := affixRule{strip: fields[2], add: fields[3], cross: cross[key]}
+			if rule.strip == "0" {
+				rule.strip = ""
+			}
+			// Continuation flags of the affix (ed/X) are not applied
+			if i := strings.IndexByte(rule.add, '/'); i >= 0 {
+				rule.add = rule.add[:i]
+			}
+			if rule.add == "0" {
+				rule.add = ""
+			}
+			condition := "."
+			if len(fields) > 4 {
+				condition = fields[4]
+			}
+			if rule.cond, err = parseAffixCondition(condition); err != nil {
+				return nil, fmt.Errorf("%s:%d: %v", path, line, err)
+			}
+			rules[fields[1]] = append(rules[fields[1]], rule)
+		}
+	}
+	return a, scanner.Err()
+}
+
+// parseAffixCondition parses a rule condition, such as [^aeiou]y
+func parseAffixCondition(condition string) ([]affixCondition, error) {
+	var conds []affixCondition
+	for i := 0; i < len(condition); {
+		switch condition[i] {
+		case '.':
+			conds = append(conds, affixCondition{any: true})
+			i++
+		case '[':
+			end := strings.IndexByte(condition[i:], ']')
+			if end < 0 {
+				return nil, fmt.Errorf("unterminated [ in condition %q", condition)
+			}
+			set := condition[i+1 : i+end]
+			cond := affixCondition{chars: set}
+			if strings.HasPrefix(set, "^") {
+				cond = affixCondition{negate: true, chars: set[1:]}
+			}
+			conds = append(conds, cond)
+			i += end + 1
+		default:
+			c, size := utf8.DecodeRuneInString(condition[i:])
+			conds = append(conds, affixCondition{chars: string(c)})
+			i += size
+		}
+	}
+	return conds, nil
+}
+
+// matches reports whether a rune satisfies the condition
+func (c affixCondition) matches(r rune) bool {
+	if c.any {
+		return true
+	}
+	return strings.ContainsRune(c.chars, r) != c.negate
+}
+
+// parseFlags splits the flags of a word or an AF alias according to the FLAG
+// setting
+func (a *affixes) parseFlags(flags string) []string {
+	switch a.flagMode {
+	case "long":
+		var out []string
+		for i := 0; i+1 < len(flags); i += 2 {
+			out = append(out, flags[i:i+2])
+		}
+		return out
+	case "num":
+		return strings.Split(flags, ",")
+	default:
+		out := make([]string, 0, len(flags))
+		for _, r := range flags {
+			out = append(out, string(r))
+		}
+		return out
+	}
+}
+
+// wordFlags returns the flags of a dictionary word, resolving AF aliases
+func (a *affixes) wordFlags(flags string) []string {
+	if len(a.aliases) > 0 {
+		if n, err := strconv.Atoi(flags); err == nil && n >= 1 && n <= len(a.aliases) {
+			return a.aliases[n-1]
+		}
+	}
+	return a.parseFlags(flags)
+}
+
+// expand returns the forms of a stem with the given flags: the stem itself,
+// unless it needs an affix, and the stem with each matching prefix, suffix
+// and cross product of the two
+func (a *affixes) expand(stem, flags string) []string {
+	var forms []string
+	var prefixes, suffixes []affixRule
+	needAffix := false
+	for _, flag := range a.wordFlags(flags) {
+		if flag == a.needAffix {
+			needAffix = true
+		}
+		prefixes = append(prefixes, a.prefixes[flag]...)
+		suffixes = append(suffixes, a.suffixes[flag]...)
+	}
+	if !needAffix {
+		forms = append(forms, stem)
+	}
+	for _, sfx := range suffixes {
+		word, ok := sfx.applySuffix(stem)
+		if !ok {
+			continue
+		}
+		forms = append(forms, word)
+		if !sfx.cross {
+			continue
+		}
+		for _, pfx := range prefixes {
+			if crossed, ok := pfx.applyPrefix(word); ok && pfx.cross {
+				forms = append(forms, crossed)
+			}
+		}
+	}
+	for _, pfx := range prefixes {
+		if word, ok := pfx.applyPrefix(stem); ok {
+			forms = append(forms, word)
+		}
+	}
+	return forms
+}
+
+// applySuffix applies a suffix rule, whose condition matches the end of the
+// stem
+func (r affixRule) applySuffix(stem string) (string, bool) {
+	runes := []rune(stem)
+	if len(runes) < len(r.cond) || !strings.HasSuffix(stem, r.strip) || len(r.strip) >= len(stem) {
+		return "", false
+	}
+	tail := runes[len(runes)-len(r.cond):]
+	for i, cond := range r.cond {
+		if !cond.matches(tail[i]) {
+			return "", false
+		}
+	}
+	return strings.TrimSuffix(stem, r.strip) + r.add, true
+}
+
+// applyPrefix applies a prefix rule, whose condition matches the start of the
+// stem
+func (r affixRule) applyPrefix(stem string) (string, bool) {
+	runes := []rune(stem)
+	if len(runes) < len(r.cond) || !strings.HasPrefix(stem, r.strip) || len(r.strip) >= len(stem) {
+		return "", false
+	}
+	for i, cond := range r.cond {
+		if !cond.matches(runes[i]) {
+			return "", false
+		}
+	}
+	return r.add + strings.TrimPrefix(stem, r.strip), true
+}
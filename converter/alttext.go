@@ -0,0 +1,124 @@
+// Generated alt text
+// Asks a pluggable captioner, such as an OCR tool or an image captioning
+// service, for the alt text of images written without one
+
+package converter
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+	"time"
+
+	"github.com/yuin/goldmark/ast"
+)
+
+// AltTextGenerator describes images that have no alt text
+type AltTextGenerator interface {
+	// AltText returns a short description of the image at src: a URL, or a
+	// local file resolved against Options.BaseDir
+	AltText(src string) (string, error)
+}
+
+// AltTextFunc adapts a function to an AltTextGenerator
+type AltTextFunc func(src string) (string, error)
+
+// AltText implements AltTextGenerator
+func (f AltTextFunc) AltText(src string) (string, error) {
+	return f(src)
+}
+
+// CommandAltText runs an external command with the image as its last
+// argument and uses what it prints as the alt text
+type CommandAltText struct {
+	// Command is the executable, e.g. tesseract or a captioning script
+	Command string
+	// Args are passed before the image
+	Args []string
+	// Timeout bounds each run (default 30s)
+	Timeout time.Duration
+}
+
+// AltText implements AltTextGenerator
+func (c CommandAltText) AltText(src string) (string, error) {
+	timeout := c.Timeout
+	if timeout == 0 {
+		timeout = 30 * time.Second
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+	cmd := exec.CommandContext(ctx, c.Command, append(append([]string{}, c.Args...), src)...)
+	var stderr strings.Builder
+	cmd.Stderr = &stderr
+	out, err := cmd.Output()
+	if err != nil {
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			return "", fmt.Errorf("%s: %v: %s", c.Command, err, msg)
+		}
+		return "", fmt.Errorf("%s: %v", c.Command, err)
+	}
+	return string(out), nil
+}
+
+// altTextReplacer removes the characters that end an image attribute
+var altTextReplacer = strings.NewReplacer("|", " ", "!", "", ",", "")
+
+// generatedAltText asks the alt text generator, through the cache, to
+// describe the image at dest; local images are keyed by their content, so a
+// changed file is described again
+func (o Options) generatedAltText(dest string) (string, error) {
+	src := dest
+	var content string
+	if path, ok := localImagePath(dest, o.BaseDir); ok {
+		src = path
+		if data, err := os.ReadFile(path); err == nil {
+			sum := sha256.Sum256(data)
+			content = hex.EncodeToString(sum[:])
+		}
+	}
+	key := cacheKey("alttext", fmt.Sprintf("%T%+v", o.AltText, o.AltText), src, content)
+	if alt, ok := o.cacheGet(key); ok {
+		return string(alt), nil
+	}
+	o.debugf("describing image %s with %T", src, o.AltText)
+	alt, err := o.AltText.AltText(src)
+	if err != nil {
+		o.warnf("describing image %s: %v", src, err)
+		return "", err
+	}
+	alt = strings.Join(strings.Fields(altTextReplacer.Replace(alt)), " ")
+	o.cacheSet(key, []byte(alt))
+	return alt, nil
+}
+
+// imageAlt returns the alt text of an image, generating one when the image
+// has none and a generator is set
+func (r *JIRARenderer) imageAlt(n *ast.Image) string {
+	alt := r.getImageAlt(n)
+	if alt != "" || r.options.AltText == nil {
+		return alt
+	}
+	alt, err := r.options.generatedAltText(string(n.Destination))
+	if err != nil && r.options.WarnOnUnsupported {
+		r.addWarning(WarnAltText, fmt.Sprintf("no alt text generated for %s: %v", n.Destination, err))
+	}
+	return alt
+}
+
+// imageAlt returns the alt text of an image, generating one when the image
+// has none and a generator is set
+func (r *ADFRenderer) imageAlt(n *ast.Image) string {
+	alt := r.plainText(n)
+	if alt != "" || r.options.AltText == nil {
+		return alt
+	}
+	alt, err := r.options.generatedAltText(string(n.Destination))
+	if err != nil && r.options.WarnOnUnsupported {
+		r.addWarning(n, WarnAltText, fmt.Sprintf("no alt text generated for %s: %v", n.Destination, err))
+	}
+	return alt
+}
@@ -0,0 +1,44 @@
+// Heading anchors
+// Links to a heading of the same document ([see below](#installation)) need
+// an {anchor} macro on the heading, named after the heading ID goldmark
+// computes, for the #installation link to resolve in JIRA
+
+package converter
+
+import "github.com/yuin/goldmark/ast"
+
+// collectAnchors records the fragments that links of the document point to,
+// so that only the headings they name get an anchor
+func (r *JIRARenderer) collectAnchors(doc ast.Node) {
+	if !r.options.compat(Compat6) {
+		return
+	}
+	r.anchors = make(map[string]bool)
+	_ = ast.Walk(doc, func(node ast.Node, entering bool) (ast.WalkStatus, error) {
+		if link, ok := node.(*ast.Link); ok && entering {
+			if url := string(link.Destination); isFragment(url) {
+				r.anchors[url[1:]] = true
+			}
+		}
+		return ast.WalkContinue, nil
+	})
+}
+
+// headingAnchor returns the {anchor} macro of a heading that is linked to
+func (r *JIRARenderer) headingAnchor(n *ast.Heading) string {
+	value, ok := n.AttributeString("id")
+	if !ok {
+		return ""
+	}
+	id, ok := value.([]byte)
+	if !ok || !r.anchors[string(id)] {
+		return ""
+	}
+	return "{anchor:" + string(id) + "}"
+}
+
+// isFragment reports whether a link destination is a heading of the same
+// document
+func isFragment(url string) bool {
+	return len(url) > 1 && url[0] == '#'
+}
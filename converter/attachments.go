@@ -0,0 +1,116 @@
+// Local image attachments
+// Renders images of local files as attachment references (!arch.png!) and
+// lists the files, so they can be uploaded to the issue the markup is
+// published to
+
+package converter
+
+import (
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// Attachment is a local file referenced by an image, which must be attached
+// to the issue under Name for the image to show
+type Attachment struct {
+	Name string `json:"name"`
+	// Path is the file, resolved against Options.BaseDir
+	Path string `json:"path"`
+}
+
+// attachmentNameReplacer replaces the characters that end an image or start
+// markup inside one
+var attachmentNameReplacer = strings.NewReplacer(
+	"|", "_", "!", "_", "[", "_", "]", "_", "{", "_", "}", "_",
+	" ", "_", "\t", "_", "\n", "_", "\r", "_",
+)
+
+// attachmentSet names the local images of a document
+type attachmentSet struct {
+	list []Attachment
+	// byPath maps the files to their names; names are the names in use
+	byPath map[string]string
+	names  map[string]bool
+}
+
+// add returns the attachment name of a file, naming it after its base name
+// the first time; different files with the same base name get a -2, -3, ...
+// suffix
+func (s *attachmentSet) add(path string) string {
+	if name, ok := s.byPath[path]; ok {
+		return name
+	}
+	if s.byPath == nil {
+		s.byPath = make(map[string]string)
+		s.names = make(map[string]bool)
+	}
+	base := attachmentNameReplacer.Replace(filepath.Base(path))
+	ext := filepath.Ext(base)
+	name := base
+	for i := 2; s.names[name]; i++ {
+		name = strings.TrimSuffix(base, ext) + "-" + strconv.Itoa(i) + ext
+	}
+	s.byPath[path] = name
+	s.names[name] = true
+	s.list = append(s.list, Attachment{Name: name, Path: path})
+	return name
+}
+
+// localImagePath returns the file an image destination refers to, resolved
+// against baseDir, or false if it is a URL
+func localImagePath(dest, baseDir string) (string, bool) {
+	u, err := url.Parse(dest)
+	if err != nil || u.Scheme != "" || u.Host != "" || u.Path == "" {
+		return "", false
+	}
+	path, err := url.PathUnescape(u.Path)
+	if err != nil {
+		path = u.Path
+	}
+	if !filepath.IsAbs(path) {
+		path = filepath.Join(baseDir, filepath.FromSlash(path))
+	}
+	return path, true
+}
+
+// attachImage returns the attachment name of an image of a local file, or
+// false to render the destination as written: for URLs, and after a warning
+// for missing files and, in safe mode, files outside Options.BaseDir
+func (r *JIRARenderer) attachImage(dest string) (string, bool) {
+	if !r.options.AttachLocalImages {
+		return "", false
+	}
+	path, ok := localImagePath(dest, r.options.BaseDir)
+	if !ok {
+		return "", false
+	}
+	if r.options.SafeMode && !insideDir(path, r.options.BaseDir) {
+		r.addWarning(WarnAttachment, fmt.Sprintf("image %s not attached: it is outside the document directory", dest))
+		return "", false
+	}
+	info, err := os.Stat(path)
+	if err == nil && info.IsDir() {
+		err = fmt.Errorf("%s is a directory", path)
+	}
+	if err != nil {
+		r.addWarning(WarnAttachment, fmt.Sprintf("image %s not attached: %v", dest, err))
+		return "", false
+	}
+	return r.attachments.add(path), true
+}
+
+// insideDir reports whether path is dir or below it
+func insideDir(path, dir string) bool {
+	if dir == "" {
+		dir = "."
+	}
+	rel, err := filepath.Rel(dir, path)
+	if err != nil {
+		return false
+	}
+	return rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator)) && !filepath.IsAbs(rel)
+}
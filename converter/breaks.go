@@ -0,0 +1,103 @@
+// Line breaks
+// Options.HardBreak and Options.SoftBreak choose how the line breaks of a
+// paragraph are written, as some JIRA renderers break the line at every
+// newline of the markup and others need a forced break
+
+package converter
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/yuin/goldmark/ast"
+)
+
+// HardBreak selects how hard line breaks (a trailing backslash or two
+// trailing spaces) are written
+type HardBreak int
+
+const (
+	// HardBreakBackslash writes a \\ forced line break; it is the zero value
+	HardBreakBackslash HardBreak = iota
+	// HardBreakBlankLine writes a blank line, starting a new paragraph.
+	// Lists end at a blank line, so list items keep \\.
+	HardBreakBlankLine
+)
+
+// hardBreakNames maps hard break styles to their CLI names
+var hardBreakNames = map[HardBreak]string{
+	HardBreakBackslash: "backslash",
+	HardBreakBlankLine: "blank",
+}
+
+// String returns the CLI name of the hard break style
+func (b HardBreak) String() string {
+	if name, ok := hardBreakNames[b]; ok {
+		return name
+	}
+	return fmt.Sprintf("HardBreak(%d)", int(b))
+}
+
+// ParseHardBreak parses a hard break style name (backslash or blank)
+func ParseHardBreak(name string) (HardBreak, error) {
+	for style, styleName := range hardBreakNames {
+		if strings.EqualFold(name, styleName) {
+			return style, nil
+		}
+	}
+	return HardBreakBackslash, fmt.Errorf("unknown hard break style %q (want backslash or blank)", name)
+}
+
+// SoftBreak selects how soft line breaks, the newlines inside a paragraph,
+// are written
+type SoftBreak int
+
+const (
+	// SoftBreakPreserve keeps the newline; it is the zero value
+	SoftBreakPreserve SoftBreak = iota
+	// SoftBreakJoin joins the lines with a space, for renderers that break
+	// the line at every newline
+	SoftBreakJoin
+)
+
+// softBreakNames maps soft break styles to their CLI names
+var softBreakNames = map[SoftBreak]string{
+	SoftBreakPreserve: "preserve",
+	SoftBreakJoin:     "join",
+}
+
+// String returns the CLI name of the soft break style
+func (b SoftBreak) String() string {
+	if name, ok := softBreakNames[b]; ok {
+		return name
+	}
+	return fmt.Sprintf("SoftBreak(%d)", int(b))
+}
+
+// ParseSoftBreak parses a soft break style name (preserve or join)
+func ParseSoftBreak(name string) (SoftBreak, error) {
+	for style, styleName := range softBreakNames {
+		if strings.EqualFold(name, styleName) {
+			return style, nil
+		}
+	}
+	return SoftBreakPreserve, fmt.Errorf("unknown soft break style %q (want preserve or join)", name)
+}
+
+// lineBreak returns what follows a text node ending in a line break, if any
+func (r *JIRARenderer) lineBreak(n *ast.Text) string {
+	switch {
+	case n.HardLineBreak():
+		// A blank line would end the list, the table or the bq. paragraph
+		if r.options.HardBreak == HardBreakBlankLine && len(r.listStack) == 0 && !r.inTableCell && r.quoteDepth < 2 {
+			return "\n\n"
+		}
+		return "\\\\\n"
+	case n.SoftLineBreak():
+		if r.options.SoftBreak == SoftBreakJoin || r.options.JoinSentenceLines {
+			return " "
+		}
+		return "\n"
+	}
+	return ""
+}
@@ -0,0 +1,49 @@
+package converter
+
+import "testing"
+
+func TestLineBreaks(t *testing.T) {
+	tests := []struct {
+		name     string
+		markdown string
+		hard     HardBreak
+		soft     SoftBreak
+		want     string
+	}{
+		{"backslash", "a  \nb\nc", HardBreakBackslash, SoftBreakPreserve, "a\\\\\nb\nc"},
+		{"backslash escape", "a\\\nb", HardBreakBackslash, SoftBreakPreserve, "a\\\\\nb"},
+		{"blank line", "a  \nb\nc", HardBreakBlankLine, SoftBreakPreserve, "a\n\nb\nc"},
+		{"join", "a  \nb\nc", HardBreakBackslash, SoftBreakJoin, "a\\\\\nb c"},
+		{"blank line and join", "a  \nb\nc", HardBreakBlankLine, SoftBreakJoin, "a\n\nb c"},
+		{"list items keep backslashes", "- x  \n  y", HardBreakBlankLine, SoftBreakPreserve, "* x\\\\\ny"},
+		{"quote", "> q  \n> r", HardBreakBlankLine, SoftBreakPreserve, "{quote}\nq\n\nr\n\n{quote}"},
+		{"nested quote keeps backslashes", "> > q  \n> > r", HardBreakBlankLine, SoftBreakPreserve, "{quote}\nbq. q\\\\\nr\n\n{quote}"},
+		{"table cell", "| h |\n|---|\n| a<br>b |", HardBreakBlankLine, SoftBreakPreserve, "||h||\n|a\\\\b|"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := render(t, tt.markdown, Options{HardBreak: tt.hard, SoftBreak: tt.soft}); got != tt.want {
+				t.Errorf("got %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseBreaks(t *testing.T) {
+	for _, b := range []HardBreak{HardBreakBackslash, HardBreakBlankLine} {
+		if got, err := ParseHardBreak(b.String()); err != nil || got != b {
+			t.Errorf("ParseHardBreak(%q) = %v, %v", b.String(), got, err)
+		}
+	}
+	for _, b := range []SoftBreak{SoftBreakPreserve, SoftBreakJoin} {
+		if got, err := ParseSoftBreak(b.String()); err != nil || got != b {
+			t.Errorf("ParseSoftBreak(%q) = %v, %v", b.String(), got, err)
+		}
+	}
+	if _, err := ParseHardBreak("newline"); err == nil {
+		t.Error("ParseHardBreak(newline) succeeded")
+	}
+	if _, err := ParseSoftBreak("wrap"); err == nil {
+		t.Error("ParseSoftBreak(wrap) succeeded")
+	}
+}
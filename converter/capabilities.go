@@ -0,0 +1,223 @@
+// Target capabilities
+// Reports the constructs of a document that a Jira product cannot display as
+// written, and what it shows instead
+
+package converter
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/yuin/goldmark/ast"
+	east "github.com/yuin/goldmark/extension/ast"
+)
+
+// Target is a Jira product that displays converted markup
+type Target int
+
+const (
+	// TargetDataCenter is Jira Server or Data Center
+	TargetDataCenter Target = iota
+	// TargetCloud is Jira Cloud, which converts wiki markup to ADF
+	TargetCloud
+	// TargetServiceManagement is the Jira Service Management customer portal
+	TargetServiceManagement
+)
+
+// targetNames maps targets to their CLI names
+var targetNames = map[Target]string{
+	TargetDataCenter:        "dc",
+	TargetCloud:             "cloud",
+	TargetServiceManagement: "jsm",
+}
+
+// String returns the CLI name of the target
+func (t Target) String() string {
+	if name, ok := targetNames[t]; ok {
+		return name
+	}
+	return fmt.Sprintf("Target(%d)", int(t))
+}
+
+// ParseTarget parses a target name (dc, cloud or jsm)
+func ParseTarget(name string) (Target, error) {
+	for target, targetName := range targetNames {
+		if strings.EqualFold(name, targetName) {
+			return target, nil
+		}
+	}
+	return TargetDataCenter, fmt.Errorf("unknown target %q (want dc, cloud or jsm)", name)
+}
+
+// targetDialects are the dialects the markup of each target is rendered in
+var targetDialects = map[Target]Dialect{
+	TargetDataCenter:        DialectDataCenter,
+	TargetCloud:             DialectCloud,
+	TargetServiceManagement: DialectServer,
+}
+
+// Degradation is a construct that a target cannot display as written
+type Degradation struct {
+	Line   int `json:"line"`
+	Column int `json:"column"`
+	// Construct is the degraded construct, such as table or callout
+	Construct string `json:"construct"`
+	// Fallback is what the target displays instead, such as noformat or quote
+	Fallback string `json:"fallback"`
+	// Reason explains why the construct degrades
+	Reason string `json:"reason"`
+}
+
+// String formats the degradation as "line:column: construct → fallback: reason"
+func (d Degradation) String() string {
+	return fmt.Sprintf("%d:%d: %s → %s: %s", d.Line, d.Column, d.Construct, d.Fallback, d.Reason)
+}
+
+// degradation is how a target displays a construct it cannot render
+type degradation struct {
+	fallback string
+	reason   string
+}
+
+// targetDegradations are the constructs each target cannot render
+var targetDegradations = map[Target]map[string]degradation{
+	TargetDataCenter: {
+		"callout": {"panel", "there are no {info}, {tip}, {note} and {warning} macros; callouts become colored panels"},
+		"task":    {"emoticon", "checkboxes become (/) and ( ) emoticons"},
+	},
+	TargetCloud: {},
+	TargetServiceManagement: {
+		"table":    {"noformat", "the customer portal shows tables as preformatted text"},
+		"callout":  {"quote", "the customer portal renders panels and callouts as quotes"},
+		"expand":   {"plain text", "the customer portal does not collapse sections"},
+		"mention":  {"plain text", "the customer portal does not render user mentions"},
+		"task":     {"plain text", "the customer portal shows checkbox emoticons as (/) and ( )"},
+		"footnote": {"plain text", "anchor links do not work in the customer portal"},
+	},
+}
+
+// Degradations reports, in document order, the constructs of a Markdown
+// document that target cannot display as written. The options are those of
+// the conversion; the dialect is the one of the target.
+func Degradations(markdown string, target Target, opts Options) ([]Degradation, error) {
+	rules, ok := targetDegradations[target]
+	if !ok {
+		return nil, fmt.Errorf("unknown target %d", int(target))
+	}
+	if err := checkCompatLevel(opts.CompatLevel); err != nil {
+		return nil, err
+	}
+	opts.Dialect = targetDialects[target]
+	source := normalizeSource([]byte(markdown), opts)
+	doc := parseMarkdown(source, opts)
+	profile := dialectProfiles[opts.Dialect]
+
+	var found []Degradation
+	add := func(node ast.Node, construct string, d degradation) {
+		line, column := constructPosition(source, node)
+		found = append(found, Degradation{Line: line, Column: column, Construct: construct, Fallback: d.fallback, Reason: d.reason})
+	}
+	addRule := func(node ast.Node, construct string) {
+		if d, ok := rules[construct]; ok {
+			add(node, construct, d)
+		}
+	}
+
+	_ = ast.Walk(doc, func(node ast.Node, entering bool) (ast.WalkStatus, error) {
+		if !entering {
+			return ast.WalkContinue, nil
+		}
+		switch n := node.(type) {
+		case *east.Table:
+			addRule(n, "table")
+		case *admonition:
+			addRule(n, "callout")
+		case *details:
+			if d, ok := rules["expand"]; ok {
+				add(n, "expand", d)
+			} else if nestedDetails(n) || opts.Runbook {
+				add(n, "expand", degradation{"bold title", "sections inside another section cannot collapse"})
+			}
+		case *east.TaskCheckBox:
+			addRule(n, "task")
+		case *east.FootnoteList:
+			addRule(n, "footnote")
+		case *mathBlock:
+			if opts.MathMacro == "" {
+				add(n, "math", degradation{"noformat", "no math macro is set"})
+			}
+		case *mathInline:
+			if opts.MathMacro == "" {
+				add(n, "math", degradation{"monospace", "no math macro is set"})
+			}
+		case *ast.FencedCodeBlock:
+			lang := strings.TrimSpace(string(n.Language(source)))
+			if mode, _, ok := opts.diagramFence(lang); ok {
+				if mode == DiagramCode {
+					add(n, "diagram", degradation{"code", lang + " diagrams are shown as their source"})
+				}
+				return ast.WalkContinue, nil
+			}
+			if opts.isMathFence(lang) {
+				if opts.MathMacro == "" {
+					add(n, "math", degradation{"noformat", "no math macro is set"})
+				}
+				return ast.WalkContinue, nil
+			}
+			jiraLang := mapLanguage(lang, opts.LanguageMap)
+			if jiraLang != "" && jiraLang != "none" && profile.codeLanguages != nil && !profile.codeLanguages[jiraLang] {
+				add(n, "code", degradation{"plain code", jiraLang + " is not highlighted"})
+			}
+		case *ast.Text:
+			if _, code := n.Parent().(*ast.CodeSpan); code {
+				return ast.WalkContinue, nil
+			}
+			value := n.Segment.Value(source)
+			for _, m := range mentionRe.FindAllSubmatchIndex(value, -1) {
+				if d, ok := mentionDegradation(target, rules, opts, string(value[m[4]:m[5]])); ok {
+					line, column := offsetPosition(source, n.Segment.Start+m[4]-1)
+					found = append(found, Degradation{Line: line, Column: column, Construct: "mention", Fallback: d.fallback, Reason: d.reason})
+				}
+			}
+		}
+		return ast.WalkContinue, nil
+	})
+	return found, nil
+}
+
+// mentionDegradation returns how target displays an @handle, and false if it
+// renders as a mention
+func mentionDegradation(target Target, rules map[string]degradation, opts Options, handle string) (degradation, bool) {
+	if d, ok := rules["mention"]; ok {
+		return d, true
+	}
+	user, mapped := opts.Mentions[strings.ToLower(handle)]
+	switch {
+	case !opts.MeetingNotes || !mapped:
+		return degradation{"plain text", "@" + handle + " is not mapped to a JIRA user"}, true
+	case target == TargetCloud && !strings.HasPrefix(user, "accountid:"):
+		return degradation{"plain text", "Cloud mentions need an account ID; map @" + handle + " to accountid:..."}, true
+	}
+	return degradation{}, false
+}
+
+// nestedDetails reports whether a collapsible section is inside another one
+func nestedDetails(n *details) bool {
+	for p := n.Parent(); p != nil; p = p.Parent() {
+		if _, ok := p.(*details); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// constructPosition returns the position of a node, or of its first
+// descendant with one, as tables have no source lines of their own
+func constructPosition(source []byte, node ast.Node) (int, int) {
+	for n := node; n != nil; n = n.FirstChild() {
+		if offset := nodeOffset(n); offset >= 0 {
+			return offsetPosition(source, offset)
+		}
+	}
+	return nodePosition(source, node)
+}
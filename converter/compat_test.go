@@ -0,0 +1,203 @@
+package converter
+
+import (
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+func TestCompatLevels(t *testing.T) {
+	tests := []struct {
+		name     string
+		markdown string
+		level    CompatLevel
+		mode     EscapeMode
+		want     string
+	}{
+		{
+			name:     "details before Compat2",
+			markdown: "<details><summary>More</summary>\n\nHidden\n\n</details>",
+			level:    Compat1,
+			want:     "More\nHidden",
+		},
+		{
+			name:     "inserted text before Compat4",
+			markdown: "++new++",
+			level:    Compat3,
+			want:     "++new++",
+		},
+		{
+			name:     "inserted text at Compat4",
+			markdown: "++new++",
+			level:    Compat4,
+			want:     "+new+",
+		},
+		{
+			name:     "toc marker before Compat7",
+			markdown: "[TOC]",
+			level:    Compat6,
+			want:     `\[TOC]`,
+		},
+		{
+			name:     "toc marker at Compat7",
+			markdown: "[TOC]",
+			level:    Compat7,
+			want:     "{toc}",
+		},
+		{
+			name:     "aggressive issue keys before Compat12",
+			markdown: "PROJ-12 a-b",
+			level:    Compat11,
+			mode:     EscapeAggressive,
+			want:     `PROJ\-12 a\-b`,
+		},
+		{
+			name:     "aggressive issue keys at Compat12",
+			markdown: "PROJ-12 a-b",
+			level:    Compat12,
+			mode:     EscapeAggressive,
+			want:     `PROJ-12 a\-b`,
+		},
+		{
+			name:     "table cell lists before Compat12",
+			markdown: "| h |\n|---|\n| <ul><li>1</li><li>2</li></ul> |",
+			level:    Compat11,
+			want:     "||h||\n|12|",
+		},
+		{
+			name:     "table cell lists at Compat12",
+			markdown: "| h |\n|---|\n| <ul><li>1</li><li>2</li></ul> |",
+			level:    Compat12,
+			want:     "||h||\n|- 1\\\\- 2|",
+		},
+		{
+			name:     "nested blockquotes before Compat12",
+			markdown: "> > nested\n>\n> back",
+			level:    Compat11,
+			want:     "{quote}\n{quote}\nnested\n\n{quote}\n\nback\n\n{quote}",
+		},
+		{
+			name:     "lone CR line endings before Compat12",
+			markdown: "a\rb",
+			level:    Compat11,
+			want:     "a\rb",
+		},
+		{
+			name:     "lone CR line endings at Compat12",
+			markdown: "a\rb",
+			level:    Compat12,
+			want:     "a\nb",
+		},
+		{
+			name:     "byte order mark before Compat12",
+			markdown: "\uFEFF# T",
+			level:    Compat11,
+			want:     "\uFEFF# T",
+		},
+		{
+			name:     "line-start markers before Compat12",
+			markdown: "h1. literal\n\n\\| pipe",
+			level:    Compat11,
+			want:     "h1. literal\n\n\\| pipe",
+		},
+		{
+			name:     "line-start markers at Compat12",
+			markdown: "h1. literal\n\n| pipe",
+			level:    Compat12,
+			want:     "h1\\. literal\n\n\\| pipe",
+		},
+		{
+			name:     "underscores before Compat12",
+			markdown: "snake_case and _open",
+			level:    Compat11,
+			want:     "snake_case and _open",
+		},
+		{
+			name:     "underscores at Compat12",
+			markdown: "snake_case and _open",
+			level:    Compat12,
+			want:     "snake\\_case and \\_open",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := render(t, tt.markdown, Options{CompatLevel: tt.level, EscapeMode: tt.mode}); got != tt.want {
+				t.Errorf("got %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+// TestCompat1Golden renders the documents in testdata at Compat1 and compares
+// the output with that of md2jira 1.0, stored next to them, byte for byte;
+// changes to it belong behind a new level
+func TestCompat1Golden(t *testing.T) {
+	for _, name := range []string{"compat1", "compat1_bytes"} {
+		t.Run(name, func(t *testing.T) {
+			markdown := readTestdata(t, name+".md")
+			opts := Options{CompatLevel: Compat1}
+
+			result, err := ConvertWithOptions(markdown, opts)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if want := strings.TrimSuffix(readTestdata(t, name+".jira"), "\n"); result.Output != want {
+				t.Errorf("wiki output differs from %s.jira:\n%s", name, firstDifference(result.Output, want))
+			}
+
+			adf, err := ConvertToADFWithOptions(markdown, opts)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if want := strings.TrimSuffix(readTestdata(t, name+".json"), "\n"); adf.Output != want {
+				t.Errorf("ADF output differs from %s.json:\n%s", name, firstDifference(adf.Output, want))
+			}
+		})
+	}
+}
+
+// readTestdata returns the content of a file in testdata
+func readTestdata(t *testing.T, name string) string {
+	t.Helper()
+	data, err := os.ReadFile(filepath.Join("testdata", name))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(data)
+}
+
+// firstDifference shows got and want from a little before the first byte
+// in which they differ
+func firstDifference(got, want string) string {
+	i := 0
+	for i < len(got) && i < len(want) && got[i] == want[i] {
+		i++
+	}
+	start := max(i-40, 0)
+	return "got  " + strconv.Quote(got[start:min(i+40, len(got))]) + "\nwant " + strconv.Quote(want[start:min(i+40, len(want))])
+}
+
+func TestParseCompatLevel(t *testing.T) {
+	tests := []struct {
+		in      string
+		want    CompatLevel
+		wantErr bool
+	}{
+		{"latest", CompatLatest, false},
+		{"LATEST", CompatLatest, false},
+		{"1", Compat1, false},
+		{"12", Compat12, false},
+		{"0", CompatLatest, false},
+		{"13", CompatLevel(13), true},
+		{"-1", CompatLevel(-1), true},
+		{"one", CompatLatest, true},
+	}
+	for _, tt := range tests {
+		got, err := ParseCompatLevel(tt.in)
+		if got != tt.want || (err != nil) != tt.wantErr {
+			t.Errorf("ParseCompatLevel(%q) = %v, %v; want %v, error %v", tt.in, got, err, tt.want, tt.wantErr)
+		}
+	}
+}
@@ -0,0 +1,446 @@
+// Package converter converts Markdown-formatted text into JIRA Text Formatting Notation
+package converter
+
+import (
+	"io"
+	"net/http"
+	"os"
+	"regexp"
+	"strings"
+	"text/template"
+	"time"
+
+	"github.com/yuin/goldmark"
+	"github.com/yuin/goldmark/ast"
+	"github.com/yuin/goldmark/extension"
+	"github.com/yuin/goldmark/parser"
+	"github.com/yuin/goldmark/text"
+	"github.com/yuin/goldmark/util"
+)
+
+// Version information
+const Version = "1.0.0"
+
+// Options holds conversion options
+type Options struct {
+	PreserveHTML      bool
+	WarnOnUnsupported bool
+	Verbose           bool
+	// ImageThumbnail renders images as thumbnails (!url|thumbnail!)
+	ImageThumbnail bool
+	// ImageWidth sets an explicit image width in pixels (0 = original size)
+	ImageWidth int
+	// CheckLinks reports relative links whose targets do not exist on disk
+	CheckLinks bool
+	// CheckRemoteLinks additionally sends HEAD requests to absolute URLs
+	CheckRemoteLinks bool
+	// HTTPTransport sends the remote link checks (default
+	// http.DefaultTransport), e.g. through a proxy or with a custom CA
+	HTTPTransport http.RoundTripper
+	// BaseDir is the directory relative links are resolved against
+	BaseDir string
+	// AttachLocalImages renders images of local files as references to
+	// attachments named after the files (!arch.png!) and lists the files in
+	// Result.Attachments, to be uploaded to the issue; in safe mode only files
+	// under BaseDir are attached. ADF output is not affected.
+	AttachLocalImages bool
+	// SpellChecker, when set, reports misspelled words as warnings
+	SpellChecker SpellChecker
+	// JoinSentenceLines joins "one sentence per line" paragraphs into single
+	// lines, as SoftBreakJoin does
+	JoinSentenceLines bool
+	// HardBreak selects how hard line breaks are written: as \\ (default) or
+	// a blank line
+	HardBreak HardBreak
+	// SoftBreak selects how the newlines inside a paragraph are written:
+	// kept (default) or joined into one line
+	SoftBreak SoftBreak
+	// MediaMacro, when set, renders <video>/<audio>/<iframe> as {MediaMacro:url=...}
+	// instead of a labeled link (e.g. "widget" or "multimedia")
+	MediaMacro string
+	// InlineFootnotes renders footnote content in parentheses at the reference site
+	InlineFootnotes bool
+	// InlineFootnoteMaxLen limits inlining to footnotes of at most this many
+	// characters (0 = no limit); longer footnotes stay in a trailing section
+	InlineFootnoteMaxLen int
+	// EscapeMode controls escaping of JIRA markup characters in text
+	EscapeMode EscapeMode
+	// EscapeStyle controls how escaped characters are neutralized: with a
+	// backslash (default), or an invisible separator after effect markers
+	EscapeStyle EscapeStyle
+	// LinkStyle controls whether links are inline or numbered endnotes
+	LinkStyle LinkStyle
+	// EnrichLinks gives bare links to known systems (GitHub, GitLab, Confluence,
+	// Google Docs, JIRA) a readable title derived from the URL
+	EnrichLinks bool
+	// LinkTitler, when set, provides bare link titles instead of the built-in derivation
+	LinkTitler LinkTitler
+	// DocumentLinks maps the absolute paths of documents to the URLs of the
+	// issues they were pushed to; links to those documents, resolved against
+	// BaseDir, become links to the issues, keeping their #fragment
+	DocumentLinks map[string]string
+	// NormalizePunctuation normalizes the spaces and punctuation of
+	// non-English text next to emphasis and links (no-break and ideographic
+	// spaces, full-width colons, guillemets), which JIRA does not recognize as
+	// boundaries of its markup
+	NormalizePunctuation bool
+	// SafeMode neutralizes untrusted input for services that convert
+	// user-submitted content: javascript:, vbscript: and data: links and
+	// images are dropped, raw HTML is always sanitized and converted, text is
+	// always escaped, media embeds become links, and content that would close
+	// a {code} or {noformat} block early is broken up
+	SafeMode bool
+	// Emoticons translates common Unicode emoji (✅, ⚠️, ❌, 👍, ...) into JIRA
+	// emoticons ((/), (!), (x), (y), ...), which render in every font
+	Emoticons bool
+	// Symbols selects how emoji and checkboxes are written: as emoticons
+	// (default), plain-text labels for instances that disable graphical
+	// emoticons, or the original characters
+	Symbols SymbolSet
+	// TaskStyle selects how checkboxes are written, overriding Symbols: as
+	// emoticons, [x] and [ ], ☑ and ☐, or not at all
+	TaskStyle TaskStyle
+	// PreserveSpacers renders &nbsp;-only and <p><br></p> spacer paragraphs as
+	// forced line breaks instead of collapsing them into blank lines
+	PreserveSpacers bool
+	// HTMLSanitizer, when set, cleans raw HTML before it is converted or
+	// preserved (see NewBasicSanitizer; a bluemonday policy also works)
+	HTMLSanitizer HTMLSanitizer
+	// ProvenanceTrailer appends a line with the md2jira version and the SHA-256
+	// of the source and of the output, which ParseProvenance reads back
+	ProvenanceTrailer bool
+	// LanguageMap overrides the mapping of code block languages to JIRA
+	// {code} languages; keys are lower-case Markdown language names
+	LanguageMap map[string]string
+	// DetectTraces renders paragraphs that look like stack traces or compiler
+	// output as {noformat} blocks instead of escaped prose
+	DetectTraces bool
+	// CollapseCodeOver collapses code blocks longer than this many lines
+	// (0 = never)
+	CollapseCodeOver int
+	// JQLBaseURL, when set, renders jql fences as links to the issue navigator
+	// of this JIRA site instead of {jql} macros
+	JQLBaseURL string
+	// IssueBaseURL, when set, renders bare issue keys such as PROJ-123 as
+	// links to the issues on this JIRA site
+	IssueBaseURL string
+	// IssueProjects limits IssueBaseURL to the keys of these projects, so
+	// terms such as UTF-8 are not linked
+	IssueProjects []string
+	// RoadmapTemplate, when set, renders tables with task, start and end (and
+	// optionally owner) columns through this template, which is executed with
+	// RoadmapData; see DefaultRoadmapTemplate
+	RoadmapTemplate *template.Template
+	// Dialect adapts code languages and checkboxes to the Jira deployment
+	Dialect Dialect
+	// Runbook renders a procedure document as a runbook: top-level ordered list
+	// items become steps numbered across the whole document, each with a ( )
+	// checkpoint, and every ## section is folded into an {expand} phase
+	Runbook bool
+	// TimelineTables renders bullet lists of "12:03 UTC — event" entries as a
+	// Time | Event table
+	TimelineTables bool
+	// TimelineZone, when set, converts timeline times into this zone; entries
+	// without a zone take the zone of the entry before them
+	TimelineZone *time.Location
+	// MeetingNotes renders the lists of "Action items" sections as checkbox
+	// lines, with @mentions and "by Friday" due dates converted, and reports
+	// them in Result.ActionItems
+	MeetingNotes bool
+	// Mentions maps lower-case @handles to JIRA user names (or accountid:...)
+	Mentions map[string]string
+	// ConvertMentions renders the @handles of all text, not only of action
+	// items, as mentions of the users Mentions maps them to; unmapped handles
+	// are left as text with a warning
+	ConvertMentions bool
+	// MeetingDate is the date relative due dates are resolved against (default today)
+	MeetingDate time.Time
+	// Header and Footer, when set, are executed with TemplateData and the
+	// resulting Markdown is converted and placed before and after the document
+	Header *template.Template
+	Footer *template.Template
+	// SourcePath is the path of the converted file, for the templates
+	SourcePath string
+	// Mermaid selects how mermaid fences are rendered: as code (default), as
+	// the MermaidMacro macro, or as an image drawn by DiagramRenderer
+	Mermaid DiagramMode
+	// MermaidMacro is the macro of the mermaid plugin (default mermaid)
+	MermaidMacro string
+	// PlantUML selects how plantuml and puml fences are rendered, like Mermaid
+	PlantUML DiagramMode
+	// PlantUMLMacro is the macro of the PlantUML plugin (default plantuml)
+	PlantUMLMacro string
+	// DiagramRenderer renders diagrams in DiagramImage mode; see KrokiRenderer
+	// and MermaidCLIRenderer
+	DiagramRenderer DiagramRenderer
+	// CompatLevel pins the rendering behavior of an md2jira release; the zero
+	// value follows the latest behavior
+	CompatLevel CompatLevel
+	// MathMacro, when set, renders $inline$ and $$display$$ math with this
+	// macro (e.g. mathjax or latex); without it math becomes {noformat} blocks
+	// and monospace
+	MathMacro string
+	// AltText, when set, generates the alt text of images written without one
+	AltText AltTextGenerator
+	// Directives choose how raw HTML blocks and large tables are rendered; they
+	// are keyed by the Key of the warnings about them
+	Directives Directives
+	// Cache, when set, keeps remote link checks and rendered diagrams across
+	// conversions
+	Cache Cache
+	// Metrics, when set, receives conversion timings and counters
+	Metrics MetricsSink
+	// ProfileBlocks records the rendering time and output size of each
+	// block in Result.Profile
+	ProfileBlocks bool
+	// Logger, when set, receives diagnostic messages
+	Logger Logger
+	// HeadingOffset is added to the level of every heading, so that with 1
+	// the H1 of a document becomes h2.
+	HeadingOffset int
+	// StripTitle removes the first H1 of the document and returns its text
+	// as the title in Result.Metadata, unless the front matter has a title
+	StripTitle bool
+	// TOC starts the document with a {toc} macro unless it has a [TOC],
+	// [[toc]] or <!-- toc --> marker, which always becomes one
+	TOC bool
+	// TOCMinLevel and TOCMaxLevel limit {toc} macros to the headings of these
+	// levels (0 = no limit)
+	TOCMinLevel int
+	TOCMaxLevel int
+	// ASTTransformers are run, in order, on the parsed document after the
+	// built-in transformers, e.g. to drop sections or rewrite link
+	// destinations before rendering
+	ASTTransformers []parser.ASTTransformer
+}
+
+// Result holds conversion result with warnings
+type Result struct {
+	Output   string
+	Warnings []Warning
+	Stats    Stats
+	// ActionItems are the action items found in meeting notes mode
+	ActionItems []ActionItem
+	// Metadata is the YAML or TOML front matter of the document, which is
+	// not converted; nil if there is none
+	Metadata map[string]any
+	// Profile is the rendering cost of each block with Options.ProfileBlocks
+	Profile *Profile
+	// Attachments are the local images to upload with Options.AttachLocalImages
+	Attachments []Attachment
+}
+
+// Convert converts Markdown to JIRA markup
+func Convert(markdown string) string {
+	result, _ := ConvertWithOptions(markdown, Options{})
+	return result.Output
+}
+
+// parseMarkdown parses Markdown source into a goldmark AST
+func parseMarkdown(source []byte, opts Options) ast.Node {
+	extensions := []goldmark.Extender{
+		extension.GFM, // GitHub Flavored Markdown (tables, strikethrough, etc.)
+	}
+	parserOptions := []parser.Option{
+		parser.WithAutoHeadingID(),
+	}
+	if opts.compat(Compat12) {
+		parserOptions = append(parserOptions, parser.WithASTTransformers(util.Prioritized(&textMerger{}, 100)))
+	}
+	if opts.compat(Compat2) {
+		extensions = append(extensions, extension.Footnote)
+		parserOptions = append(parserOptions,
+			parser.WithBlockParsers(util.Prioritized(&admonitionParser{}, 750)),
+			parser.WithASTTransformers(
+				util.Prioritized(&alertTransformer{}, 100),
+				util.Prioritized(&detailsTransformer{}, 100),
+			),
+		)
+	} else if opts.InlineFootnotes {
+		extensions = append(extensions, extension.Footnote)
+	}
+	if opts.compat(Compat3) {
+		parserOptions = append(parserOptions,
+			parser.WithBlockParsers(util.Prioritized(&mathBlockParser{}, 760)),
+			parser.WithInlineParsers(util.Prioritized(&mathInlineParser{}, 150)),
+		)
+	}
+	if opts.compat(Compat4) {
+		parserOptions = append(parserOptions,
+			parser.WithInlineParsers(util.Prioritized(&insertedParser{}, 500)),
+		)
+	}
+	if opts.StripTitle {
+		parserOptions = append(parserOptions, parser.WithASTTransformers(
+			util.Prioritized(&titleTransformer{}, 100),
+		))
+	}
+	if opts.compat(Compat7) || opts.TOC {
+		parserOptions = append(parserOptions, parser.WithASTTransformers(
+			util.Prioritized(&tocTransformer{markers: opts.compat(Compat7), prepend: opts.TOC}, 100),
+		))
+	}
+	if len(opts.ASTTransformers) > 0 {
+		// Transformers run from the lowest priority value; these come after
+		// the built-in and extension ones (the footnote transformer is 999)
+		transformers := make([]util.PrioritizedValue, len(opts.ASTTransformers))
+		for i, t := range opts.ASTTransformers {
+			transformers[i] = util.Prioritized(t, 1000+i)
+		}
+		parserOptions = append(parserOptions, parser.WithASTTransformers(transformers...))
+	}
+
+	// Create goldmark parser with extensions
+	md := goldmark.New(
+		goldmark.WithExtensions(extensions...),
+		goldmark.WithParserOptions(parserOptions...),
+	)
+
+	reader := text.NewReader(stripFrontMatter(source, opts))
+	return md.Parser().Parse(reader)
+}
+
+// ConvertWithOptions converts Markdown to JIRA markup with options
+func ConvertWithOptions(markdown string, opts Options) (Result, error) {
+	// Parse the markdown
+	start := time.Now()
+	source := normalizeSource([]byte(markdown), opts)
+	if err := checkCompatLevel(opts.CompatLevel); err != nil {
+		return Result{}, err
+	}
+	header, footer, err := executeTemplates(opts)
+	if err != nil {
+		return Result{}, err
+	}
+	parseStart := time.Now()
+	doc := parseMarkdown(source, opts)
+	parsed := time.Since(parseStart)
+
+	// Create renderer and render
+	renderer := NewJIRARenderer(source, opts)
+	output := renderer.Render(doc)
+
+	// Clean up output
+	output = cleanOutput(output)
+	output = joinBlocks(templateMarkup(header, opts), output, templateMarkup(footer, opts))
+	if opts.ProvenanceTrailer {
+		// The hash is of the file as written, whatever its line endings
+		provenance := NewProvenance([]byte(markdown))
+		if opts.compat(Compat12) {
+			provenance = provenance.WithOutput(output)
+		}
+		output += "\n\n" + provenance.Trailer()
+	}
+
+	warnings := renderer.GetWarnings()
+	if opts.CheckLinks {
+		warnings = append(warnings, newLinkChecker(opts).Check(doc, source)...)
+	}
+	if opts.SpellChecker != nil {
+		warnings = append(warnings, spellcheck(doc, source, opts.SpellChecker)...)
+	}
+
+	result := Result{
+		Output:      output,
+		Warnings:    warnings,
+		Stats:       collectStats(doc, source, output, warnings, renderer.unknown),
+		ActionItems: collectActionItems(doc, source, opts),
+		Metadata:    metadata(doc, source, opts),
+		Profile:     renderer.profiler.profile(parsed),
+		Attachments: renderer.attachments.list,
+	}
+	opts.observeConversion("jira", start, len(result.Output), result.Warnings)
+	return result, nil
+}
+
+// cleanOutput cleans up the output
+func cleanOutput(output string) string {
+	// Remove excessive blank lines (more than 2 consecutive)
+	blankLineRe := regexp.MustCompile(`\n{3,}`)
+	output = blankLineRe.ReplaceAllString(output, "\n\n")
+
+	// Trim trailing whitespace from each line
+	lines := strings.Split(output, "\n")
+	for i, line := range lines {
+		lines[i] = strings.TrimRight(line, " \t")
+	}
+	output = strings.Join(lines, "\n")
+
+	// Trim leading and trailing whitespace from the whole output
+	output = strings.TrimSpace(output)
+
+	return output
+}
+
+// Converter provides the conversion API
+type Converter struct {
+	options Options
+}
+
+// NewConverter creates a new converter with default options
+func NewConverter() *Converter {
+	return &Converter{}
+}
+
+// NewConverterWithOptions creates a new converter with specified options
+func NewConverterWithOptions(opts Options) *Converter {
+	return &Converter{options: opts}
+}
+
+// Convert converts Markdown to JIRA markup
+func (c *Converter) Convert(markdown string) string {
+	result, _ := ConvertWithOptions(markdown, c.options)
+	return result.Output
+}
+
+// ConvertWithWarnings converts Markdown and returns warnings
+func (c *Converter) ConvertWithWarnings(markdown string) (string, []Warning) {
+	result, _ := ConvertWithOptions(markdown, c.options)
+	return result.Output, result.Warnings
+}
+
+// ConvertBytes converts Markdown bytes to JIRA markup bytes
+func (c *Converter) ConvertBytes(markdown []byte) []byte {
+	result, _ := ConvertWithOptions(string(markdown), c.options)
+	return []byte(result.Output)
+}
+
+// ConvertReader converts from a reader to a writer
+func (c *Converter) ConvertReader(r io.Reader, w io.Writer) error {
+	input, err := io.ReadAll(r)
+	if err == nil {
+		input, err = DecodeSource(input)
+	}
+	if err != nil {
+		return err
+	}
+	result, _ := ConvertWithOptions(string(input), c.options)
+	_, err = w.Write([]byte(result.Output))
+	return err
+}
+
+// MustConvert converts Markdown to JIRA markup, panicking on error
+func MustConvert(markdown string) string {
+	return Convert(markdown)
+}
+
+// ConvertFile converts a file and returns the result
+func ConvertFile(inputPath string) (string, error) {
+	input, err := os.ReadFile(inputPath)
+	if err == nil {
+		input, err = DecodeSource(input)
+	}
+	if err != nil {
+		return "", err
+	}
+	return Convert(string(input)), nil
+}
+
+// ConvertFileToFile converts an input file to an output file
+func ConvertFileToFile(inputPath, outputPath string) error {
+	output, err := ConvertFile(inputPath)
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(outputPath, []byte(output), 0644)
+}
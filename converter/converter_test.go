@@ -0,0 +1,13 @@
+package converter
+
+import "testing"
+
+// render converts markdown to JIRA markup, failing the test on errors
+func render(t *testing.T, markdown string, opts Options) string {
+	t.Helper()
+	result, err := ConvertWithOptions(markdown, opts)
+	if err != nil {
+		t.Fatalf("ConvertWithOptions(%q): %v", markdown, err)
+	}
+	return result.Output
+}
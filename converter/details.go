@@ -0,0 +1,182 @@
+// Collapsible sections
+// Turns HTML <details><summary> blocks into {expand} macros, with the
+// Markdown between the tags converted as usual
+
+package converter
+
+import (
+	"regexp"
+	"strings"
+
+	"github.com/astsu-dev/md2jira/jiraescape"
+	"github.com/yuin/goldmark/ast"
+	"github.com/yuin/goldmark/parser"
+	"github.com/yuin/goldmark/text"
+)
+
+// kindDetails is the node kind of collapsible sections
+var kindDetails = ast.NewNodeKind("Details")
+
+// details is a <details> element whose content is parsed as Markdown
+type details struct {
+	ast.BaseBlock
+	// Summary is the plain text of the <summary> element
+	Summary string
+}
+
+// Kind implements ast.Node
+func (n *details) Kind() ast.NodeKind {
+	return kindDetails
+}
+
+// Dump implements ast.Node
+func (n *details) Dump(source []byte, level int) {
+	ast.DumpHelper(n, source, level, map[string]string{"Summary": n.Summary}, nil)
+}
+
+// detailsOpenRe matches the start of an HTML block opening a <details>
+// element, with its optional <summary>
+var detailsOpenRe = regexp.MustCompile(`(?is)^\s*<details\b[^>]*>\s*(?:<summary\b[^>]*>(.*?)</summary\s*>)?`)
+
+// detailsCloseRe matches a </details> closing tag
+var detailsCloseRe = regexp.MustCompile(`(?i)</details\s*>`)
+
+// detailsTransformer replaces <details> HTML blocks, and the blocks up to the
+// matching </details>, with details nodes
+type detailsTransformer struct{}
+
+// Transform implements parser.ASTTransformer
+func (t *detailsTransformer) Transform(doc *ast.Document, reader text.Reader, pc parser.Context) {
+	source := reader.Source()
+	var blocks []*ast.HTMLBlock
+	ast.Walk(doc, func(n ast.Node, entering bool) (ast.WalkStatus, error) {
+		if block, ok := n.(*ast.HTMLBlock); ok && entering {
+			blocks = append(blocks, block)
+		}
+		return ast.WalkContinue, nil
+	})
+	// Innermost sections first, so that the blocks of outer ones are still siblings
+	for i := len(blocks) - 1; i >= 0; i-- {
+		transformDetails(blocks[i], source)
+	}
+}
+
+// transformDetails replaces the <details> element opened by block, if any
+func transformDetails(block *ast.HTMLBlock, source []byte) {
+	html := htmlBlockText(block, source)
+	m := detailsOpenRe.FindStringSubmatchIndex(html)
+	if m == nil || block.Parent() == nil {
+		return
+	}
+	node := &details{Summary: "Details"}
+	if m[2] >= 0 {
+		if summary := strings.TrimSpace(decodeEntities(htmlTagRe.ReplaceAllString(html[m[2]:m[3]], ""))); summary != "" {
+			node.Summary = strings.Join(strings.Fields(summary), " ")
+		}
+	}
+
+	rest := html[m[1]:]
+	var end ast.Node
+	if loc := detailsCloseRe.FindStringIndex(rest); loc != nil {
+		// The whole element is one HTML block; its content stays HTML
+		if strings.TrimSpace(rest[loc[1]:]) != "" {
+			return
+		}
+		if strings.TrimSpace(rest[:loc[0]]) != "" {
+			node.AppendChild(node, htmlBlockPart(block, m[1], m[1]+loc[0]))
+		}
+	} else {
+		// Markdown follows the opening block up to a block closing the element;
+		// sections nested in it have already been replaced
+		for sibling := block.NextSibling(); sibling != nil && end == nil; sibling = sibling.NextSibling() {
+			if other, ok := sibling.(*ast.HTMLBlock); ok {
+				text := htmlBlockText(other, source)
+				if detailsCloseRe.MatchString(text) && strings.TrimSpace(detailsCloseRe.ReplaceAllString(text, "")) == "" {
+					end = other
+				}
+			}
+		}
+		if end == nil {
+			return
+		}
+		if strings.TrimSpace(rest) != "" {
+			node.AppendChild(node, htmlBlockPart(block, m[1], len(html)))
+		}
+		for sibling := block.NextSibling(); sibling != end; sibling = block.NextSibling() {
+			block.Parent().RemoveChild(block.Parent(), sibling)
+			node.AppendChild(node, sibling)
+		}
+		block.Parent().RemoveChild(block.Parent(), end)
+	}
+	node.SetBlankPreviousLines(block.HasBlankPreviousLines())
+	block.Parent().ReplaceChild(block.Parent(), block, node)
+}
+
+// htmlBlockText returns the source of an HTML block
+func htmlBlockText(block *ast.HTMLBlock, source []byte) string {
+	var html strings.Builder
+	lines := block.Lines()
+	for i := 0; i < lines.Len(); i++ {
+		segment := lines.At(i)
+		html.Write(segment.Value(source))
+	}
+	if block.HasClosure() {
+		html.Write(block.ClosureLine.Value(source))
+	}
+	return html.String()
+}
+
+// htmlBlockPart returns an HTML block of the text from offset from to offset
+// to of block's text, as returned by htmlBlockText
+func htmlBlockPart(block *ast.HTMLBlock, from, to int) *ast.HTMLBlock {
+	part := ast.NewHTMLBlock(block.HTMLBlockType)
+	segments := block.Lines().Sliced(0, block.Lines().Len())
+	if block.HasClosure() {
+		segments = append(segments, block.ClosureLine)
+	}
+	offset := 0
+	for _, segment := range segments {
+		start, stop := offset, offset+segment.Len()
+		offset = stop
+		if stop <= from || start >= to {
+			continue
+		}
+		if from > start {
+			segment = segment.WithStart(segment.Start + from - start)
+		}
+		if to < stop {
+			segment = segment.WithStop(segment.Stop - (stop - to))
+		}
+		part.Lines().Append(segment)
+	}
+	return part
+}
+
+// renderDetails renders a collapsible section as {expand}; JIRA cannot nest
+// expands, so sections inside another expand or a runbook phase become a
+// bold title followed by their content
+func (r *JIRARenderer) renderDetails(buf *strings.Builder, n *details, entering bool) {
+	title := jiraescape.MacroParam(n.Summary)
+	if entering {
+		if r.phaseOpen || r.expandDepth > 0 {
+			// Any * in the title would end the bold text
+			if r.options.compat(Compat12) {
+				title = jiraescape.EscapeWithRules(title, 0, jiraescape.Aggressive, r.options.EscapeStyle, r.options.escapeRules())
+			}
+			buf.WriteString("*" + title + "*\n\n")
+		} else {
+			buf.WriteString("{expand:" + title + "}\n")
+		}
+		r.expandDepth++
+		return
+	}
+	r.expandDepth--
+	if !strings.HasSuffix(buf.String(), "\n") {
+		buf.WriteString("\n")
+	}
+	if !r.phaseOpen && r.expandDepth == 0 {
+		buf.WriteString("{expand}\n\n")
+	} else {
+		buf.WriteString("\n")
+	}
+}
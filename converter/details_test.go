@@ -0,0 +1,41 @@
+package converter
+
+import "testing"
+
+func TestDetailsTitles(t *testing.T) {
+	tests := []struct {
+		name     string
+		markdown string
+		level    CompatLevel
+		want     string
+	}{
+		{
+			name:     "macro characters",
+			markdown: "<details><summary>a}b|c {x</summary>\n\nbody\n\n</details>",
+			want:     "{expand:ab c x}\nbody\n\n{expand}",
+		},
+		{
+			name:     "formatted summary",
+			markdown: "<details>\n<summary>Full <b>logs</b> | raw</summary>\n\nbody\n\n</details>",
+			want:     "{expand:Full logs raw}\nbody\n\n{expand}",
+		},
+		{
+			name:     "nested section",
+			markdown: "<details><summary>a</summary>\n\n<details><summary>b*c_d [x|y]</summary>\n\ninner\n\n</details>\n\n</details>",
+			want:     "{expand:a}\n*b\\*c\\_d \\[x y\\]*\n\ninner\n\n{expand}",
+		},
+		{
+			name:     "nested section before Compat12",
+			markdown: "<details><summary>a</summary>\n\n<details><summary>b*c</summary>\n\ninner\n\n</details>\n\n</details>",
+			level:    Compat11,
+			want:     "{expand:a}\n*b*c*\n\ninner\n\n{expand}",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := render(t, tt.markdown, Options{CompatLevel: tt.level}); got != tt.want {
+				t.Errorf("got %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
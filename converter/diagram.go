@@ -0,0 +1,237 @@
+// Diagram fences
+// Renders mermaid and PlantUML fences as code, as a diagram macro, or as an image
+
+package converter
+
+import (
+	"bytes"
+	"compress/zlib"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"github.com/yuin/goldmark/ast"
+)
+
+// DiagramMode selects how diagram fences are rendered
+type DiagramMode int
+
+const (
+	// DiagramCode renders diagrams as {code} blocks of their source
+	DiagramCode DiagramMode = iota
+	// DiagramMacro wraps the source in a diagram macro such as {mermaid}, for
+	// sites with the plugin installed
+	DiagramMacro
+	// DiagramImage renders the diagram with Options.DiagramRenderer and embeds
+	// the result as an image
+	DiagramImage
+)
+
+// diagramModeNames maps diagram modes to their CLI names
+var diagramModeNames = map[DiagramMode]string{
+	DiagramCode:  "code",
+	DiagramMacro: "macro",
+	DiagramImage: "image",
+}
+
+// String returns the CLI name of the diagram mode
+func (m DiagramMode) String() string {
+	if name, ok := diagramModeNames[m]; ok {
+		return name
+	}
+	return fmt.Sprintf("DiagramMode(%d)", int(m))
+}
+
+// ParseDiagramMode parses a diagram mode name (code, macro or image)
+func ParseDiagramMode(name string) (DiagramMode, error) {
+	for mode, modeName := range diagramModeNames {
+		if strings.EqualFold(name, modeName) {
+			return mode, nil
+		}
+	}
+	return DiagramCode, fmt.Errorf("unknown diagram mode %q (want code, macro or image)", name)
+}
+
+// DiagramRenderer renders diagram source to an image
+type DiagramRenderer interface {
+	// RenderDiagram renders source written in lang (e.g. mermaid) and returns
+	// the image reference to embed: an attachment file name or a URL
+	RenderDiagram(lang, source string) (string, error)
+}
+
+// KrokiRenderer renders diagrams as links to a Kroki server, which draws them
+// when the image is viewed; nothing is fetched during conversion
+type KrokiRenderer struct {
+	// BaseURL is the Kroki server (default https://kroki.io)
+	BaseURL string
+	// Format is the image format (default png)
+	Format string
+}
+
+// RenderDiagram implements DiagramRenderer
+func (k KrokiRenderer) RenderDiagram(lang, source string) (string, error) {
+	base := strings.TrimRight(k.BaseURL, "/")
+	if base == "" {
+		base = "https://kroki.io"
+	}
+	format := k.Format
+	if format == "" {
+		format = "png"
+	}
+	var compressed bytes.Buffer
+	w, err := zlib.NewWriterLevel(&compressed, zlib.BestCompression)
+	if err != nil {
+		return "", err
+	}
+	if _, err := w.Write([]byte(source)); err != nil {
+		return "", err
+	}
+	if err := w.Close(); err != nil {
+		return "", err
+	}
+	return base + "/" + lang + "/" + format + "/" + base64.URLEncoding.EncodeToString(compressed.Bytes()), nil
+}
+
+// MermaidCLIRenderer renders mermaid diagrams to PNG files with the mermaid
+// CLI (mmdc); the files must be attached to the issue for the images to show
+type MermaidCLIRenderer struct {
+	// Dir is where the images are written (default: the working directory)
+	Dir string
+	// Command is the mermaid CLI executable (default mmdc)
+	Command string
+}
+
+// RenderDiagram implements DiagramRenderer
+func (m MermaidCLIRenderer) RenderDiagram(lang, source string) (string, error) {
+	if lang != "mermaid" {
+		return "", fmt.Errorf("the mermaid CLI cannot render %s diagrams", lang)
+	}
+	command := m.Command
+	if command == "" {
+		command = "mmdc"
+	}
+	name := diagramFileName(source, ".png")
+	input, err := os.CreateTemp("", "md2jira-*.mmd")
+	if err != nil {
+		return "", err
+	}
+	defer os.Remove(input.Name())
+	if _, err := input.WriteString(source); err != nil {
+		input.Close()
+		return "", err
+	}
+	input.Close()
+	out, err := exec.Command(command, "--input", input.Name(), "--output", filepath.Join(m.Dir, name)).CombinedOutput()
+	if err != nil {
+		if msg := strings.TrimSpace(string(out)); msg != "" {
+			return "", fmt.Errorf("%s: %v: %s", command, err, msg)
+		}
+		return "", err
+	}
+	return name, nil
+}
+
+// diagramFileName names an image after the hash of its source, so unchanged
+// diagrams keep their attachment
+func diagramFileName(source, ext string) string {
+	sum := sha256.Sum256([]byte(source))
+	return "diagram-" + hex.EncodeToString(sum[:6]) + ext
+}
+
+// diagramFence returns how a fence of the given language is rendered, and
+// false if it is not a diagram language
+func (o Options) diagramFence(lang string) (DiagramMode, string, bool) {
+	switch strings.ToLower(lang) {
+	case "mermaid":
+		macro := o.MermaidMacro
+		if macro == "" {
+			macro = "mermaid"
+		}
+		return o.Mermaid, macro, true
+	case "plantuml", "puml":
+		macro := o.PlantUMLMacro
+		if macro == "" {
+			macro = "plantuml"
+		}
+		return o.PlantUML, macro, true
+	}
+	return DiagramCode, "", false
+}
+
+// fenceText returns the content of a code block
+func fenceText(source []byte, n ast.Node) string {
+	var code strings.Builder
+	lines := n.Lines()
+	for i := 0; i < lines.Len(); i++ {
+		line := lines.At(i)
+		code.Write(line.Value(source))
+	}
+	return code.String()
+}
+
+// renderDiagramImage renders a diagram fence, returning the image reference,
+// or false (after a warning) when the renderer is missing or failed
+func renderDiagramImage(opts Options, lang, source string, warn func(code WarningCode, msg string)) (string, bool) {
+	if opts.DiagramRenderer == nil {
+		warn(WarnDiagram, lang+" diagram left as code: no diagram renderer is set")
+		return "", false
+	}
+	lang = strings.ToLower(lang)
+	if lang == "puml" {
+		lang = "plantuml"
+	}
+	ref, err := opts.cachedDiagram(lang, source)
+	if err != nil {
+		warn(WarnDiagram, fmt.Sprintf("%s diagram left as code: %v", lang, err))
+		return "", false
+	}
+	return ref, true
+}
+
+// renderDiagram renders a diagram fence as a macro or image, returning false
+// when it should be rendered as a code block
+func (r *JIRARenderer) renderDiagram(buf *strings.Builder, n *ast.FencedCodeBlock, lang string) bool {
+	mode, macro, ok := r.options.diagramFence(lang)
+	if !ok {
+		return false
+	}
+	source := fenceText(r.source, n)
+	switch mode {
+	case DiagramMacro:
+		buf.WriteString("{" + macro + "}\n" + r.options.macroBody(macro, source) + "{" + macro + "}\n\n")
+		return true
+	case DiagramImage:
+		ref, ok := renderDiagramImage(r.options, lang, source, r.addWarning)
+		if !ok {
+			return false
+		}
+		buf.WriteString("!" + ref + "!\n\n")
+		return true
+	}
+	return false
+}
+
+// diagram renders a diagram fence rendered as an image to a URL as a media
+// node; ADF has no wiki macros, so other diagrams stay code blocks
+func (r *ADFRenderer) diagram(n *ast.FencedCodeBlock) *ADFNode {
+	lang := strings.TrimSpace(string(n.Language(r.source)))
+	mode, _, ok := r.options.diagramFence(lang)
+	if !ok || mode != DiagramImage {
+		return nil
+	}
+	ref, ok := renderDiagramImage(r.options, lang, fenceText(r.source, n), func(code WarningCode, msg string) {
+		r.addWarning(n, code, msg)
+	})
+	if !ok || !(strings.HasPrefix(ref, "http://") || strings.HasPrefix(ref, "https://")) {
+		return nil
+	}
+	return &ADFNode{
+		Type:    "mediaSingle",
+		Content: []*ADFNode{{Type: "media", Attrs: map[string]any{"type": "external", "url": ref, "alt": lang + " diagram"}}},
+	}
+}
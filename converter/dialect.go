@@ -0,0 +1,143 @@
+// Jira dialects
+// Server, Data Center and Cloud highlight different code languages and
+// render task list checkboxes and callouts differently
+
+package converter
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Dialect selects the Jira deployment the markup is written for
+type Dialect int
+
+const (
+	// DialectServer targets Jira Server, the most conservative markup
+	DialectServer Dialect = iota
+	// DialectDataCenter targets Jira Data Center
+	DialectDataCenter
+	// DialectCloud targets Jira Cloud
+	DialectCloud
+)
+
+// dialectNames maps dialects to their CLI names
+var dialectNames = map[Dialect]string{
+	DialectServer:     "server",
+	DialectDataCenter: "datacenter",
+	DialectCloud:      "cloud",
+}
+
+// String returns the CLI name of the dialect
+func (d Dialect) String() string {
+	if name, ok := dialectNames[d]; ok {
+		return name
+	}
+	return fmt.Sprintf("Dialect(%d)", int(d))
+}
+
+// ParseDialect parses a dialect name (server, datacenter or cloud)
+func ParseDialect(name string) (Dialect, error) {
+	for dialect, dialectName := range dialectNames {
+		if strings.EqualFold(name, dialectName) {
+			return dialect, nil
+		}
+	}
+	return DialectServer, fmt.Errorf("unknown dialect %q (want server, datacenter or cloud)", name)
+}
+
+// dialectProfile describes the markup a Jira deployment supports
+type dialectProfile struct {
+	// codeLanguages are the {code} languages the deployment highlights; nil
+	// means any language is accepted
+	codeLanguages map[string]bool
+	// checked and unchecked render task list checkboxes
+	checked, unchecked string
+	// calloutMacros reports whether {info}, {tip}, {note} and {warning} render;
+	// otherwise callouts become colored {panel} macros
+	calloutMacros bool
+}
+
+// serverCodeLanguages are the languages of the Jira Server {code} macro
+var serverCodeLanguages = []string{
+	"actionscript", "ada", "applescript", "bash", "c", "c#", "c++", "cpp", "css",
+	"erlang", "go", "groovy", "haskell", "html", "java", "javascript", "json",
+	"lua", "nyan", "objc", "perl", "php", "python", "r", "ruby", "scala", "sql",
+	"swift", "visualbasic", "xml", "yaml",
+}
+
+// dataCenterCodeLanguages are the languages added by Jira Data Center
+var dataCenterCodeLanguages = []string{
+	"dart", "dockerfile", "kotlin", "powershell", "rust", "typescript",
+}
+
+// dialectProfiles are the profiles of each dialect
+var dialectProfiles = map[Dialect]dialectProfile{
+	DialectServer: {
+		codeLanguages: stringSet(serverCodeLanguages),
+		checked:       "(/) ",
+		unchecked:     "( ) ",
+	},
+	DialectDataCenter: {
+		codeLanguages: stringSet(append(append([]string{}, serverCodeLanguages...), dataCenterCodeLanguages...)),
+		checked:       "(/) ",
+		unchecked:     "( ) ",
+	},
+	DialectCloud: {
+		// Cloud converts wiki markup to ADF, which highlights any language
+		checked:       "☑ ",
+		unchecked:     "☐ ",
+		calloutMacros: true,
+	},
+}
+
+// stringSet builds a set from a list of strings
+func stringSet(items []string) map[string]bool {
+	set := make(map[string]bool, len(items))
+	for _, item := range items {
+		set[item] = true
+	}
+	return set
+}
+
+// profile returns the dialect profile of the renderer
+func (r *JIRARenderer) profile() dialectProfile {
+	if p, ok := dialectProfiles[r.options.Dialect]; ok {
+		return p
+	}
+	return dialectProfiles[DialectServer]
+}
+
+// codeLanguage maps a Markdown code language to the {code} language of the
+// dialect, returning "" when the block should be an unhighlighted {code}
+func (r *JIRARenderer) codeLanguage(lang string) string {
+	jiraLang := mapLanguage(lang, r.options.LanguageMap)
+	if jiraLang == "" || jiraLang == "none" || (r.options.SafeMode && !safeLanguageRe.MatchString(jiraLang)) {
+		return ""
+	}
+	if supported := r.profile().codeLanguages; supported != nil && !supported[jiraLang] {
+		if r.options.WarnOnUnsupported {
+			r.addWarning(WarnCodeLanguage, fmt.Sprintf("%s does not highlight %s code; rendered as plain {code}", r.options.Dialect, jiraLang))
+		}
+		return ""
+	}
+	return jiraLang
+}
+
+// codeMacro returns the opening {code} macro for a Markdown code language,
+// followed by optional parameters such as title=...
+func (r *JIRARenderer) codeMacro(lang string, params ...string) string {
+	var all []string
+	if jiraLang := r.codeLanguage(lang); jiraLang != "" {
+		all = append(all, jiraLang)
+	}
+	for _, param := range params {
+		if param != "" {
+			all = append(all, param)
+		}
+	}
+	if len(all) == 0 {
+		return "{code}"
+	}
+	return "{code:" + strings.Join(all, "|") + "}"
+}
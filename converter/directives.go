@@ -0,0 +1,170 @@
+// Conversion directives
+// Record how constructs that only convert with a loss are rendered, so a
+// choice made once (see md2jira --interactive) applies to every later run
+
+package converter
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"strings"
+
+	"github.com/yuin/goldmark/ast"
+	east "github.com/yuin/goldmark/extension/ast"
+)
+
+// Directives map the Key of a warning to the choice that resolves it; a
+// resolved construct is rendered as chosen and no longer warned about
+type Directives map[string]string
+
+// Choices offered by warnings with a Key
+const (
+	// ChoiceConvert converts raw HTML with best effort
+	ChoiceConvert = "convert"
+	// ChoiceNoformat shows the Markdown source of the construct in a {noformat} block
+	ChoiceNoformat = "noformat"
+	// ChoiceDrop leaves the construct out
+	ChoiceDrop = "drop"
+	// ChoiceTable keeps a large table as it is
+	ChoiceTable = "table"
+	// ChoiceExpand folds a large table into an {expand}
+	ChoiceExpand = "expand"
+)
+
+// htmlBlockChoices and largeTableChoices are the choices of each warning; the
+// first one is what is rendered without a directive
+var (
+	htmlBlockChoices  = []string{ChoiceConvert, ChoiceNoformat, ChoiceDrop}
+	largeTableChoices = []string{ChoiceTable, ChoiceExpand, ChoiceNoformat}
+)
+
+// Tables with more columns or body rows than this are hard to read in JIRA
+const (
+	largeTableColumns = 8
+	largeTableRows    = 50
+)
+
+// resolveDirective returns how node is rendered: the choice of its directive,
+// or the first choice together with a warning offering the others
+func resolveDirective(opts Options, source []byte, node ast.Node, code WarningCode, msg string, choices []string) (string, *Warning) {
+	start, stop := blockSpan(source, node)
+	sum := sha256.Sum256(source[start:stop])
+	key := string(code) + ":" + hex.EncodeToString(sum[:6])
+	if choice, ok := opts.Directives[key]; ok {
+		for _, c := range choices {
+			if c == choice {
+				return choice, nil
+			}
+		}
+	}
+	if !opts.WarnOnUnsupported {
+		return choices[0], nil
+	}
+	line, column := constructPosition(source, node)
+	w := Warning{Code: code, Severity: codeSeverities[code], Line: line, Column: column, Message: msg, Key: key, Choices: choices}
+	return choices[0], &w
+}
+
+// blockSpan returns the source range of the lines holding a block and its
+// descendants
+func blockSpan(source []byte, node ast.Node) (int, int) {
+	start, stop := len(source), 0
+	_ = ast.Walk(node, func(n ast.Node, entering bool) (ast.WalkStatus, error) {
+		if !entering {
+			return ast.WalkContinue, nil
+		}
+		if t, ok := n.(*ast.Text); ok {
+			start, stop = min(start, t.Segment.Start), max(stop, t.Segment.Stop)
+		} else if n.Type() == ast.TypeBlock {
+			lines := n.Lines()
+			for i := 0; lines != nil && i < lines.Len(); i++ {
+				segment := lines.At(i)
+				start, stop = min(start, segment.Start), max(stop, segment.Stop)
+			}
+		}
+		return ast.WalkContinue, nil
+	})
+	if html, ok := node.(*ast.HTMLBlock); ok && html.HasClosure() {
+		stop = max(stop, html.ClosureLine.Stop)
+	}
+	if start > stop {
+		return 0, 0
+	}
+	for start > 0 && source[start-1] != '\n' {
+		start--
+	}
+	for stop < len(source) && source[stop-1] != '\n' {
+		stop++
+	}
+	return start, stop
+}
+
+// blockSource returns the Markdown source of a block, ending in a newline
+func blockSource(source []byte, node ast.Node) string {
+	start, stop := blockSpan(source, node)
+	text := string(source[start:stop])
+	if text != "" && text[len(text)-1] != '\n' {
+		text += "\n"
+	}
+	return text
+}
+
+// noformatSource returns the Markdown source of a block in a {noformat} macro
+func (r *JIRARenderer) noformatSource(node ast.Node) string {
+	return "{noformat}\n" + r.options.macroBody("noformat", blockSource(r.source, node)) + "{noformat}\n\n"
+}
+
+// sourceBlock renders the Markdown source of a block as a code block
+func (r *ADFRenderer) sourceBlock(node ast.Node) *ADFNode {
+	block := &ADFNode{Type: "codeBlock"}
+	if text := strings.TrimSuffix(blockSource(r.source, node), "\n"); text != "" {
+		block.Content = []*ADFNode{textNode(text, nil)}
+	}
+	return block
+}
+
+// isLargeTable reports whether a table has too many columns or rows to read
+// comfortably, with a description of its size
+func isLargeTable(table *east.Table) (string, bool) {
+	columns := len(table.Alignments)
+	rows := table.ChildCount() - 1
+	if columns <= largeTableColumns && rows <= largeTableRows {
+		return "", false
+	}
+	return fmt.Sprintf("table of %d columns and %d rows is hard to read in JIRA", columns, rows), true
+}
+
+// tableChoice returns how a table is rendered, warning about large tables
+// once per table
+func (r *JIRARenderer) tableChoice(table *east.Table) string {
+	if choice, ok := r.tableChoices[table]; ok {
+		return choice
+	}
+	choice := ChoiceTable
+	if msg, large := isLargeTable(table); large {
+		var w *Warning
+		choice, w = resolveDirective(r.options, r.source, table, WarnLargeTable, msg, largeTableChoices)
+		if w != nil {
+			r.warnings = append(r.warnings, *w)
+		}
+	}
+	if r.tableChoices == nil {
+		r.tableChoices = make(map[*east.Table]string)
+	}
+	r.tableChoices[table] = choice
+	return choice
+}
+
+// tableChoice returns how a table is rendered, warning about large tables
+func (r *ADFRenderer) tableChoice(table *east.Table) string {
+	msg, large := isLargeTable(table)
+	if !large {
+		return ChoiceTable
+	}
+	choice, w := resolveDirective(r.options, r.source, table, WarnLargeTable, msg, largeTableChoices)
+	if w != nil {
+		r.warnings = append(r.warnings, *w)
+	}
+	return choice
+}
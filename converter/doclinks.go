@@ -0,0 +1,38 @@
+// Links between documents
+// A link to another converted document ([design](design.md#api)) would point
+// to a file JIRA does not have; once the document is pushed to an issue, the
+// link is rewritten to the issue
+
+package converter
+
+import (
+	"net/url"
+	"path/filepath"
+)
+
+// documentURL returns the URL of the issue a link to a local document points
+// to, with the link's fragment, or false if the document was not pushed
+func (o Options) documentURL(dest string) (string, bool) {
+	if len(o.DocumentLinks) == 0 {
+		return "", false
+	}
+	u, err := url.Parse(dest)
+	if err != nil || u.Scheme != "" || u.Host != "" || u.Path == "" {
+		return "", false
+	}
+	path := filepath.FromSlash(u.Path)
+	if !filepath.IsAbs(path) {
+		path = filepath.Join(o.BaseDir, path)
+	}
+	if path, err = filepath.Abs(path); err != nil {
+		return "", false
+	}
+	target, ok := o.DocumentLinks[path]
+	if !ok {
+		return "", false
+	}
+	if u.Fragment != "" {
+		target += "#" + u.EscapedFragment()
+	}
+	return target, true
+}
@@ -0,0 +1,81 @@
+package converter
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestDocumentLinks(t *testing.T) {
+	dir := t.TempDir()
+	opts := Options{
+		BaseDir: dir,
+		DocumentLinks: map[string]string{
+			filepath.Join(dir, "design.md"):     "https://jira.example.com/browse/PROJ-7",
+			filepath.Join(dir, "ops", "run.md"): "https://jira.example.com/browse/PROJ-8",
+		},
+	}
+	tests := []struct {
+		name     string
+		markdown string
+		want     string
+	}{
+		{
+			name:     "pushed document",
+			markdown: "[design](design.md)",
+			want:     "[design|https://jira.example.com/browse/PROJ-7]",
+		},
+		{
+			name:     "heading of a pushed document",
+			markdown: "[API](design.md#api)",
+			want:     "[API|https://jira.example.com/browse/PROJ-7#api]",
+		},
+		{
+			name:     "relative path",
+			markdown: "[runbook](./ops/../ops/run.md)",
+			want:     "[runbook|https://jira.example.com/browse/PROJ-8]",
+		},
+		{
+			name:     "bare link keeps its label",
+			markdown: "[design.md](design.md)",
+			want:     "[design.md|https://jira.example.com/browse/PROJ-7]",
+		},
+		{
+			name:     "document that was not pushed",
+			markdown: "[other](other.md)",
+			want:     "[other|other.md]",
+		},
+		{
+			name:     "absolute URL",
+			markdown: "[design](https://example.com/design.md)",
+			want:     "[design|https://example.com/design.md]",
+		},
+		{
+			name:     "fragment",
+			markdown: "[below](#design)",
+			want:     "[below|#design]",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := render(t, tt.markdown, opts); got != tt.want {
+				t.Errorf("got %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDocumentLinksADF(t *testing.T) {
+	dir := t.TempDir()
+	opts := Options{
+		BaseDir:       dir,
+		DocumentLinks: map[string]string{filepath.Join(dir, "design.md"): "https://jira.example.com/browse/PROJ-7"},
+	}
+	result, err := ConvertToADFWithOptions("[API](design.md#api)", opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(result.Output, `"href":"https://jira.example.com/browse/PROJ-7#api"`) {
+		t.Errorf("got %s", result.Output)
+	}
+}
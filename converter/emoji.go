@@ -0,0 +1,177 @@
+// Emoji and symbols
+// Translates common Unicode emoji into JIRA emoticons, which render the same
+// in every font, or into plain-text labels for instances that disable
+// graphical emoticons; emoji without either are left as they are
+
+package converter
+
+import (
+	"fmt"
+	"strings"
+	"unicode/utf8"
+)
+
+// SymbolSet selects how emoji and checkboxes are written
+type SymbolSet int
+
+const (
+	// SymbolsEmoticons writes checkboxes as the emoticons of the dialect and,
+	// with Options.Emoticons, emoji as JIRA emoticons ((/), (x), (!), ...)
+	SymbolsEmoticons SymbolSet = iota
+	// SymbolsText writes checkboxes and emoji as plain-text labels ([done],
+	// [blocked], ...) and arrows as -> and <-
+	SymbolsText
+	// SymbolsOriginal leaves emoji as written, even with Options.Emoticons,
+	// and draws checkboxes as the Unicode ballot boxes ☑ and ☐
+	SymbolsOriginal
+)
+
+// symbolSetNames maps symbol sets to their CLI names
+var symbolSetNames = map[SymbolSet]string{
+	SymbolsEmoticons: "emoticons",
+	SymbolsText:      "text",
+	SymbolsOriginal:  "original",
+}
+
+// String returns the CLI name of the symbol set
+func (s SymbolSet) String() string {
+	if name, ok := symbolSetNames[s]; ok {
+		return name
+	}
+	return fmt.Sprintf("SymbolSet(%d)", int(s))
+}
+
+// ParseSymbolSet parses a symbol set name (emoticons, text or original)
+func ParseSymbolSet(name string) (SymbolSet, error) {
+	for set, setName := range symbolSetNames {
+		if strings.EqualFold(name, setName) {
+			return set, nil
+		}
+	}
+	return SymbolsEmoticons, fmt.Errorf("unknown symbol set %q (want emoticons, text or original)", name)
+}
+
+// emojiEmoticons maps emoji to the JIRA emoticon closest in meaning
+var emojiEmoticons = map[rune]string{
+	'✅': "(/)", '✔': "(/)", '☑': "(/)",
+	'❌': "(x)", '✖': "(x)", '❎': "(x)",
+	'⚠': "(!)", '❗': "(!)",
+	'❓': "(?)", '❔': "(?)",
+	'ℹ': "(i)",
+	'👍': "(y)", '👎': "(n)",
+	'➕': "(+)", '➖': "(-)",
+	'💡': "(on)",
+	'⭐': "(*)", '🌟': "(*)",
+	'🚩': "(flag)",
+	'🙂': ":)", '😊': ":)",
+	'🙁': ":(", '☹': ":(",
+	'😀': ":D", '😃': ":D", '😄': ":D",
+	'😛': ":P",
+	'😉': ";)",
+}
+
+// emoticonLabels are the plain-text forms of the emoticons; smileys such as
+// :) already read as text
+var emoticonLabels = map[string]string{
+	"(/)": "[done]", "(x)": "[blocked]", "(!)": "[warning]", "(?)": "[question]",
+	"(i)": "[info]", "(y)": "[yes]", "(n)": "[no]", "(+)": "[plus]", "(-)": "[minus]",
+	"(on)": "[idea]", "(*)": "[star]", "(flag)": "[flag]",
+}
+
+// arrowLabels are the plain-text forms of arrows
+var arrowLabels = map[rune]string{
+	'→': "->", '➡': "->", '⟶': "->",
+	'←': "<-", '⬅': "<-", '⟵': "<-",
+	'↔': "<->", '⟷': "<->",
+}
+
+// emojiModifier reports whether r only changes how the preceding emoji is
+// drawn: the emoji presentation selector and the skin tones
+func emojiModifier(r rune) bool {
+	return r == '\uFE0F' || r == '\uFE0E' || (r >= 0x1F3FB && r <= 0x1F3FF)
+}
+
+// replaceEmoji replaces the emoji of text that have a JIRA emoticon
+func replaceEmoji(text string) string {
+	return replaceSymbols(text, func(r rune) (string, bool) {
+		emoticon, ok := emojiEmoticons[r]
+		return emoticon, ok
+	})
+}
+
+// labelEmoji replaces the emoji of text that have a JIRA emoticon, and
+// arrows, with plain-text labels
+func labelEmoji(text string) string {
+	return replaceSymbols(text, func(r rune) (string, bool) {
+		if arrow, ok := arrowLabels[r]; ok {
+			return arrow, true
+		}
+		emoticon, ok := emojiEmoticons[r]
+		if label, labeled := emoticonLabels[emoticon]; labeled {
+			return label, true
+		}
+		return emoticon, ok
+	})
+}
+
+// replaceSymbols replaces the characters of text that replacement has a
+// replacement for, along with the modifiers that follow them
+func replaceSymbols(text string, replacement func(r rune) (string, bool)) string {
+	if !strings.ContainsFunc(text, func(r rune) bool { return r >= 0x2000 }) {
+		return text
+	}
+	var b strings.Builder
+	for i := 0; i < len(text); {
+		r, size := utf8.DecodeRuneInString(text[i:])
+		i += size
+		symbol, ok := replacement(r)
+		if !ok {
+			b.WriteRune(r)
+			continue
+		}
+		b.WriteString(symbol)
+		for i < len(text) {
+			next, size := utf8.DecodeRuneInString(text[i:])
+			if !emojiModifier(next) {
+				break
+			}
+			i += size
+		}
+	}
+	return b.String()
+}
+
+// checkbox returns the marker of a checked or unchecked checkbox, followed
+// by a space
+func (r *JIRARenderer) checkbox(checked bool) string {
+	if marker, ok := r.options.taskMarker(checked); ok {
+		// Escaped, as [x] would be a link
+		return r.escapeJIRAText(marker, 0)
+	}
+	if r.options.TaskStyle == TaskEmoticons {
+		return r.emoticonCheckbox(checked)
+	}
+	switch r.options.Symbols {
+	case SymbolsText:
+		// Escaped, as [done] would be a link
+		if checked {
+			return r.escapeJIRAText("[done]", 0) + " "
+		}
+		return r.escapeJIRAText("[todo]", 0) + " "
+	case SymbolsOriginal:
+		if checked {
+			return "☑ "
+		}
+		return "☐ "
+	}
+	return r.emoticonCheckbox(checked)
+}
+
+// emoticonCheckbox returns the emoticon of the dialect for a checked or
+// unchecked checkbox, followed by a space
+func (r *JIRARenderer) emoticonCheckbox(checked bool) string {
+	if checked {
+		return r.profile().checked
+	}
+	return r.profile().unchecked
+}
@@ -0,0 +1,78 @@
+// Input encodings
+// Editors on Windows save Markdown with a UTF-8 byte order mark, or as UTF-16,
+// which goldmark reads as text: the mark ends up in the first heading and
+// UTF-16 comes out as garbage
+
+package converter
+
+import (
+	"bytes"
+	"fmt"
+	"unicode/utf16"
+	"unicode/utf8"
+)
+
+// Byte order marks
+var (
+	bomUTF8    = []byte{0xEF, 0xBB, 0xBF}
+	bomUTF16LE = []byte{0xFF, 0xFE}
+	bomUTF16BE = []byte{0xFE, 0xFF}
+)
+
+// DecodeSource returns the UTF-8 text of a Markdown file: a UTF-8 byte order
+// mark is stripped, UTF-16 starting with a byte order mark is transcoded, and
+// anything else must be valid UTF-8
+func DecodeSource(data []byte) ([]byte, error) {
+	switch {
+	case bytes.HasPrefix(data, bomUTF8):
+		data = data[len(bomUTF8):]
+	case bytes.HasPrefix(data, bomUTF16LE):
+		return decodeUTF16(data[len(bomUTF16LE):], false)
+	case bytes.HasPrefix(data, bomUTF16BE):
+		return decodeUTF16(data[len(bomUTF16BE):], true)
+	}
+	if utf8.Valid(data) {
+		return data, nil
+	}
+	offset := invalidUTF8(data)
+	line := bytes.Count(data[:offset], []byte("\n")) + 1
+	column := utf8.RuneCount(data[bytes.LastIndexByte(data[:offset], '\n')+1:offset]) + 1
+	return nil, fmt.Errorf("invalid UTF-8 at line %d, column %d (byte 0x%02X); save the file as UTF-8 or UTF-16 with a byte order mark", line, column, data[offset])
+}
+
+// invalidUTF8 returns the offset of the first invalid UTF-8 sequence of data
+func invalidUTF8(data []byte) int {
+	for offset := 0; offset < len(data); {
+		r, size := utf8.DecodeRune(data[offset:])
+		if r == utf8.RuneError && size == 1 {
+			return offset
+		}
+		offset += size
+	}
+	return len(data)
+}
+
+// decodeUTF16 transcodes UTF-16 without its byte order mark to UTF-8
+func decodeUTF16(data []byte, bigEndian bool) ([]byte, error) {
+	if len(data)%2 != 0 {
+		return nil, fmt.Errorf("truncated UTF-16: odd number of bytes")
+	}
+	units := make([]uint16, len(data)/2)
+	for i := range units {
+		if bigEndian {
+			units[i] = uint16(data[2*i])<<8 | uint16(data[2*i+1])
+		} else {
+			units[i] = uint16(data[2*i+1])<<8 | uint16(data[2*i])
+		}
+	}
+	return []byte(string(utf16.Decode(units))), nil
+}
+
+// normalizeSource strips the UTF-8 byte order mark of Markdown passed as a
+// string and normalizes its line endings, from Compat12
+func normalizeSource(source []byte, opts Options) []byte {
+	if !opts.compat(Compat12) {
+		return source
+	}
+	return normalizeEOL(bytes.TrimPrefix(source, bomUTF8))
+}
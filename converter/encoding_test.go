@@ -0,0 +1,48 @@
+package converter
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestDecodeSource(t *testing.T) {
+	tests := []struct {
+		name    string
+		in      []byte
+		want    string
+		wantErr string
+	}{
+		{"UTF-8", []byte("# Título"), "# Título", ""},
+		{"UTF-8 with byte order mark", []byte("\xEF\xBB\xBF# Title"), "# Title", ""},
+		{"UTF-16LE", []byte("\xFF\xFE#\x00 \x00\xe9\x00"), "# é", ""},
+		{"UTF-16BE", []byte("\xFE\xFF\x00#\x00 \x00\xe9"), "# é", ""},
+		{"UTF-16LE surrogate pair", []byte("\xFF\xFE\x3D\xD8\x00\xDE"), "😀", ""},
+		{"truncated UTF-16", []byte("\xFF\xFE#\x00 "), "", "truncated UTF-16"},
+		{"empty", nil, "", ""},
+		{"invalid UTF-8", []byte("ok\nab\xE9c"), "", "invalid UTF-8 at line 2, column 3 (byte 0xE9)"},
+		{"Latin-1 on the first line", []byte("caf\xe9"), "", "invalid UTF-8 at line 1, column 4 (byte 0xE9)"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := DecodeSource(tt.in)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("DecodeSource error = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if string(got) != tt.want {
+				t.Errorf("DecodeSource = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestConvertStripsByteOrderMark(t *testing.T) {
+	if got := render(t, "\uFEFF# Title", Options{}); got != "h1. Title" {
+		t.Errorf("got %q, want %q", got, "h1. Title")
+	}
+}
@@ -0,0 +1,17 @@
+// Line endings
+// Files saved on Windows end their lines in \r\n, and goldmark keeps the \r
+// in code blocks, before hard breaks and at the end of text, so the input is
+// normalized to \n before it is parsed
+
+package converter
+
+import "bytes"
+
+// normalizeEOL converts the CRLF and lone CR line endings of source to LF
+func normalizeEOL(source []byte) []byte {
+	if bytes.IndexByte(source, '\r') < 0 {
+		return source
+	}
+	source = bytes.ReplaceAll(source, []byte("\r\n"), []byte("\n"))
+	return bytes.ReplaceAll(source, []byte("\r"), []byte("\n"))
+}
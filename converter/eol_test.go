@@ -0,0 +1,42 @@
+package converter
+
+import "testing"
+
+func TestNormalizeEOL(t *testing.T) {
+	tests := []struct {
+		in   string
+		want string
+	}{
+		{"a\nb\n", "a\nb\n"},
+		{"a\r\nb\r\n", "a\nb\n"},
+		{"a\rb\r", "a\nb\n"},
+		{"a\r\n\r\nb\rc\n", "a\n\nb\nc\n"},
+		{"", ""},
+	}
+	for _, tt := range tests {
+		if got := string(normalizeEOL([]byte(tt.in))); got != tt.want {
+			t.Errorf("normalizeEOL(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestConvertCRLF(t *testing.T) {
+	tests := []struct {
+		name     string
+		markdown string
+		want     string
+	}{
+		{"paragraphs", "# T\r\n\r\ntext\r\nmore\r\n", "h1. T\n\ntext\nmore"},
+		{"code block", "```\r\ncode\r\n```\r\n", "{code}\ncode\n{code}"},
+		{"hard break", "line  \r\nnext\r\n", "line\\\\\nnext"},
+		{"lone CR", "a\rb\r", "a\nb"},
+		{"table", "| a |\r\n|---|\r\n| b |\r\n", "||a||\n|b|"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := render(t, tt.markdown, Options{}); got != tt.want {
+				t.Errorf("got %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,93 @@
+// JIRA special-character escaping
+// Escapes only the characters that would trigger JIRA formatting in their
+// context; the rules live in the jiraescape package
+
+package converter
+
+import (
+	"github.com/astsu-dev/md2jira/jiraescape"
+	"github.com/yuin/goldmark/ast"
+	east "github.com/yuin/goldmark/extension/ast"
+)
+
+// EscapeMode controls how aggressively JIRA markup characters are escaped
+type EscapeMode = jiraescape.Mode
+
+const (
+	// EscapeMinimal escapes only characters that would trigger JIRA formatting in context
+	EscapeMinimal = jiraescape.Minimal
+	// EscapeAggressive escapes every JIRA special character
+	EscapeAggressive = jiraescape.Aggressive
+	// EscapeNone leaves text untouched, for targets that do not interpret the markup
+	EscapeNone = jiraescape.None
+)
+
+// ParseEscapeMode parses an escape mode name (none, minimal or aggressive)
+func ParseEscapeMode(name string) (EscapeMode, error) {
+	return jiraescape.ParseMode(name)
+}
+
+// EscapeStyle controls how escaped characters are neutralized
+type EscapeStyle = jiraescape.Style
+
+const (
+	// EscapeBackslash prefixes escaped characters with a visible backslash
+	EscapeBackslash = jiraescape.Backslash
+	// EscapeZeroWidth follows escaped effect markers with a zero-width space
+	EscapeZeroWidth = jiraescape.ZeroWidth
+	// EscapeEmptyGroup follows escaped effect markers with an empty {} group
+	EscapeEmptyGroup = jiraescape.EmptyGroup
+)
+
+// ParseEscapeStyle parses an escape style name (backslash, zero-width or empty-group)
+func ParseEscapeStyle(name string) (EscapeStyle, error) {
+	return jiraescape.ParseStyle(name)
+}
+
+// escapeContext describes where escaped text ends up in the output
+type escapeContext = jiraescape.Context
+
+const (
+	// ctxTableCell marks text inside a table cell, where | splits the row
+	ctxTableCell = jiraescape.TableCell
+	// ctxLinkLabel marks text inside [label|url], where | and ] end the label
+	ctxLinkLabel = jiraescape.LinkLabel
+	// ctxCode marks text inside {{monospace}}, where } ends the span and
+	// backslashes are literal in the Markdown source
+	ctxCode = jiraescape.Code
+	// ctxLineStart marks text that starts a line of the output, where list,
+	// heading, quote and table markers take effect
+	ctxLineStart = jiraescape.LineStart
+)
+
+// jiraMetaChars are characters that carry formatting meaning in JIRA markup
+const jiraMetaChars = jiraescape.MetaChars
+
+// escapeJIRA escapes JIRA markup characters in Markdown text for the given
+// context, with the escaping rules of opts
+func escapeJIRA(text string, ctx escapeContext, mode EscapeMode, opts Options) string {
+	return jiraescape.MarkdownWithRules(text, ctx, mode, opts.EscapeStyle, opts.escapeRules())
+}
+
+// escapeRules returns the escaping rules of the compatibility level
+func (o Options) escapeRules() jiraescape.Rules {
+	rules := jiraescape.AllRules
+	if !o.compat(Compat12) {
+		rules &^= jiraescape.IssueKeys | jiraescape.LineStarts | jiraescape.Underscores
+	}
+	return rules
+}
+
+// textContext determines the escape context of a node from its ancestors
+func textContext(node ast.Node) escapeContext {
+	var ctx escapeContext
+	for p := node.Parent(); p != nil; p = p.Parent() {
+		switch p.(type) {
+		case *east.TableCell:
+			ctx |= ctxTableCell
+		case *ast.Link:
+			ctx |= ctxLinkLabel
+		}
+	}
+	return ctx
+}
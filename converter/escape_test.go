@@ -0,0 +1,60 @@
+package converter
+
+import "testing"
+
+func TestEscapeLineStarts(t *testing.T) {
+	tests := []struct {
+		name     string
+		markdown string
+		want     string
+	}{
+		{"heading marker", "h1. Title", `h1\. Title`},
+		{"quote marker", "bq. quoted", `bq\. quoted`},
+		{"table row", "| not a row", `\| not a row`},
+		{"mixed list marker", "#* mixed", `\#* mixed`},
+		{"deleted text marker", "-- double", `\-- double`},
+		{"nested list marker", "** two", `\** two`},
+		{"second line of a paragraph", "text\n** two", "text\n\\** two"},
+		{"quote", "> ** x", "{quote}\n\\** x\n\n{quote}"},
+		{"heading text", "# h1. heading", "h1. h1. heading"},
+		{"mid-line marker", "a *b* -- c", "a _b_ -- c"},
+		{"after strong text", "**bold** -- x", "*bold* -- x"},
+		{"table cell", "| a |\n|---|\n| -- b |", "||a||\n|-- b|"},
+		{"Markdown escape", `\- not a list`, `\- not a list`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := render(t, tt.markdown, Options{}); got != tt.want {
+				t.Errorf("got %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestEscapeUnderscores(t *testing.T) {
+	tests := []struct {
+		name     string
+		markdown string
+		mode     EscapeMode
+		want     string
+	}{
+		{"intra-word", "snake_case_name", EscapeMinimal, `snake\_case\_name`},
+		{"emphasis is kept", "_emph_ and intra_word", EscapeMinimal, `_emph_ and intra\_word`},
+		{"strong", "call __init__ now", EscapeMinimal, "call *init* now"},
+		{"unpaired", "under_ score", EscapeMinimal, `under\_ score`},
+		{"apostrophes", "it's a_b's", EscapeMinimal, `it's a\_b's`},
+		{"code span", "`HKEY_CURRENT_USER`", EscapeMinimal, "{{HKEY_CURRENT_USER}}"},
+		{"link label", "[my_file](u)", EscapeMinimal, `[my\_file|u]`},
+		{"table cells", "| a_b |\n|---|\n| c_d |", EscapeMinimal, "||a\\_b||\n|c\\_d|"},
+		{"issue key", "MY_PROJ-12 done", EscapeMinimal, "MY_PROJ-12 done"},
+		{"aggressive", "a_b", EscapeAggressive, `a\_b`},
+		{"none", "a_b", EscapeNone, "a_b"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := render(t, tt.markdown, Options{EscapeMode: tt.mode}); got != tt.want {
+				t.Errorf("got %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,100 @@
+// Footnote rendering
+// Inlines short footnotes at their reference site and lists the rest in an
+// anchored Footnotes section at the end
+
+package converter
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/yuin/goldmark/ast"
+	east "github.com/yuin/goldmark/extension/ast"
+)
+
+// collectFootnotes renders the content of footnotes eligible for inlining
+func (r *JIRARenderer) collectFootnotes(doc ast.Node) {
+	if !r.options.InlineFootnotes {
+		return
+	}
+	r.inlineFootnotes = make(map[int]string)
+	for node := doc.FirstChild(); node != nil; node = node.NextSibling() {
+		list, ok := node.(*east.FootnoteList)
+		if !ok {
+			continue
+		}
+		for child := list.FirstChild(); child != nil; child = child.NextSibling() {
+			footnote, ok := child.(*east.Footnote)
+			if !ok {
+				continue
+			}
+			content := r.footnoteContent(footnote)
+			max := r.options.InlineFootnoteMaxLen
+			if max == 0 || len([]rune(content)) <= max {
+				r.inlineFootnotes[footnote.Index] = content
+			}
+		}
+	}
+}
+
+// footnoteContent renders a footnote's blocks as a single line
+func (r *JIRARenderer) footnoteContent(n *east.Footnote) string {
+	var parts []string
+	for block := n.FirstChild(); block != nil; block = block.NextSibling() {
+		var buf strings.Builder
+		if ast.IsParagraph(block) {
+			r.renderChildren(&buf, block)
+		} else {
+			r.walk(&buf, block)
+		}
+		if text := strings.Join(strings.Fields(buf.String()), " "); text != "" {
+			parts = append(parts, text)
+		}
+	}
+	return strings.Join(parts, " ")
+}
+
+// renderFootnoteLink renders a footnote reference
+func (r *JIRARenderer) renderFootnoteLink(buf *strings.Builder, n *east.FootnoteLink, entering bool) {
+	if !entering {
+		return
+	}
+	if content, ok := r.inlineFootnotes[n.Index]; ok {
+		fmt.Fprintf(buf, " (%s)", content)
+		return
+	}
+	fmt.Fprintf(buf, "^%d^", n.Index)
+}
+
+// renderFootnoteList renders the trailing list of footnotes that were not inlined
+func (r *JIRARenderer) renderFootnoteList(buf *strings.Builder, n *east.FootnoteList, entering bool) {
+	if !entering {
+		return
+	}
+	for child := n.FirstChild(); child != nil; child = child.NextSibling() {
+		if footnote, ok := child.(*east.Footnote); ok && !r.isInlineFootnote(footnote) {
+			if r.options.compat(Compat2) {
+				buf.WriteString("h4. Footnotes\n\n")
+			} else {
+				buf.WriteString("----\n")
+			}
+			return
+		}
+	}
+}
+
+// renderFootnote renders a single footnote definition, anchored as #fn-N
+func (r *JIRARenderer) renderFootnote(buf *strings.Builder, n *east.Footnote, entering bool) {
+	if entering && !r.isInlineFootnote(n) {
+		if r.options.compat(Compat2) {
+			fmt.Fprintf(buf, "{anchor:fn-%d}", n.Index)
+		}
+		fmt.Fprintf(buf, "^%d^ %s\n", n.Index, r.footnoteContent(n))
+	}
+}
+
+// isInlineFootnote reports whether a footnote is rendered at its reference site
+func (r *JIRARenderer) isInlineFootnote(n *east.Footnote) bool {
+	_, ok := r.inlineFootnotes[n.Index]
+	return ok
+}
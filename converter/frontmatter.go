@@ -0,0 +1,154 @@
+// Front matter
+// Strips the YAML (---) or TOML (+++) metadata block that Hugo, Jekyll and
+// Obsidian put at the top of a document, and exposes it as Result.Metadata;
+// SplitDocuments splits bundles of documents at their front matter
+
+package converter
+
+import (
+	"bytes"
+	"strings"
+
+	"github.com/BurntSushi/toml"
+	"github.com/yuin/goldmark/ast"
+	"gopkg.in/yaml.v3"
+)
+
+// frontMatter returns the metadata of the front matter block that starts the
+// source and the offset where the document proper begins. A block that does
+// not hold a mapping, such as a --- rule followed by a setext heading, is not
+// front matter.
+func frontMatter(source []byte) (map[string]any, int, bool) {
+	line, rest, ok := bytes.Cut(source, []byte("\n"))
+	if !ok {
+		return nil, 0, false
+	}
+	fence := string(bytes.TrimRight(line, " \t\r"))
+	if fence != "---" && fence != "+++" {
+		return nil, 0, false
+	}
+	start := len(line) + 1
+	offset := start
+	for len(rest) > 0 {
+		line, rest, _ = bytes.Cut(rest, []byte("\n"))
+		closing := string(bytes.TrimRight(line, " \t\r"))
+		if closing == fence || (fence == "---" && closing == "...") {
+			body := source[start:offset]
+			end := offset + len(line)
+			if end < len(source) {
+				end++
+			}
+			meta := map[string]any{}
+			var err error
+			if fence == "---" {
+				err = yaml.Unmarshal(body, &meta)
+			} else {
+				err = toml.Unmarshal(body, &meta)
+			}
+			if err != nil {
+				return nil, 0, false
+			}
+			return meta, end, true
+		}
+		offset += len(line) + 1
+	}
+	return nil, 0, false
+}
+
+// stripFrontMatter returns the source to parse: a copy with the front matter
+// blanked out, so that offsets and line numbers still match the original
+func stripFrontMatter(source []byte, opts Options) []byte {
+	if !opts.compat(Compat5) {
+		return source
+	}
+	_, end, ok := frontMatter(source)
+	if !ok {
+		return source
+	}
+	blanked := bytes.Clone(source)
+	for i := 0; i < end; i++ {
+		if blanked[i] != '\n' {
+			blanked[i] = ' '
+		}
+	}
+	return blanked
+}
+
+// HasFrontMatter reports whether source starts with a front matter block
+func HasFrontMatter(source string) bool {
+	_, _, ok := frontMatter([]byte(source))
+	return ok
+}
+
+// metadata returns the parsed front matter of the source, with the title
+// taken out of the document if the front matter has none, or nil
+func metadata(doc ast.Node, source []byte, opts Options) map[string]any {
+	var meta map[string]any
+	if opts.compat(Compat5) {
+		meta, _, _ = frontMatter(source)
+	}
+	if title := documentTitle(doc); title != "" {
+		if meta == nil {
+			meta = make(map[string]any)
+		}
+		if _, ok := meta["title"]; !ok {
+			meta["title"] = title
+		}
+	}
+	return meta
+}
+
+// Document is one of the documents of a bundle
+type Document struct {
+	// Source is the document, starting with its front matter
+	Source string
+	// Offset is the byte offset of the document in the bundle
+	Offset int
+	// Metadata is the parsed front matter, or nil for text before the
+	// first block
+	Metadata map[string]any
+}
+
+// SplitDocuments splits a bundle of documents that each start with their own
+// front matter block, as generators emit them. Text before the first block is
+// a document without metadata; blank documents are dropped. Blocks inside
+// fenced code and blocks that hold no fields, such as a --- rule followed by
+// another, do not start a document.
+func SplitDocuments(source string) []Document {
+	var docs []Document
+	add := func(start, end int) {
+		text := source[start:end]
+		if strings.TrimSpace(text) == "" {
+			return
+		}
+		meta, _, _ := frontMatter([]byte(text))
+		docs = append(docs, Document{Source: text, Offset: start, Metadata: meta})
+	}
+
+	start := 0
+	fence := ""
+	for offset := 0; offset < len(source); {
+		text, _, _ := strings.Cut(source[offset:], "\n")
+		next := offset + len(text) + 1
+		trimmed := strings.TrimSpace(text)
+		switch {
+		case fence != "":
+			if strings.HasPrefix(trimmed, fence) {
+				fence = ""
+			}
+		case strings.HasPrefix(trimmed, "```") || strings.HasPrefix(trimmed, "~~~"):
+			fence = trimmed[:3]
+		case trimmed == "---" || trimmed == "+++":
+			meta, end, ok := frontMatter([]byte(source[offset:]))
+			if !ok || len(meta) == 0 {
+				break
+			}
+			add(start, offset)
+			// The block is skipped, so its closing fence starts nothing
+			start, next = offset, offset+end
+		}
+		offset = next
+	}
+	add(start, len(source))
+	return docs
+}
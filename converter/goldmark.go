@@ -0,0 +1,185 @@
+// goldmark renderer integration
+// Exposes the JIRA renderer as goldmark renderer.Renderer and renderer.NodeRenderer
+
+package converter
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/yuin/goldmark/ast"
+	east "github.com/yuin/goldmark/extension/ast"
+	"github.com/yuin/goldmark/renderer"
+	"github.com/yuin/goldmark/util"
+)
+
+// jiraNodeKinds are the node kinds rendered natively by JIRARenderer
+var jiraNodeKinds = []ast.NodeKind{
+	ast.KindDocument, ast.KindHeading, ast.KindParagraph, ast.KindText,
+	ast.KindString, ast.KindEmphasis, ast.KindCodeSpan, ast.KindFencedCodeBlock,
+	ast.KindCodeBlock, ast.KindLink, ast.KindAutoLink, ast.KindImage,
+	ast.KindList, ast.KindListItem, ast.KindThematicBreak, ast.KindBlockquote,
+	ast.KindHTMLBlock, ast.KindRawHTML, ast.KindTextBlock,
+	east.KindTable, east.KindTableHeader, east.KindTableRow, east.KindTableCell,
+	east.KindStrikethrough, east.KindTaskCheckBox,
+	east.KindFootnoteLink, east.KindFootnoteBacklink, east.KindFootnoteList, east.KindFootnote,
+	kindAdmonition, kindDetails, kindMathBlock, kindMathInline, kindInserted,
+}
+
+// Renderer is a goldmark renderer.Renderer producing JIRA markup.
+// Use it with goldmark.WithRenderer to plug JIRA output into an existing pipeline.
+// NodeRenderers added through AddOptions render node kinds that JIRARenderer
+// does not handle itself, which lets custom extensions render their nodes.
+// The HTML renderers registered by goldmark's own extensions are ignored for
+// natively handled kinds (tables, strikethrough, task lists).
+type Renderer struct {
+	options  Options
+	config   *renderer.Config
+	warnings []Warning
+}
+
+// NewRenderer creates a goldmark renderer producing JIRA markup
+func NewRenderer(opts Options, options ...renderer.Option) *Renderer {
+	r := &Renderer{
+		options: opts,
+		config:  renderer.NewConfig(),
+	}
+	r.AddOptions(options...)
+	return r
+}
+
+// AddOptions adds goldmark renderer options, such as renderer.WithNodeRenderers
+func (r *Renderer) AddOptions(options ...renderer.Option) {
+	for _, opt := range options {
+		opt.SetConfig(r.config)
+	}
+}
+
+// Render renders the AST to JIRA markup
+func (r *Renderer) Render(w io.Writer, source []byte, n ast.Node) error {
+	jira := NewJIRARenderer(source, r.options)
+	jira.nodeFuncs = r.nodeFuncs()
+	output := cleanOutput(jira.Render(n))
+	r.warnings = jira.GetWarnings()
+	_, err := io.WriteString(w, output)
+	return err
+}
+
+// Warnings returns the warnings generated by the last Render call
+func (r *Renderer) Warnings() []Warning {
+	return r.warnings
+}
+
+// nodeFuncs collects the NodeRendererFuncs registered through options
+func (r *Renderer) nodeFuncs() map[ast.NodeKind]renderer.NodeRendererFunc {
+	funcs := make(funcRegisterer)
+	r.config.NodeRenderers.Sort()
+	// Register in reverse priority order so higher priorities win
+	for i := len(r.config.NodeRenderers) - 1; i >= 0; i-- {
+		v := r.config.NodeRenderers[i]
+		if se, ok := v.Value.(renderer.SetOptioner); ok {
+			for name, value := range r.config.Options {
+				se.SetOption(name, value)
+			}
+		}
+		if nr, ok := v.Value.(renderer.NodeRenderer); ok {
+			nr.RegisterFuncs(funcs)
+		}
+	}
+	for _, kind := range jiraNodeKinds {
+		delete(funcs, kind)
+	}
+	return funcs
+}
+
+// funcRegisterer is a renderer.NodeRendererFuncRegisterer backed by a map
+type funcRegisterer map[ast.NodeKind]renderer.NodeRendererFunc
+
+// Register registers a NodeRendererFunc for a node kind
+func (f funcRegisterer) Register(kind ast.NodeKind, fn renderer.NodeRendererFunc) {
+	f[kind] = fn
+}
+
+// walkFunc renders a node with a goldmark NodeRendererFunc
+func (r *JIRARenderer) walkFunc(buf *strings.Builder, node ast.Node, f renderer.NodeRendererFunc) {
+	w := bufio.NewWriter(buf)
+	r.current = node
+	status, err := f(w, r.source, node, true)
+	w.Flush()
+	if err != nil {
+		r.addWarning(WarnRendererFailed, fmt.Sprintf("%s node renderer failed: %v", node.Kind(), err))
+		return
+	}
+	if status != ast.WalkSkipChildren {
+		r.renderChildren(buf, node)
+	}
+	r.current = node
+	if _, err := f(w, r.source, node, false); err != nil {
+		r.addWarning(WarnRendererFailed, fmt.Sprintf("%s node renderer failed: %v", node.Kind(), err))
+	}
+	w.Flush()
+}
+
+// NodeRenderer is a goldmark renderer.NodeRenderer producing JIRA markup.
+// Register it with renderer.WithNodeRenderers, using a priority below 500 so it
+// wins over the HTML renderers goldmark's extensions add. Unlike Renderer, output
+// is not post-processed, so blank lines between blocks are not collapsed.
+type NodeRenderer struct {
+	jira *JIRARenderer
+}
+
+// NewNodeRenderer creates a goldmark node renderer producing JIRA markup
+func NewNodeRenderer(opts Options) *NodeRenderer {
+	return &NodeRenderer{jira: NewJIRARenderer(nil, opts)}
+}
+
+// RegisterFuncs registers the JIRA rendering functions
+func (nr *NodeRenderer) RegisterFuncs(reg renderer.NodeRendererFuncRegisterer) {
+	for _, kind := range jiraNodeKinds {
+		reg.Register(kind, nr.renderNode)
+	}
+}
+
+// Warnings returns the warnings generated so far
+func (nr *NodeRenderer) Warnings() []Warning {
+	return nr.jira.GetWarnings()
+}
+
+// renderNode renders a single node as a goldmark NodeRendererFunc
+func (nr *NodeRenderer) renderNode(w util.BufWriter, source []byte, n ast.Node, entering bool) (ast.WalkStatus, error) {
+	r := nr.jira
+	if _, ok := n.(*ast.Document); ok {
+		// Reset per-document state; goldmark walks the children itself
+		if entering {
+			warnings := r.warnings
+			*r = *NewJIRARenderer(source, r.options)
+			r.warnings = warnings
+			r.collectFootnotes(n)
+			r.collectAnchors(n)
+			return ast.WalkContinue, nil
+		}
+		var buf strings.Builder
+		r.closePhase(&buf)
+		r.renderEndnotes(&buf)
+		_, err := w.WriteString(buf.String())
+		return ast.WalkContinue, err
+	}
+
+	var buf strings.Builder
+	r.current = n
+	r.renderNode(&buf, n, entering)
+	if buf.Len() > 0 {
+		// goldmark writes each node separately, so the next text node
+		// learns from the output so far whether it starts a line
+		r.midLine = !strings.HasSuffix(buf.String(), "\n")
+	}
+	if _, err := w.WriteString(buf.String()); err != nil {
+		return ast.WalkStop, err
+	}
+	if r.isLeafNode(n) || r.skipChildren(n) {
+		return ast.WalkSkipChildren, nil
+	}
+	return ast.WalkContinue, nil
+}
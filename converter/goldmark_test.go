@@ -0,0 +1,58 @@
+package converter
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/yuin/goldmark"
+	"github.com/yuin/goldmark/renderer"
+	"github.com/yuin/goldmark/util"
+)
+
+func TestNodeRendererLineStarts(t *testing.T) {
+	tests := []struct {
+		name     string
+		markdown string
+		want     string
+	}{
+		{
+			name:     "marker at the start of a paragraph",
+			markdown: `\- not a list`,
+			want:     "\\- not a list\n\n",
+		},
+		{
+			name:     "marker after emphasis",
+			markdown: "*a*- b",
+			want:     "_a_- b\n\n",
+		},
+		{
+			name:     "marker after a code span",
+			markdown: "`a` # b",
+			want:     "{{a}} # b\n\n",
+		},
+		{
+			name:     "marker after a heading marker",
+			markdown: "# - x",
+			want:     "h1. - x\n\n",
+		},
+		{
+			name:     "marker after a line break",
+			markdown: "*a*\n\\- b",
+			want:     "_a_\n\\- b\n\n",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			md := goldmark.New(goldmark.WithRenderer(renderer.NewRenderer(
+				renderer.WithNodeRenderers(util.Prioritized(NewNodeRenderer(Options{}), 100)),
+			)))
+			var out bytes.Buffer
+			if err := md.Convert([]byte(tt.markdown), &out); err != nil {
+				t.Fatal(err)
+			}
+			if got := out.String(); got != tt.want {
+				t.Errorf("got %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
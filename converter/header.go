@@ -0,0 +1,141 @@
+// Header and footer templates
+// Wraps every conversion in a team's standard preamble and sign-off blocks
+
+package converter
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"text/template"
+	"time"
+)
+
+// TemplateData is the data Options.Header and Options.Footer are executed with
+type TemplateData struct {
+	// Source is the path of the converted file ("" for standard input)
+	Source string
+	// Date is the date of the conversion as YYYY-MM-DD
+	Date string
+	// Version is the md2jira version
+	Version string
+}
+
+// Author returns the author of most lines of the source according to git
+// blame, or "" when the source is not tracked by git. It only runs git when
+// a template uses it.
+func (d TemplateData) Author() string {
+	if d.Source == "" {
+		return ""
+	}
+	cmd := exec.Command("git", "blame", "--porcelain", "--", filepath.Base(d.Source))
+	cmd.Dir = filepath.Dir(d.Source)
+	out, err := cmd.Output()
+	if err != nil {
+		return ""
+	}
+	// Porcelain output repeats the author of a commit only on its first line,
+	// so count the lines of each commit and credit them to its author
+	authors := make(map[string]string)
+	lines := make(map[string]int)
+	var commit string
+	scanner := bufio.NewScanner(bytes.NewReader(out))
+	for scanner.Scan() {
+		line := scanner.Text()
+		switch {
+		case strings.HasPrefix(line, "\t"):
+			lines[commit]++
+		case strings.HasPrefix(line, "author "):
+			authors[commit] = strings.TrimPrefix(line, "author ")
+		default:
+			if fields := strings.Fields(line); len(fields) >= 3 && len(fields[0]) == 40 {
+				commit = fields[0]
+			}
+		}
+	}
+	counts := make(map[string]int)
+	for commit, n := range lines {
+		if author := authors[commit]; author != "" && author != "Not Committed Yet" {
+			counts[author] += n
+		}
+	}
+	best := ""
+	for author, n := range counts {
+		if n > counts[best] || (n == counts[best] && author < best) {
+			best = author
+		}
+	}
+	return best
+}
+
+// executeTemplates returns the Markdown of the header and footer templates,
+// which are "" when not set
+func executeTemplates(opts Options) (header, footer string, err error) {
+	data := TemplateData{
+		Source:  opts.SourcePath,
+		Date:    time.Now().Format("2006-01-02"),
+		Version: Version,
+	}
+	execute := func(name string, t *template.Template) (string, error) {
+		if t == nil {
+			return "", nil
+		}
+		var out strings.Builder
+		if err := t.Execute(&out, data); err != nil {
+			return "", fmt.Errorf("%s template: %v", name, err)
+		}
+		return out.String(), nil
+	}
+	if header, err = execute("header", opts.Header); err != nil {
+		return "", "", err
+	}
+	if footer, err = execute("footer", opts.Footer); err != nil {
+		return "", "", err
+	}
+	return header, footer, nil
+}
+
+// templateOptions returns the options header and footer Markdown is converted
+// with: those of the document, without the templates, trailer and checks
+func templateOptions(opts Options) Options {
+	opts.Header = nil
+	opts.Footer = nil
+	opts.ProvenanceTrailer = false
+	opts.CheckLinks = false
+	opts.SpellChecker = nil
+	return opts
+}
+
+// templateMarkup converts header or footer Markdown to JIRA markup
+func templateMarkup(markdown string, opts Options) string {
+	if markdown == "" {
+		return ""
+	}
+	source := normalizeSource([]byte(markdown), opts)
+	opts = templateOptions(opts)
+	return cleanOutput(NewJIRARenderer(source, opts).Render(parseMarkdown(source, opts)))
+}
+
+// templateADF converts header or footer Markdown to ADF block nodes
+func templateADF(markdown string, opts Options) []*ADFNode {
+	if markdown == "" {
+		return nil
+	}
+	source := normalizeSource([]byte(markdown), opts)
+	opts = templateOptions(opts)
+	return NewADFRenderer(source, opts).Render(parseMarkdown(source, opts)).Content
+}
+
+// joinBlocks joins converted blocks of markup with blank lines, skipping empty ones
+func joinBlocks(blocks ...string) string {
+	var parts []string
+	for _, block := range blocks {
+		if block != "" {
+			parts = append(parts, block)
+		}
+	}
+	return strings.Join(parts, "\n\n")
+}
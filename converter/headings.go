@@ -0,0 +1,80 @@
+// Heading levels and titles
+// Shifts headings down with Options.HeadingOffset, for documents whose H1 is
+// the title of a page that already has one, such as an issue summary; levels
+// past h6., which JIRA does not have, are clamped. Options.StripTitle takes
+// the H1 out instead.
+
+package converter
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/yuin/goldmark/ast"
+	"github.com/yuin/goldmark/parser"
+	"github.com/yuin/goldmark/text"
+)
+
+// maxHeadingLevel is the deepest heading level of JIRA and ADF
+const maxHeadingLevel = 6
+
+// headingLevel returns the output level of a heading of the given level, and
+// false if it is deeper than maxHeadingLevel and was clamped
+func (o Options) headingLevel(level int) (int, bool) {
+	level += o.HeadingOffset
+	if level > maxHeadingLevel {
+		return maxHeadingLevel, false
+	}
+	return level, true
+}
+
+// clampedHeadingMessage is the warning about a heading clamped to h6.
+func clampedHeadingMessage(n *ast.Heading, offset int) string {
+	return fmt.Sprintf("heading level %d (%d + offset %d) rendered as h%d.", n.Level+offset, n.Level, offset, maxHeadingLevel)
+}
+
+// headingLevel returns the output level of a heading, warning when it is
+// clamped
+func (r *JIRARenderer) headingLevel(n *ast.Heading) int {
+	level, ok := r.options.headingLevel(n.Level)
+	if !ok {
+		r.addWarning(WarnHeadingLevel, clampedHeadingMessage(n, r.options.HeadingOffset))
+	}
+	return level
+}
+
+// headingLevel returns the output level of a heading, warning when it is
+// clamped
+func (r *ADFRenderer) headingLevel(n *ast.Heading) int {
+	level, ok := r.options.headingLevel(n.Level)
+	if !ok {
+		r.addWarning(n, WarnHeadingLevel, clampedHeadingMessage(n, r.options.HeadingOffset))
+	}
+	return level
+}
+
+// titleTransformer removes the first top-level H1 of a document, keeping its
+// text as the title in the document's meta
+type titleTransformer struct{}
+
+// Transform implements parser.ASTTransformer
+func (t *titleTransformer) Transform(doc *ast.Document, reader text.Reader, pc parser.Context) {
+	for n := doc.FirstChild(); n != nil; n = n.NextSibling() {
+		if h, ok := n.(*ast.Heading); ok && h.Level == 1 {
+			doc.AddMeta("title", strings.TrimSpace(plainText(reader.Source(), h)))
+			doc.RemoveChild(doc, h)
+			return
+		}
+	}
+}
+
+// documentTitle returns the title taken out of a document with
+// Options.StripTitle, or ""
+func documentTitle(doc ast.Node) string {
+	d, ok := doc.(*ast.Document)
+	if !ok {
+		return ""
+	}
+	title, _ := d.Meta()["title"].(string)
+	return title
+}
@@ -0,0 +1,649 @@
+// HTML conversion
+// Converts inline and block HTML into the nearest JIRA markup
+
+package converter
+
+import (
+	"fmt"
+	stdhtml "html"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/astsu-dev/md2jira/jiraescape"
+	"github.com/yuin/goldmark/ast"
+)
+
+// renderHTMLBlock renders an HTML block
+func (r *JIRARenderer) renderHTMLBlock(buf *strings.Builder, n *ast.HTMLBlock, entering bool) {
+	if entering {
+		choice, w := resolveDirective(r.options, r.source, n, WarnHTMLBlock, "HTML block found - converted with best effort", htmlBlockChoices)
+		if w != nil {
+			// Reported after the warnings of the conversion itself
+			defer func() { r.warnings = append(r.warnings, *w) }()
+		}
+		switch choice {
+		case ChoiceDrop:
+			return
+		case ChoiceNoformat:
+			buf.WriteString(r.noformatSource(n))
+			return
+		}
+		lines := n.Lines()
+		var html strings.Builder
+		for i := 0; i < lines.Len(); i++ {
+			line := lines.At(i)
+			html.Write(line.Value(r.source))
+		}
+		// The closing line of <pre>, <script> and similar blocks is kept separately
+		if n.HasClosure() {
+			html.Write(n.ClosureLine.Value(r.source))
+		}
+		clean := r.sanitizeHTML(html.String())
+		if r.options.PreserveHTML {
+			buf.WriteString(clean)
+		} else {
+			// Try to convert common HTML tags
+			converted := r.convertHTML(clean, true)
+			buf.WriteString(converted)
+		}
+	}
+}
+
+// renderRawHTML renders inline HTML
+func (r *JIRARenderer) renderRawHTML(buf *strings.Builder, n *ast.RawHTML, entering bool) {
+	if entering {
+		segments := n.Segments
+		var html strings.Builder
+		for i := 0; i < segments.Len(); i++ {
+			segment := segments.At(i)
+			html.Write(segment.Value(r.source))
+		}
+		clean := r.sanitizeHTML(html.String())
+		if r.inTableCell {
+			if text, ok := r.cellHTML(clean, buf.Len()); ok {
+				buf.WriteString(text)
+				return
+			}
+		}
+		// Links are tracked against buf, to tell those without text
+		if m := anchorTagRe.FindStringSubmatch(clean); m != nil && m[0] == strings.TrimSpace(clean) && r.options.compat(Compat9) {
+			if m[1] == "/" {
+				buf.WriteString(r.closeAnchor(buf.Len()))
+			} else {
+				buf.WriteString(r.openAnchor(m[0], buf.Len()))
+			}
+			return
+		}
+		// Formatting tags around inline content, such as the text of an
+		// <a href>, arrive one tag per node
+		if m := inlineTagRe.FindStringSubmatch(strings.TrimSpace(clean)); m != nil && r.options.compat(Compat12) {
+			buf.WriteString(inlineTagMarkers[strings.ToLower(m[2])][len(m[1])])
+			return
+		}
+		converted := r.convertHTML(clean, false)
+		buf.WriteString(converted)
+	}
+}
+
+// inlineTagRe matches a lone opening or closing formatting tag
+var inlineTagRe = regexp.MustCompile(`(?i)^<(/?)(strong|b|em|i|code|del|s|u|sup|sub)\s*>$`)
+
+// inlineTagMarkers are the JIRA markers that open and close the text of
+// formatting tags
+var inlineTagMarkers = map[string][2]string{
+	"strong": {"*", "*"},
+	"b":      {"*", "*"},
+	"em":     {"_", "_"},
+	"i":      {"_", "_"},
+	"code":   {"{{", "}}"},
+	"del":    {"-", "-"},
+	"s":      {"-", "-"},
+	"u":      {"+", "+"},
+	"sup":    {"^", "^"},
+	"sub":    {"~", "~"},
+}
+
+// sanitizeHTML applies the HTML sanitizer, warning when it removed anything
+func (r *JIRARenderer) sanitizeHTML(html string) string {
+	clean, changed := sanitizeHTML(r.options, html)
+	if changed && r.options.WarnOnUnsupported {
+		r.addWarning(WarnHTMLSanitized, "unsafe HTML removed by the sanitizer")
+	}
+	return clean
+}
+
+// convertHTML converts common HTML to JIRA markup; block is set for HTML blocks
+func (r *JIRARenderer) convertHTML(html string, block bool) string {
+	// Convert <pre> blocks first and keep them out of the remaining passes
+	html, preBlocks := r.extractPreBlocks(html)
+
+	// The text of untrusted HTML is escaped like Markdown text, so that
+	// [label|javascript:...], !data:...! and {macros} in it stay text
+	if r.options.SafeMode {
+		html = r.escapeHTMLText(html)
+	}
+
+	// Collapse &nbsp;-only and <p><br></p> spacer paragraphs
+	html = r.convertSpacers(html)
+
+	// Convert <video>, <audio> and <iframe> embeds to links or macros
+	html = r.convertMedia(html)
+
+	if r.options.compat(Compat9) {
+		// Convert <img> to !url|alt=text!
+		html = r.convertImages(html)
+
+		// Convert <a href> to [text|url]
+		html = r.convertAnchors(html)
+	}
+
+	// Convert <dl> definition lists to two-column tables
+	html = r.convertDefinitionLists(html)
+
+	// Degrade <font>, <span>, <big>, <small> and <center> to the nearest JIRA
+	// markup
+	html = r.convertLegacyStyles(html, block)
+
+	// Convert <sup> to ^text^
+	supRe := regexp.MustCompile(`<sup>([^<]*)</sup>`)
+	html = supRe.ReplaceAllString(html, "^$1^")
+
+	// Convert <sub> to ~text~
+	subRe := regexp.MustCompile(`<sub>([^<]*)</sub>`)
+	html = subRe.ReplaceAllString(html, "~$1~")
+
+	// Convert <br> and <br/> to \\
+	brRe := regexp.MustCompile(`<br\s*/?>`)
+	html = brRe.ReplaceAllString(html, "\\\\")
+
+	// Convert <strong> and <b> to *text*
+	strongRe := regexp.MustCompile(`<(?:strong|b)>([^<]*)</(?:strong|b)>`)
+	html = strongRe.ReplaceAllString(html, "*$1*")
+
+	// Convert <em> and <i> to _text_
+	emRe := regexp.MustCompile(`<(?:em|i)>([^<]*)</(?:em|i)>`)
+	html = emRe.ReplaceAllString(html, "_${1}_")
+
+	// Convert <code> to {{text}}
+	codeRe := regexp.MustCompile(`<code>([^<]*)</code>`)
+	html = codeRe.ReplaceAllString(html, "{{$1}}")
+
+	// Convert <del> and <s> to -text-
+	delRe := regexp.MustCompile(`<(?:del|s)>([^<]*)</(?:del|s)>`)
+	html = delRe.ReplaceAllString(html, "-$1-")
+
+	// Convert <u> to +text+
+	uRe := regexp.MustCompile(`<u>([^<]*)</u>`)
+	html = uRe.ReplaceAllString(html, "+$1+")
+
+	// Strip remaining HTML tags
+	tagRe := regexp.MustCompile(`<[^>]+>`)
+	html = tagRe.ReplaceAllString(html, "")
+
+	// Decode character references left after tag stripping
+	html = decodeEntities(html)
+
+	return restorePreBlocks(html, preBlocks)
+}
+
+// escapeHTMLText escapes the text between the tags of html, leaving tags and
+// character references, which are escaped when decoded, as they are
+func (r *JIRARenderer) escapeHTMLText(html string) string {
+	var ctx escapeContext
+	if r.inTableCell {
+		ctx |= ctxTableCell
+	}
+	escape := func(text string) string {
+		var b strings.Builder
+		last := 0
+		for _, ref := range entityRe.FindAllStringIndex(text, -1) {
+			b.WriteString(jiraescape.EscapeWithRules(text[last:ref[0]], ctx, r.options.EscapeMode, r.options.EscapeStyle, r.options.escapeRules()))
+			b.WriteString(text[ref[0]:ref[1]])
+			last = ref[1]
+		}
+		b.WriteString(jiraescape.EscapeWithRules(text[last:], ctx, r.options.EscapeMode, r.options.EscapeStyle, r.options.escapeRules()))
+		return b.String()
+	}
+	var b strings.Builder
+	last := 0
+	for _, tag := range htmlTagRe.FindAllStringIndex(html, -1) {
+		b.WriteString(escape(html[last:tag[0]]))
+		b.WriteString(html[tag[0]:tag[1]])
+		last = tag[1]
+	}
+	b.WriteString(escape(html[last:]))
+	return b.String()
+}
+
+// mediaRe matches media embeds, with their content when the closing tag is present
+var mediaRe = regexp.MustCompile(`(?is)<(video|audio|iframe)\b([^>]*)>(?:(.*?)</(?:video|audio|iframe)\s*>)?`)
+
+// sourceTagRe matches <source> elements inside media embeds
+var sourceTagRe = regexp.MustCompile(`(?is)<source\b[^>]*>`)
+
+// mediaLabels are the link labels used for each media element
+var mediaLabels = map[string]string{
+	"video":  "Video",
+	"audio":  "Audio",
+	"iframe": "Embedded content",
+}
+
+// htmlAttr returns the value of the named attribute in an HTML tag
+func htmlAttr(tag, name string) string {
+	re := regexp.MustCompile(`(?is)\s` + regexp.QuoteMeta(name) + `\s*=\s*(?:"([^"]*)"|'([^']*)'|([^\s"'>]+))`)
+	m := re.FindStringSubmatch(tag)
+	if m == nil {
+		return ""
+	}
+	return stdhtml.UnescapeString(m[1] + m[2] + m[3])
+}
+
+// mediaURLReplacer percent-encodes the characters of a media source URL that
+// would end the macro parameter or link it is written in, as they cannot be
+// escaped there
+var mediaURLReplacer = strings.NewReplacer("|", "%7C", "[", "%5B", "]", "%5D", "{", "%7B", "}", "%7D")
+
+// convertMedia converts <video>, <audio> and <iframe> elements, keeping the source URL
+func (r *JIRARenderer) convertMedia(html string) string {
+	return mediaRe.ReplaceAllStringFunc(html, func(match string) string {
+		m := mediaRe.FindStringSubmatch(match)
+		element := strings.ToLower(m[1])
+		tag := "<" + m[1] + m[2] + ">"
+
+		url := htmlAttr(tag, "src")
+		if url == "" {
+			if source := sourceTagRe.FindString(m[3]); source != "" {
+				url = htmlAttr(source, "src")
+			}
+		}
+		if url == "" {
+			if r.options.WarnOnUnsupported {
+				r.addWarning(WarnMediaNoSource, "<"+element+"> element without a source URL removed")
+			}
+			return ""
+		}
+
+		label := mediaLabels[element]
+		if title := htmlAttr(tag, "title"); title != "" {
+			label = title
+			if r.options.compat(Compat12) {
+				label = r.escapeJIRAText(title, ctxLinkLabel)
+			}
+		}
+
+		url, ok := r.options.safeURL(url)
+		if !ok {
+			return label
+		}
+		if r.options.compat(Compat12) {
+			url = mediaURLReplacer.Replace(url)
+		}
+		if r.options.MediaMacro != "" {
+			return "{" + r.options.MediaMacro + ":url=" + url + "}"
+		}
+		if r.options.WarnOnUnsupported {
+			r.addWarning(WarnMediaLink, "<"+element+"> embed converted to a link: "+url)
+		}
+		return "[▶ " + label + "|" + url + "]"
+	})
+}
+
+// imgRe matches <img> elements
+var imgRe = regexp.MustCompile(`(?is)<img\b[^>]*>`)
+
+// imageSizeRe matches the pixel sizes of <img> width and height attributes
+var imageSizeRe = regexp.MustCompile(`^\s*(\d+)\s*(?:px)?\s*$`)
+
+// convertImages converts <img> elements to images, keeping their alt text,
+// width and height; local images are attached as Markdown images are
+func (r *JIRARenderer) convertImages(html string) string {
+	return imgRe.ReplaceAllStringFunc(html, func(tag string) string {
+		src := strings.TrimSpace(htmlAttr(tag, "src"))
+		alt := htmlAttr(tag, "alt")
+		if src == "" {
+			return alt
+		}
+		url, ok := r.attachImage(src)
+		if !ok {
+			if url, ok = r.options.safeURL(src); !ok {
+				return alt
+			}
+		}
+		if alt == "" && r.options.AltText != nil {
+			var err error
+			if alt, err = r.options.generatedAltText(src); err != nil && r.options.WarnOnUnsupported {
+				r.addWarning(WarnAltText, fmt.Sprintf("no alt text generated for %s: %v", src, err))
+			}
+		}
+		attrs := r.imageAttributes(alt, imageSize(htmlAttr(tag, "width")), imageSize(htmlAttr(tag, "height")))
+		if len(attrs) > 0 {
+			return "!" + url + "|" + strings.Join(attrs, ",") + "!"
+		}
+		return "!" + url + "!"
+	})
+}
+
+// imageSize returns a pixel size of an <img> attribute, or 0 for sizes such
+// as 50% that JIRA images cannot take
+func imageSize(value string) int {
+	m := imageSizeRe.FindStringSubmatch(value)
+	if m == nil {
+		return 0
+	}
+	n, _ := strconv.Atoi(m[1])
+	return n
+}
+
+// anchorTagRe matches opening and closing <a> tags
+var anchorTagRe = regexp.MustCompile(`(?is)<(/?)a\b([^>]*)>`)
+
+// htmlLink is an <a> tag whose link is not closed yet
+type htmlLink struct {
+	// url is the target, or "" when the tag has no safe href
+	url string
+	// start is the length of the output where the link text starts
+	start int
+}
+
+// convertAnchors converts <a href> links one tag at a time, so that inline
+// tags split across RawHTML nodes still pair up through r.htmlLinks
+func (r *JIRARenderer) convertAnchors(html string) string {
+	var out strings.Builder
+	last := 0
+	for _, loc := range anchorTagRe.FindAllStringSubmatchIndex(html, -1) {
+		out.WriteString(html[last:loc[0]])
+		if loc[3] > loc[2] {
+			out.WriteString(r.closeAnchor(out.Len()))
+		} else {
+			out.WriteString(r.openAnchor(html[loc[0]:loc[1]], out.Len()))
+		}
+		last = loc[1]
+	}
+	out.WriteString(html[last:])
+	return out.String()
+}
+
+// openAnchor starts the link of an <a> tag; written is the length of the
+// output so far
+func (r *JIRARenderer) openAnchor(tag string, written int) string {
+	url, ok := r.options.safeURL(anchorHref(tag))
+	if !ok || url == "" {
+		// The text alone, as there is no target or it would run a script
+		r.htmlLinks = append(r.htmlLinks, htmlLink{start: written})
+		return ""
+	}
+	if r.options.compat(Compat12) {
+		url = hrefURLReplacer.Replace(url)
+	}
+	prefix := "["
+	if r.options.LinkStyle == LinkStyleEndnotes && !isFragment(url) {
+		prefix = ""
+	}
+	r.htmlLinks = append(r.htmlLinks, htmlLink{url: url, start: written + len(prefix)})
+	return prefix
+}
+
+// hrefReplacer removes the tabs and newlines browsers ignore in URLs, which
+// would break the link markup
+var hrefReplacer = strings.NewReplacer("\t", "", "\n", "", "\r", "")
+
+// hrefURLReplacer percent-encodes the characters of an href that would end
+// the [text|url] link it is written in
+var hrefURLReplacer = strings.NewReplacer("|", "%7C", "]", "%5D")
+
+// anchorHref returns the href of an <a> tag
+func anchorHref(tag string) string {
+	return hrefReplacer.Replace(strings.TrimSpace(htmlAttr(tag, "href")))
+}
+
+// closeAnchor ends the link of the innermost open <a> tag; written is the
+// length of the output so far
+func (r *JIRARenderer) closeAnchor(written int) string {
+	if len(r.htmlLinks) == 0 {
+		return ""
+	}
+	link := r.htmlLinks[len(r.htmlLinks)-1]
+	r.htmlLinks = r.htmlLinks[:len(r.htmlLinks)-1]
+	bare := written == link.start
+	endnote := r.options.LinkStyle == LinkStyleEndnotes && !isFragment(link.url)
+	switch {
+	case link.url == "":
+		return ""
+	case bare:
+		prefix, title := "", link.url
+		if endnote {
+			prefix = "["
+		}
+		if t, ok := r.linkTitle(link.url); ok {
+			title = t + "|" + link.url
+		}
+		return prefix + title + "]"
+	case endnote:
+		return fmt.Sprintf(" \\[%d\\]", r.endnoteIndex(link.url))
+	}
+	return "|" + link.url + "]"
+}
+
+// definitionListRe matches <dl> elements
+var definitionListRe = regexp.MustCompile(`(?is)<dl\b[^>]*>(.*?)</dl\s*>`)
+
+// definitionItemRe matches <dt> and <dd> opening tags
+var definitionItemRe = regexp.MustCompile(`(?is)<(dt|dd)\b[^>]*>`)
+
+// definitionCloseRe matches </dt> and </dd> closing tags
+var definitionCloseRe = regexp.MustCompile(`(?is)</(?:dt|dd)\s*>`)
+
+// convertDefinitionLists converts <dl> lists into ||term|definition| table rows
+func (r *JIRARenderer) convertDefinitionLists(html string) string {
+	return definitionListRe.ReplaceAllStringFunc(html, func(match string) string {
+		body := definitionListRe.FindStringSubmatch(match)[1]
+		items := definitionItemRe.FindAllStringSubmatchIndex(body, -1)
+
+		var out strings.Builder
+		var terms, defs []string
+		flush := func() {
+			if len(terms) > 0 || len(defs) > 0 {
+				out.WriteString("||" + strings.Join(terms, "\\\\") + "|" + strings.Join(defs, "\\\\") + "|\n")
+			}
+			terms, defs = nil, nil
+		}
+		for i, item := range items {
+			end := len(body)
+			if i+1 < len(items) {
+				end = items[i+1][0]
+			}
+			content := definitionCloseRe.ReplaceAllString(body[item[1]:end], "")
+			// Inline tags and entities are converted by the remaining convertHTML passes
+			content = strings.Join(strings.Fields(content), " ")
+			content = strings.ReplaceAll(content, "|", "\\|")
+			if strings.EqualFold(body[item[2]:item[3]], "dt") {
+				// A term after definitions starts a new row
+				if len(defs) > 0 {
+					flush()
+				}
+				terms = append(terms, content)
+			} else {
+				defs = append(defs, content)
+			}
+		}
+		flush()
+		return out.String()
+	})
+}
+
+// preRe matches <pre> elements
+var preRe = regexp.MustCompile(`(?is)<pre\b[^>]*>(.*?)</pre\s*>`)
+
+// preCodeRe matches a <code> element wrapping the whole <pre> content
+var preCodeRe = regexp.MustCompile(`(?is)^\s*<code\b([^>]*)>(.*)</code\s*>\s*$`)
+
+// codeClassLangRe extracts the language from class="language-x" or class="lang-x"
+var codeClassLangRe = regexp.MustCompile(`(?:^|\s)lang(?:uage)?-([^\s]+)`)
+
+// prePlaceholder marks where an extracted <pre> block is restored
+const prePlaceholder = "\x00pre%d\x00"
+
+// extractPreBlocks converts <pre> blocks to {code} or {noformat} macros and
+// replaces them with placeholders so later passes leave their whitespace intact
+func (r *JIRARenderer) extractPreBlocks(html string) (string, []string) {
+	var blocks []string
+	html = preRe.ReplaceAllStringFunc(html, func(match string) string {
+		content := preRe.FindStringSubmatch(match)[1]
+		macro := "{noformat}"
+		closing := "{noformat}"
+		if m := preCodeRe.FindStringSubmatch(content); m != nil {
+			content = m[2]
+			macro, closing = "{code}", "{code}"
+			if lang := codeClassLangRe.FindStringSubmatch(htmlAttr("<code"+m[1]+">", "class")); lang != nil {
+				macro = r.codeMacro(lang[1])
+			}
+		}
+		// Syntax highlighting markup is dropped; the text is kept verbatim
+		content = htmlTagRe.ReplaceAllString(content, "")
+		content = stdhtml.UnescapeString(content)
+		// A newline directly after <pre> is not part of the content
+		content = strings.TrimPrefix(strings.TrimPrefix(content, "\r"), "\n")
+		if !strings.HasSuffix(content, "\n") {
+			content += "\n"
+		}
+		// A {noformat} in the text would end the block early, and {code}
+		// keeps it verbatim
+		if closing == "{noformat}" && r.options.compat(Compat12) &&
+			containsMacroTag(content, "noformat") && !containsMacroTag(content, "code") {
+			macro, closing = "{code}", "{code}"
+		}
+		blocks = append(blocks, macro+"\n"+r.options.macroBody(strings.Trim(closing, "{}"), content)+closing+"\n")
+		return fmt.Sprintf(prePlaceholder, len(blocks)-1)
+	})
+	return html, blocks
+}
+
+// containsMacroTag reports whether text contains a tag of the named macro
+func containsMacroTag(text, macro string) bool {
+	for _, m := range macroTagRe.FindAllStringSubmatch(text, -1) {
+		if strings.EqualFold(m[1], macro) {
+			return true
+		}
+	}
+	return false
+}
+
+// restorePreBlocks puts converted <pre> blocks back in place of their placeholders
+func restorePreBlocks(html string, blocks []string) string {
+	for i, block := range blocks {
+		html = strings.Replace(html, fmt.Sprintf(prePlaceholder, i), block, 1)
+	}
+	return html
+}
+
+// legacyStyleRe matches opening and closing <font>, <span>, <big>, <small>
+// and <center> tags
+var legacyStyleRe = regexp.MustCompile(`(?is)<(/?)(font|span|big|small|center)\b([^>]*)>`)
+
+// jiraColorRe matches color values accepted by {color}: names and hex codes
+var jiraColorRe = regexp.MustCompile(`^#?[0-9A-Za-z]+$`)
+
+// styleColorRe matches the color declaration of a style attribute, but not
+// background-color
+var styleColorRe = regexp.MustCompile(`(?i)(?:^|;)\s*color\s*:\s*([^;]*)`)
+
+// rgbColorRe matches rgb(r, g, b) colors
+var rgbColorRe = regexp.MustCompile(`(?i)^rgba?\(\s*(\d{1,3})\s*,\s*(\d{1,3})\s*,\s*(\d{1,3})\s*(?:,[^)]*)?\)$`)
+
+// jiraColor returns value as a {color} parameter, converting rgb() colors
+// to hex codes, and false if JIRA does not accept it
+func jiraColor(value string) (string, bool) {
+	value = strings.TrimSpace(value)
+	if m := rgbColorRe.FindStringSubmatch(value); m != nil {
+		hex := "#"
+		for _, c := range m[1:] {
+			n, _ := strconv.Atoi(c)
+			hex += fmt.Sprintf("%02x", min(n, 255))
+		}
+		return hex, true
+	}
+	return value, jiraColorRe.MatchString(value)
+}
+
+// convertLegacyStyles converts legacy styling tags one tag at a time, so that
+// inline tags split across RawHTML nodes still pair up through r.fontColors
+func (r *JIRARenderer) convertLegacyStyles(html string, block bool) string {
+	return legacyStyleRe.ReplaceAllStringFunc(html, func(match string) string {
+		m := legacyStyleRe.FindStringSubmatch(match)
+		closing, element := m[1] == "/", strings.ToLower(m[2])
+		tag := "<" + m[2] + m[3] + ">"
+
+		switch element {
+		case "font":
+			if closing {
+				return r.closeColor()
+			}
+			color, colored := jiraColor(htmlAttr(tag, "color"))
+			if r.options.WarnOnUnsupported {
+				for _, attr := range []string{"size", "face"} {
+					if htmlAttr(tag, attr) != "" {
+						r.addWarning(WarnLegacyStyle, "<font "+attr+"> has no JIRA equivalent and was dropped")
+					}
+				}
+				if color != "" && !colored {
+					r.addWarning(WarnLegacyStyle, "<font> color "+color+" is not a JIRA color and was dropped")
+				}
+			}
+			r.fontColors = append(r.fontColors, colored)
+			if colored {
+				return "{color:" + color + "}"
+			}
+			return ""
+		case "span":
+			// Spans pair up like <font>, whether they set a color or not
+			if closing {
+				return r.closeColor()
+			}
+			var color string
+			if m := styleColorRe.FindStringSubmatch(htmlAttr(tag, "style")); m != nil && r.options.compat(Compat9) {
+				color = strings.TrimSuffix(strings.TrimSpace(m[1]), "!important")
+			}
+			color, colored := jiraColor(color)
+			if color != "" && !colored && r.options.WarnOnUnsupported {
+				r.addWarning(WarnLegacyStyle, "<span> color "+color+" is not a JIRA color and was dropped")
+			}
+			r.fontColors = append(r.fontColors, colored)
+			if colored {
+				return "{color:" + color + "}"
+			}
+			return ""
+		case "center":
+			if block {
+				if closing {
+					return "{div}"
+				}
+				return "{div:style=text-align:center}"
+			}
+			if !closing && r.options.WarnOnUnsupported {
+				r.addWarning(WarnLegacyStyle, "inline <center> cannot be centered in JIRA and was dropped")
+			}
+			return ""
+		default:
+			// JIRA has no relative text sizes
+			if !closing && r.options.WarnOnUnsupported {
+				r.addWarning(WarnLegacyStyle, "<"+element+"> text size has no JIRA equivalent and was dropped")
+			}
+			return ""
+		}
+	})
+}
+
+// closeColor closes the innermost open <font> or <span> tag, ending its
+// {color} if it emitted one
+func (r *JIRARenderer) closeColor() string {
+	if len(r.fontColors) == 0 {
+		return ""
+	}
+	colored := r.fontColors[len(r.fontColors)-1]
+	r.fontColors = r.fontColors[:len(r.fontColors)-1]
+	if colored {
+		return "{color}"
+	}
+	return ""
+}
@@ -0,0 +1,118 @@
+package converter
+
+import "testing"
+
+func TestMediaEmbeds(t *testing.T) {
+	tests := []struct {
+		name     string
+		markdown string
+		opts     Options
+		want     string
+	}{
+		{
+			name:     "video as a link",
+			markdown: `<video src="https://v.example/demo.mp4"></video>`,
+			want:     "[▶ Video|https://v.example/demo.mp4]",
+		},
+		{
+			name:     "iframe as a macro",
+			markdown: `<iframe src="https://x.example/embed"></iframe>`,
+			opts:     Options{MediaMacro: "widget"},
+			want:     "{widget:url=https://x.example/embed}",
+		},
+		{
+			name:     "macro URL cannot end the macro",
+			markdown: `<iframe src="https://x.example/e?a=1|b}{color:red}x"></iframe>`,
+			opts:     Options{MediaMacro: "widget"},
+			want:     "{widget:url=https://x.example/e?a=1%7Cb%7D%7Bcolor:red%7Dx}",
+		},
+		{
+			name:     "link URL cannot end the link",
+			markdown: `<video src="https://v.example/a]b|c.mp4"></video>`,
+			want:     "[▶ Video|https://v.example/a%5Db%7Cc.mp4]",
+		},
+		{
+			name:     "title cannot end the link",
+			markdown: `<iframe src="https://x.example/e" title="Demo] [x|javascript:y"></iframe>`,
+			want:     `[▶ Demo\] \[x\|javascript:y|https://x.example/e]`,
+		},
+		{
+			name:     "script URL in safe mode",
+			markdown: `<iframe src="javascript:alert(1)" title="Demo"></iframe>`,
+			opts:     Options{SafeMode: true, HTMLSanitizer: passSanitizer{}},
+			want:     "Demo",
+		},
+		{
+			name:     "macro in safe mode",
+			markdown: `<iframe src="https://x.example/e|x"></iframe>`,
+			opts:     Options{SafeMode: true, MediaMacro: "widget"},
+			want:     "[▶ Embedded content|https://x.example/e%7Cx]",
+		},
+		{
+			name:     "URL and title before Compat12",
+			markdown: `<video src="https://v.example/a|b.mp4" title="t|x"></video>`,
+			opts:     Options{CompatLevel: Compat11},
+			want:     "[▶ t|x|https://v.example/a|b.mp4]",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := render(t, tt.markdown, tt.opts); got != tt.want {
+				t.Errorf("got %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestHTMLAnchors(t *testing.T) {
+	tests := []struct {
+		name     string
+		markdown string
+		level    CompatLevel
+		want     string
+	}{
+		{"link", `<a href="http://x/a">t</a>`, CompatLatest, "[t|http://x/a]"},
+		{"href cannot end the link", `<a href="http://x/a|b]c">t</a>`, CompatLatest, "[t|http://x/a%7Cb%5Dc]"},
+		{"bare link", `<a href="http://x/a|b"></a>`, CompatLatest, "[http://x/a%7Cb]"},
+		{"formatted text", `x <a href="http://x/a">t <b>b</b> <em>e</em> <code>c</code></a>`, CompatLatest, "x [t *b* _e_ {{c}}|http://x/a]"},
+		{"formatting outside links", "a <strong>b</strong> <del>c</del>", CompatLatest, "a *b* -c-"},
+		{"before Compat12", `<a href="http://x/a|b">t <b>b</b></a>`, Compat11, "[t b|http://x/a|b]"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := render(t, tt.markdown, Options{CompatLevel: tt.level}); got != tt.want {
+				t.Errorf("got %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestPreBlocks(t *testing.T) {
+	tests := []struct {
+		name     string
+		markdown string
+		level    CompatLevel
+		want     string
+	}{
+		{"noformat", "<pre>  a\n    b</pre>", CompatLatest, "{noformat}\n  a\n    b\n{noformat}"},
+		{"code with a language", `<pre><code class="language-go">x := 1</code></pre>`, CompatLatest, "{code:go}\nx := 1\n{code}"},
+		{"noformat in the text", "<pre>x {noformat} y</pre>", CompatLatest, "{code}\nx {noformat} y\n{code}"},
+		{"noformat with a title in the text", "<pre>{noformat:title=t}</pre>", CompatLatest, "{code}\n{noformat:title=t}\n{code}"},
+		{"noformat and code in the text", "<pre>{noformat} {code}</pre>", CompatLatest, "{noformat}\n{noformat} {code}\n{noformat}"},
+		{"noformat in the text before Compat12", "<pre>x {noformat} y</pre>", Compat11, "{noformat}\nx {noformat} y\n{noformat}"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := render(t, tt.markdown, Options{CompatLevel: tt.level}); got != tt.want {
+				t.Errorf("got %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+// passSanitizer is an HTMLSanitizer that keeps the HTML as it is
+type passSanitizer struct{}
+
+func (passSanitizer) Sanitize(html string) string {
+	return html
+}
@@ -0,0 +1,24 @@
+package converter
+
+import "testing"
+
+func TestImageAltText(t *testing.T) {
+	tests := []struct {
+		name     string
+		markdown string
+		level    CompatLevel
+		want     string
+	}{
+		{"markdown image", "![a|b!, c](a.png)", CompatLatest, "!a.png|alt=a b c!"},
+		{"html image", `<img alt="a|b!" src="a.png">`, CompatLatest, "!a.png|alt=a b!"},
+		{"html image with a width", `<img alt="x, y" src="a.png" width="300">`, CompatLatest, "!a.png|width=300,alt=x y!"},
+		{"markdown image before Compat12", "![a|b!](a.png)", Compat11, "!a.png|alt=a|b!!"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := render(t, tt.markdown, Options{CompatLevel: tt.level}); got != tt.want {
+				t.Errorf("got %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,78 @@
+// Inserted text
+// Parses the ++inserted++ convention (markdown-it-ins) and renders it as
+// JIRA underline
+
+package converter
+
+import (
+	"strings"
+
+	"github.com/yuin/goldmark/ast"
+	"github.com/yuin/goldmark/parser"
+	"github.com/yuin/goldmark/text"
+)
+
+// kindInserted is the node kind of ++inserted++ text
+var kindInserted = ast.NewNodeKind("Inserted")
+
+// inserted is ++inserted++ text; its children are the inserted inlines
+type inserted struct {
+	ast.BaseInline
+}
+
+// Kind implements ast.Node
+func (n *inserted) Kind() ast.NodeKind {
+	return kindInserted
+}
+
+// Dump implements ast.Node
+func (n *inserted) Dump(source []byte, level int) {
+	ast.DumpHelper(n, source, level, nil, nil)
+}
+
+// insertedDelimiter is the delimiter processor of ++
+type insertedDelimiter struct{}
+
+// IsDelimiter implements parser.DelimiterProcessor
+func (p *insertedDelimiter) IsDelimiter(b byte) bool {
+	return b == '+'
+}
+
+// CanOpenCloser implements parser.DelimiterProcessor
+func (p *insertedDelimiter) CanOpenCloser(opener, closer *parser.Delimiter) bool {
+	return opener.Char == closer.Char
+}
+
+// OnMatch implements parser.DelimiterProcessor
+func (p *insertedDelimiter) OnMatch(consumes int) ast.Node {
+	return &inserted{}
+}
+
+// insertedParser is a goldmark inline parser for ++inserted++. Like ~~strike~~
+// it follows the emphasis flanking rules, so C++ and a++ stay text; only runs
+// of exactly two plus signs are delimiters.
+type insertedParser struct{}
+
+// Trigger implements parser.InlineParser
+func (p *insertedParser) Trigger() []byte {
+	return []byte{'+'}
+}
+
+// Parse implements parser.InlineParser
+func (p *insertedParser) Parse(parent ast.Node, block text.Reader, pc parser.Context) ast.Node {
+	before := block.PrecendingCharacter()
+	line, segment := block.PeekLine()
+	node := parser.ScanDelimiter(line, before, 2, &insertedDelimiter{})
+	if node == nil || node.OriginalLength != 2 || before == '+' {
+		return nil
+	}
+	node.Segment = segment.WithStop(segment.Start + node.OriginalLength)
+	block.Advance(node.OriginalLength)
+	pc.PushDelimiter(node)
+	return node
+}
+
+// renderInserted renders inserted text as +underline+
+func (r *JIRARenderer) renderInserted(buf *strings.Builder, n *inserted, entering bool) {
+	buf.WriteString(r.effectMarker(n, "+"))
+}
@@ -0,0 +1,86 @@
+// Issue keys
+// JIRA links bare issue keys such as PROJ-123 by itself, so they are never
+// escaped; with Options.IssueBaseURL they become explicit links, which also
+// work where JIRA does not link them, e.g. in other sites' markup
+
+package converter
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/astsu-dev/md2jira/jiraescape"
+)
+
+// issueKeyIndex returns the byte offsets of the issue keys of text that are
+// linked: those of Options.IssueProjects, or all of them if none are set
+func (o Options) issueKeyIndex(text string) [][]int {
+	if o.IssueBaseURL == "" {
+		return nil
+	}
+	keys := jiraescape.IssueKeyIndex(text)
+	if len(o.IssueProjects) == 0 {
+		return keys
+	}
+	linked := keys[:0]
+	for _, key := range keys {
+		project, _, _ := strings.Cut(text[key[0]:key[1]], "-")
+		for _, p := range o.IssueProjects {
+			if p == project {
+				linked = append(linked, key)
+				break
+			}
+		}
+	}
+	return linked
+}
+
+// issueURL returns the URL of an issue on the Options.IssueBaseURL site
+func (o Options) issueURL(key string) string {
+	return strings.TrimRight(o.IssueBaseURL, "/") + "/browse/" + key
+}
+
+// issueText escapes text, rendering its issue keys as links with
+// Options.IssueBaseURL
+func (r *JIRARenderer) issueText(text string, ctx escapeContext) string {
+	keys := r.options.issueKeyIndex(text)
+	if len(keys) == 0 {
+		return r.escapeJIRAText(text, ctx)
+	}
+	var b strings.Builder
+	last := 0
+	for _, key := range keys {
+		b.WriteString(r.escapeJIRAText(text[last:key[0]], ctx))
+		// Only the text before the first key can start a line
+		ctx &^= ctxLineStart
+		issue := text[key[0]:key[1]]
+		if r.options.LinkStyle == LinkStyleEndnotes {
+			fmt.Fprintf(&b, "%s \\[%d\\]", issue, r.endnoteIndex(r.options.issueURL(issue)))
+		} else {
+			fmt.Fprintf(&b, "[%s|%s]", issue, r.options.issueURL(issue))
+		}
+		last = key[1]
+	}
+	b.WriteString(r.escapeJIRAText(text[last:], ctx))
+	return b.String()
+}
+
+// issueNodes renders text as ADF text nodes, with link marks on its issue
+// keys with Options.IssueBaseURL
+func (r *ADFRenderer) issueNodes(text string, marks []ADFMark) []*ADFNode {
+	var nodes []*ADFNode
+	last := 0
+	for _, key := range r.options.issueKeyIndex(text) {
+		if key[0] > last {
+			nodes = append(nodes, textNode(text[last:key[0]], marks))
+		}
+		issue := text[key[0]:key[1]]
+		link := ADFMark{Type: "link", Attrs: map[string]any{"href": r.options.issueURL(issue)}}
+		nodes = append(nodes, textNode(issue, withMark(marks, link)))
+		last = key[1]
+	}
+	if last < len(text) {
+		nodes = append(nodes, textNode(text[last:], marks))
+	}
+	return nodes
+}
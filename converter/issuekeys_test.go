@@ -0,0 +1,32 @@
+package converter
+
+import "testing"
+
+func TestIssueKeys(t *testing.T) {
+	tests := []struct {
+		name     string
+		markdown string
+		opts     Options
+		want     string
+	}{
+		{"keys stay unescaped", "See PROJ-123.", Options{}, "See PROJ-123."},
+		{"linked keys", "See PROJ-123 and AB-1.", Options{IssueBaseURL: "https://j.example"},
+			"See [PROJ-123|https://j.example/browse/PROJ-123] and [AB-1|https://j.example/browse/AB-1]."},
+		{"base URL with a slash", "PROJ-1", Options{IssueBaseURL: "https://j.example/"},
+			"[PROJ-1|https://j.example/browse/PROJ-1]"},
+		{"limited to projects", "PROJ-1 and UTF-8", Options{IssueBaseURL: "https://j.example", IssueProjects: []string{"PROJ"}},
+			"[PROJ-1|https://j.example/browse/PROJ-1] and UTF-8"},
+		{"not in code or links", "`PROJ-1` and [PROJ-2](http://x)", Options{IssueBaseURL: "https://j.example"},
+			"{{PROJ-1}} and [PROJ-2|http://x]"},
+		{"not inside words", "XPROJ-3", Options{IssueBaseURL: "https://j.example", IssueProjects: []string{"PROJ"}}, "XPROJ-3"},
+		{"inside emphasis", "*PROJ-1*", Options{IssueBaseURL: "https://j.example"},
+			"_[PROJ-1|https://j.example/browse/PROJ-1]_"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := render(t, tt.markdown, tt.opts); got != tt.want {
+				t.Errorf("got %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,171 @@
+// Dead link detection
+// Verifies relative link targets on disk and, optionally, absolute URLs over HTTP
+
+package converter
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/yuin/goldmark/ast"
+)
+
+// linkCheckTimeout bounds each remote link check
+const linkCheckTimeout = 10 * time.Second
+
+// LinkChecker checks link destinations found in a document
+type LinkChecker struct {
+	baseDir string
+	remote  bool
+	client  *http.Client
+	checked map[string]bool
+	// options supplies the cache, metrics and logger of the conversion
+	options Options
+}
+
+// NewLinkChecker creates a link checker resolving relative links against baseDir
+func NewLinkChecker(baseDir string, remote bool) *LinkChecker {
+	return &LinkChecker{
+		baseDir: baseDir,
+		remote:  remote,
+		client:  &http.Client{Timeout: linkCheckTimeout},
+		checked: make(map[string]bool),
+	}
+}
+
+// newLinkChecker creates the link checker of a conversion
+func newLinkChecker(opts Options) *LinkChecker {
+	c := NewLinkChecker(opts.BaseDir, opts.CheckRemoteLinks)
+	c.client.Transport = opts.HTTPTransport
+	c.options = opts
+	return c
+}
+
+// Check walks the AST and returns a warning for every broken link
+func (c *LinkChecker) Check(doc ast.Node, source []byte) []Warning {
+	var warnings []Warning
+	_ = ast.Walk(doc, func(node ast.Node, entering bool) (ast.WalkStatus, error) {
+		if !entering {
+			return ast.WalkContinue, nil
+		}
+		var dest string
+		switch n := node.(type) {
+		case *ast.Link:
+			dest = string(n.Destination)
+		case *ast.Image:
+			dest = string(n.Destination)
+		case *ast.AutoLink:
+			if n.AutoLinkType != ast.AutoLinkURL {
+				return ast.WalkContinue, nil
+			}
+			dest = string(n.URL(source))
+		default:
+			return ast.WalkContinue, nil
+		}
+		if dest == "" || c.checked[dest] {
+			return ast.WalkContinue, nil
+		}
+		c.checked[dest] = true
+		if err := c.checkDestination(dest); err != nil {
+			warnings = append(warnings, newWarning(source, node, WarnBrokenLink, fmt.Sprintf("Broken link %s: %v", dest, err)))
+		}
+		return ast.WalkContinue, nil
+	})
+	return warnings
+}
+
+// checkDestination checks a single link destination
+func (c *LinkChecker) checkDestination(dest string) error {
+	u, err := url.Parse(dest)
+	if err != nil {
+		return err
+	}
+	switch {
+	case u.Scheme == "http" || u.Scheme == "https":
+		if !c.remote {
+			return nil
+		}
+		return c.checkRemote(dest)
+	case u.Scheme != "" || u.Host != "":
+		// mailto:, ftp:, etc. are not checked
+		return nil
+	case u.Path == "":
+		// Fragment-only links point into the same document
+		return nil
+	}
+
+	path, err := url.PathUnescape(u.Path)
+	if err != nil {
+		path = u.Path
+	}
+	if !filepath.IsAbs(path) {
+		path = filepath.Join(c.baseDir, filepath.FromSlash(path))
+	}
+	if _, err := os.Stat(path); err != nil {
+		if os.IsNotExist(err) {
+			return fmt.Errorf("file %s does not exist", path)
+		}
+		return err
+	}
+	return nil
+}
+
+// checkRemote checks an absolute URL, remembering the outcome in the cache
+// of the conversion, if any. Only answers of the server are remembered:
+// transport errors, 5xx and 429 responses may pass, and are checked again by
+// the next conversion.
+func (c *LinkChecker) checkRemote(dest string) error {
+	key := cacheKey("link", dest)
+	if cached, ok := c.options.cacheGet(key); ok {
+		c.options.count("md2jira.linkcheck.cache_hits", 1, nil)
+		if len(cached) == 0 {
+			return nil
+		}
+		return fmt.Errorf("%s", cached)
+	}
+	c.options.debugf("checking link %s", dest)
+	start := time.Now()
+	lasting, err := c.fetch(dest)
+	if c.options.Metrics != nil {
+		c.options.Metrics.Timing("md2jira.linkcheck.duration", time.Since(start), nil)
+	}
+	if !lasting {
+		return err
+	}
+	var cached []byte
+	if err != nil {
+		cached = []byte(err.Error())
+	}
+	c.options.cacheSet(key, cached)
+	return err
+}
+
+// fetch performs a HEAD request, falling back to GET for servers that reject
+// HEAD, and reports whether the outcome lasts: false for transport errors and
+// responses that ask to retry later
+func (c *LinkChecker) fetch(dest string) (bool, error) {
+	resp, err := c.client.Head(dest)
+	if err == nil && (resp.StatusCode == http.StatusMethodNotAllowed || resp.StatusCode == http.StatusNotImplemented) {
+		resp.Body.Close()
+		resp, err = c.client.Get(dest)
+	}
+	if err != nil {
+		// Strip the method/URL prefix added by net/http
+		msg := err.Error()
+		if i := strings.LastIndex(msg, ": "); i >= 0 {
+			msg = msg[i+2:]
+		}
+		return false, fmt.Errorf("%s", msg)
+	}
+	defer resp.Body.Close()
+	lasting := resp.StatusCode < 500 && resp.StatusCode != http.StatusTooManyRequests
+	if resp.StatusCode >= 400 {
+		return lasting, fmt.Errorf("HTTP %s", resp.Status)
+	}
+	return lasting, nil
+}
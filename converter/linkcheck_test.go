@@ -0,0 +1,146 @@
+package converter
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"testing"
+)
+
+// mapCache is an in-memory Cache
+type mapCache map[string][]byte
+
+func (c mapCache) Get(key string) ([]byte, bool) {
+	value, ok := c[key]
+	return value, ok
+}
+
+func (c mapCache) Set(key string, value []byte) {
+	c[key] = value
+}
+
+func TestCheckRemoteCache(t *testing.T) {
+	statuses := map[string]int{
+		"/ok":       http.StatusOK,
+		"/moved":    http.StatusMovedPermanently,
+		"/missing":  http.StatusNotFound,
+		"/down":     http.StatusServiceUnavailable,
+		"/limited":  http.StatusTooManyRequests,
+		"/internal": http.StatusInternalServerError,
+	}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/moved" {
+			w.Header().Set("Location", "/ok")
+		}
+		w.WriteHeader(statuses[r.URL.Path])
+	}))
+	defer server.Close()
+
+	tests := []struct {
+		path       string
+		wantErr    bool
+		wantCached bool
+	}{
+		{"/ok", false, true},
+		{"/moved", false, true},
+		{"/missing", true, true},
+		{"/down", true, false},
+		{"/limited", true, false},
+		{"/internal", true, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			cache := mapCache{}
+			c := newLinkChecker(Options{CheckRemoteLinks: true, Cache: cache})
+			err := c.checkRemote(server.URL + tt.path)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("checkRemote error = %v, want error %v", err, tt.wantErr)
+			}
+			if cached := len(cache) > 0; cached != tt.wantCached {
+				t.Errorf("cached = %v, want %v", cached, tt.wantCached)
+			}
+		})
+	}
+}
+
+func TestCheckRemoteTransportErrorNotCached(t *testing.T) {
+	server := httptest.NewServer(http.NotFoundHandler())
+	url := server.URL + "/gone"
+	server.Close()
+
+	cache := mapCache{}
+	c := newLinkChecker(Options{CheckRemoteLinks: true, Cache: cache})
+	if err := c.checkRemote(url); err == nil {
+		t.Fatal("checkRemote of a closed server succeeded")
+	}
+	if len(cache) != 0 {
+		t.Errorf("transport error was cached: %q", cache)
+	}
+}
+
+func TestCheckLinksWarnings(t *testing.T) {
+	var heads, gets int
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.URL.Path == "/head-only-get" && r.Method == http.MethodHead:
+			heads++
+			w.WriteHeader(http.StatusMethodNotAllowed)
+		case r.URL.Path == "/head-only-get":
+			gets++
+		case r.URL.Path != "/ok":
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "present.md"), nil, 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	markdown := "[a](present.md) [b](missing.md) [c](#top) [d](mailto:a@example.com)\n\n" +
+		"[e](" + server.URL + "/ok) [f](" + server.URL + "/gone) ![g](" + server.URL + "/head-only-get)\n\n" +
+		"[again](" + server.URL + "/gone) <" + server.URL + "/autolink>\n"
+	tests := []struct {
+		name   string
+		remote bool
+		want   []string
+	}{
+		{"local", false, []string{"missing.md"}},
+		{"remote", true, []string{server.URL + "/autolink", server.URL + "/gone", "missing.md"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			heads, gets = 0, 0
+			result, err := ConvertWithOptions(markdown, Options{
+				CheckLinks:       true,
+				CheckRemoteLinks: tt.remote,
+				BaseDir:          dir,
+				HTTPTransport:    server.Client().Transport,
+			})
+			if err != nil {
+				t.Fatal(err)
+			}
+			var broken []string
+			for _, w := range result.Warnings {
+				if w.Code == WarnBrokenLink {
+					broken = append(broken, w.Message)
+				}
+			}
+			sort.Strings(broken)
+			if len(broken) != len(tt.want) {
+				t.Fatalf("broken links = %q, want %q", broken, tt.want)
+			}
+			for i, dest := range tt.want {
+				if !strings.HasPrefix(broken[i], "Broken link "+dest+":") {
+					t.Errorf("warning %d = %q, want one for %s", i, broken[i], dest)
+				}
+			}
+			if tt.remote && (heads != 1 || gets != 1) {
+				t.Errorf("%d HEAD and %d GET requests to a server rejecting HEAD, want 1 each", heads, gets)
+			}
+		})
+	}
+}
@@ -0,0 +1,163 @@
+// Link styles
+// Renders links inline or as numbered references with a trailing "Links" section
+
+package converter
+
+import (
+	"fmt"
+	"net/url"
+	"regexp"
+	"strings"
+)
+
+// LinkStyle controls how links with a label are rendered
+type LinkStyle int
+
+const (
+	// LinkStyleInline renders links as [label|url]
+	LinkStyleInline LinkStyle = iota
+	// LinkStyleEndnotes renders links as "label [n]" and lists URLs in a trailing section
+	LinkStyleEndnotes
+)
+
+// ParseLinkStyle parses a link style name (inline or endnotes)
+func ParseLinkStyle(name string) (LinkStyle, error) {
+	switch strings.ToLower(name) {
+	case "inline":
+		return LinkStyleInline, nil
+	case "endnotes":
+		return LinkStyleEndnotes, nil
+	}
+	return LinkStyleInline, fmt.Errorf("unknown link style %q (want inline or endnotes)", name)
+}
+
+// endnoteIndex returns the reference number for a URL, assigning one if needed
+func (r *JIRARenderer) endnoteIndex(url string) int {
+	for i, existing := range r.endnotes {
+		if existing == url {
+			return i + 1
+		}
+	}
+	r.endnotes = append(r.endnotes, url)
+	return len(r.endnotes)
+}
+
+// renderEndnotes renders the trailing "Links" section
+func (r *JIRARenderer) renderEndnotes(buf *strings.Builder) {
+	if len(r.endnotes) == 0 {
+		return
+	}
+	buf.WriteString("h4. Links\n\n")
+	for i, url := range r.endnotes {
+		fmt.Fprintf(buf, "\\[%d\\] [%s]\n", i+1, url)
+	}
+}
+
+// LinkTitler derives a human-readable title for a bare URL
+type LinkTitler interface {
+	// Title returns the title for a URL, or false if none is known
+	Title(url string) (string, bool)
+}
+
+// URLStructureTitler derives titles for well-known systems from the URL alone
+type URLStructureTitler struct{}
+
+var (
+	// githubRe matches GitHub pull request, issue and commit URLs
+	githubRe = regexp.MustCompile(`^/([^/]+)/([^/]+)/(pull|issues|commit)/([^/]+)`)
+	// gitlabRe matches GitLab merge request and issue URLs
+	gitlabRe = regexp.MustCompile(`^/(.+?)/-/(merge_requests|issues)/(\d+)`)
+	// confluenceDisplayRe matches Confluence Server page URLs
+	confluenceDisplayRe = regexp.MustCompile(`/display/[^/]+/([^/]+)$`)
+	// confluencePagesRe matches Confluence Cloud page URLs
+	confluencePagesRe = regexp.MustCompile(`/spaces/[^/]+/pages/\d+/([^/]+)$`)
+	// jiraBrowseRe matches JIRA issue URLs
+	jiraBrowseRe = regexp.MustCompile(`/browse/([A-Z][A-Z0-9_]+-\d+)$`)
+)
+
+// googleDocTitles maps Google Docs path prefixes to document kinds
+var googleDocTitles = map[string]string{
+	"document":     "Google Doc",
+	"spreadsheets": "Google Sheet",
+	"presentation": "Google Slides",
+	"forms":        "Google Form",
+}
+
+// Title implements LinkTitler
+func (URLStructureTitler) Title(rawURL string) (string, bool) {
+	u, err := url.Parse(rawURL)
+	if err != nil || u.Host == "" {
+		return "", false
+	}
+	host := strings.TrimPrefix(strings.ToLower(u.Host), "www.")
+	p := strings.TrimSuffix(u.Path, "/")
+
+	switch {
+	case host == "github.com":
+		if m := githubRe.FindStringSubmatch(p); m != nil {
+			repo := m[1] + "/" + m[2]
+			if m[3] == "commit" {
+				return repo + "@" + shortSHA(m[4]), true
+			}
+			return repo + "#" + m[4], true
+		}
+	case host == "gitlab.com" || strings.HasPrefix(host, "gitlab."):
+		if m := gitlabRe.FindStringSubmatch(p); m != nil {
+			if m[2] == "merge_requests" {
+				return m[1] + "!" + m[3], true
+			}
+			return m[1] + "#" + m[3], true
+		}
+	case host == "docs.google.com":
+		kind := strings.SplitN(strings.TrimPrefix(p, "/"), "/", 2)[0]
+		if title, ok := googleDocTitles[kind]; ok {
+			return title, true
+		}
+	case host == "drive.google.com":
+		return "Google Drive file", true
+	}
+
+	if m := confluenceDisplayRe.FindStringSubmatch(p); m != nil {
+		return pageTitle(m[1]), true
+	}
+	if m := confluencePagesRe.FindStringSubmatch(p); m != nil {
+		return pageTitle(m[1]), true
+	}
+	if m := jiraBrowseRe.FindStringSubmatch(p); m != nil {
+		return m[1], true
+	}
+	return "", false
+}
+
+// shortSHA abbreviates a commit hash
+func shortSHA(sha string) string {
+	if len(sha) > 7 {
+		return sha[:7]
+	}
+	return sha
+}
+
+// pageTitle turns a Confluence URL path segment into a page title
+func pageTitle(segment string) string {
+	segment = strings.ReplaceAll(segment, "+", " ")
+	if unescaped, err := url.PathUnescape(segment); err == nil {
+		segment = unescaped
+	}
+	return strings.TrimSpace(segment)
+}
+
+// linkTitle returns the enriched display text for a bare URL, if enabled and known
+func (r *JIRARenderer) linkTitle(url string) (string, bool) {
+	titler := r.options.LinkTitler
+	if titler == nil {
+		if !r.options.EnrichLinks {
+			return "", false
+		}
+		titler = URLStructureTitler{}
+	}
+	title, ok := titler.Title(url)
+	if !ok || title == "" {
+		return "", false
+	}
+	return escapeJIRA(title, ctxLinkLabel, r.options.EscapeMode, r.options), true
+}
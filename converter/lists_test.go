@@ -0,0 +1,61 @@
+package converter
+
+import "testing"
+
+func TestListItemBlocks(t *testing.T) {
+	tests := []struct {
+		name     string
+		markdown string
+		level    CompatLevel
+		want     string
+	}{
+		{
+			name:     "nested list starting an item",
+			markdown: "- - x",
+			want:     "* &nbsp;\n** x",
+		},
+		{
+			name:     "nested lists starting an item",
+			markdown: "- - - x\n  - y",
+			want:     "* &nbsp;\n** &nbsp;\n*** x\n** y",
+		},
+		{
+			name:     "ordered list starting an item",
+			markdown: "1. - x\n2. y",
+			want:     "# &nbsp;\n#* x\n# y",
+		},
+		{
+			name:     "code block starting an item",
+			markdown: "- ```\n  code\n  ```\n- y",
+			want:     "* &nbsp;\n{code}\ncode\n{code}\n* y",
+		},
+		{
+			name:     "paragraphs of an item",
+			markdown: "- a\n\n  b\n- c",
+			want:     "* a\\\\\nb\n* c",
+		},
+		{
+			name:     "nested list after text",
+			markdown: "- a\n  - b",
+			want:     "* a\n** b",
+		},
+		{
+			name:     "heading starting an item",
+			markdown: "- # h",
+			want:     "* h1. h",
+		},
+		{
+			name:     "nested list starting an item before Compat12",
+			markdown: "- - x",
+			level:    Compat11,
+			want:     "* ** x",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := render(t, tt.markdown, Options{CompatLevel: tt.level}); got != tt.want {
+				t.Errorf("got %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,248 @@
+// Math
+// Parses $inline$ and $$display$$ LaTeX (goldmark-mathjax style) and renders
+// it with a math macro, or as {noformat} and monospace where there is none
+
+package converter
+
+import (
+	"bytes"
+	"regexp"
+	"strings"
+
+	"github.com/yuin/goldmark/ast"
+	"github.com/yuin/goldmark/parser"
+	"github.com/yuin/goldmark/text"
+	"github.com/yuin/goldmark/util"
+)
+
+// kindMathBlock and kindMathInline are the node kinds of math
+var (
+	kindMathBlock  = ast.NewNodeKind("MathBlock")
+	kindMathInline = ast.NewNodeKind("MathInline")
+)
+
+// mathBlock is a $$ display math block; its lines hold the LaTeX source
+type mathBlock struct {
+	ast.BaseBlock
+	// closed is set once the closing $$ has been read
+	closed bool
+}
+
+// Kind implements ast.Node
+func (n *mathBlock) Kind() ast.NodeKind {
+	return kindMathBlock
+}
+
+// Dump implements ast.Node
+func (n *mathBlock) Dump(source []byte, level int) {
+	ast.DumpHelper(n, source, level, nil, nil)
+}
+
+// IsRaw implements ast.Node; the content is LaTeX, not Markdown
+func (n *mathBlock) IsRaw() bool {
+	return true
+}
+
+// mathInline is $inline$ (or $$display$$) math within a paragraph
+type mathInline struct {
+	ast.BaseInline
+	// Segment is the LaTeX source between the dollar signs
+	Segment text.Segment
+}
+
+// Kind implements ast.Node
+func (n *mathInline) Kind() ast.NodeKind {
+	return kindMathInline
+}
+
+// Dump implements ast.Node
+func (n *mathInline) Dump(source []byte, level int) {
+	ast.DumpHelper(n, source, level, map[string]string{"Segment": string(n.Segment.Value(source))}, nil)
+}
+
+// mathBlockParser is a goldmark block parser for $$ display math
+type mathBlockParser struct{}
+
+// Trigger implements parser.BlockParser
+func (p *mathBlockParser) Trigger() []byte {
+	return []byte{'$'}
+}
+
+// Open implements parser.BlockParser; the math may start on the opening line
+// and may end on it ($$ x^2 $$)
+func (p *mathBlockParser) Open(parent ast.Node, reader text.Reader, pc parser.Context) (ast.Node, parser.State) {
+	line, segment := reader.PeekLine()
+	pos := pc.BlockOffset()
+	if pos < 0 || !bytes.HasPrefix(line[pos:], []byte("$$")) {
+		return nil, parser.NoChildren
+	}
+	node := &mathBlock{}
+	rest := segment.WithStart(segment.Start + pos + 2)
+	rest = rest.TrimRightSpace(reader.Source())
+	if value := rest.Value(reader.Source()); bytes.HasSuffix(value, []byte("$$")) {
+		rest = rest.WithStop(rest.Stop - 2)
+		node.closed = true
+	}
+	if !util.IsBlank(rest.Value(reader.Source())) {
+		node.Lines().Append(rest.TrimLeftSpace(reader.Source()))
+	}
+	advanceLine(reader, line, segment)
+	return node, parser.NoChildren
+}
+
+// Continue implements parser.BlockParser; a line ending in $$ closes the block
+func (p *mathBlockParser) Continue(node ast.Node, reader text.Reader, pc parser.Context) parser.State {
+	n := node.(*mathBlock)
+	if n.closed {
+		return parser.Close
+	}
+	line, segment := reader.PeekLine()
+	if line == nil {
+		return parser.Close
+	}
+	trimmed := segment.TrimRightSpace(reader.Source())
+	if bytes.HasSuffix(trimmed.Value(reader.Source()), []byte("$$")) {
+		if content := trimmed.WithStop(trimmed.Stop - 2); !util.IsBlank(content.Value(reader.Source())) {
+			n.Lines().Append(content)
+		}
+		n.closed = true
+		advanceLine(reader, line, segment)
+		return parser.Close
+	}
+	n.Lines().Append(segment)
+	advanceLine(reader, line, segment)
+	return parser.Continue | parser.NoChildren
+}
+
+// advanceLine moves the reader to the end of the line, before its newline
+func advanceLine(reader text.Reader, line []byte, segment text.Segment) {
+	length := segment.Len()
+	if len(line) > 0 && line[len(line)-1] == '\n' {
+		length--
+	}
+	reader.Advance(length)
+}
+
+// Close implements parser.BlockParser
+func (p *mathBlockParser) Close(node ast.Node, reader text.Reader, pc parser.Context) {}
+
+// CanInterruptParagraph implements parser.BlockParser
+func (p *mathBlockParser) CanInterruptParagraph() bool {
+	return false
+}
+
+// CanAcceptIndentedLine implements parser.BlockParser
+func (p *mathBlockParser) CanAcceptIndentedLine() bool {
+	return false
+}
+
+// mathInlineParser is a goldmark inline parser for $inline$ math. As in
+// pandoc, the opening $ must not be followed by a space and the closing $
+// must not follow a space or precede a digit; in addition a $ followed by a
+// number and a space or punctuation is a price, so "$5 and $10" stays text.
+type mathInlineParser struct{}
+
+// priceRe matches an amount of money such as $10 or $1,500.00, at the start of
+// the text
+var priceRe = regexp.MustCompile(`^\$[0-9](?:[0-9.,]*[0-9])?(?:[\s,;:!?)]|\.(?:\s|$)|$)`)
+
+// Trigger implements parser.InlineParser
+func (p *mathInlineParser) Trigger() []byte {
+	return []byte{'$'}
+}
+
+// Parse implements parser.InlineParser
+func (p *mathInlineParser) Parse(parent ast.Node, block text.Reader, pc parser.Context) ast.Node {
+	line, segment := block.PeekLine()
+	delim := 1
+	if len(line) > 1 && line[1] == '$' {
+		delim = 2
+	}
+	if len(line) <= delim || line[delim] == '$' || (delim == 1 && (util.IsSpace(line[1]) || priceRe.Match(line))) {
+		return nil
+	}
+	for i := delim + 1; i+delim <= len(line); i++ {
+		switch {
+		case line[i] == '\\':
+			i++
+		case line[i] == '$' && bytes.HasPrefix(line[i:], []byte("$$")[:delim]):
+			after := i + delim
+			if after < len(line) && line[after] == '$' {
+				return nil
+			}
+			if delim == 1 && (util.IsSpace(line[i-1]) || (after < len(line) && line[after] >= '0' && line[after] <= '9')) {
+				continue
+			}
+			node := &mathInline{Segment: text.NewSegment(segment.Start+delim, segment.Start+i)}
+			block.Advance(after)
+			return node
+		}
+	}
+	return nil
+}
+
+// mathText returns the LaTeX source of a math node or ```math fence, without
+// a trailing newline
+func mathText(source []byte, node ast.Node) string {
+	if n, ok := node.(*mathInline); ok {
+		return strings.TrimSpace(string(n.Segment.Value(source)))
+	}
+	lines := node.Lines()
+	latex := make([]string, lines.Len())
+	for i := range latex {
+		segment := lines.At(i)
+		latex[i] = strings.TrimRight(string(segment.Value(source)), "\r\n")
+	}
+	return strings.Join(latex, "\n")
+}
+
+// isMathFence reports whether a fenced code block is a ```math block, which
+// GitHub renders like $$ display math
+func (o Options) isMathFence(lang string) bool {
+	return o.compat(Compat3) && strings.EqualFold(lang, "math")
+}
+
+// renderMathBlock renders display math as the math macro, or as {noformat}
+func (r *JIRARenderer) renderMathBlock(buf *strings.Builder, n ast.Node) {
+	macro := r.options.MathMacro
+	if macro == "" {
+		macro = "noformat"
+		if r.options.WarnOnUnsupported {
+			r.addWarning(WarnMath, "math block rendered as {noformat}: no math macro is set")
+		}
+	}
+	buf.WriteString("{" + macro + "}\n" + r.options.macroBody(macro, mathText(r.source, n)) + "\n{" + macro + "}\n\n")
+}
+
+// mathBlock renders display math as a LaTeX code block, as ADF has no math
+func (r *ADFRenderer) mathBlock(n ast.Node) *ADFNode {
+	if r.options.WarnOnUnsupported {
+		r.addWarning(n, WarnMath, "math block rendered as a code block: ADF has no math")
+	}
+	node := &ADFNode{Type: "codeBlock", Attrs: map[string]any{"language": "latex"}}
+	if latex := mathText(r.source, n); latex != "" {
+		node.Content = []*ADFNode{textNode(latex, nil)}
+	}
+	return node
+}
+
+// renderMathInline renders inline math as the math macro, or as monospace
+func (r *JIRARenderer) renderMathInline(buf *strings.Builder, n *mathInline) {
+	latex := mathText(r.source, n)
+	if macro := r.options.MathMacro; macro != "" {
+		buf.WriteString("{" + macro + "}" + r.options.macroBody(macro, latex) + "{" + macro + "}")
+		return
+	}
+	if r.options.WarnOnUnsupported {
+		r.addWarning(WarnMath, "inline math rendered as monospace: no math macro is set")
+	}
+	buf.WriteString("{{" + r.escapeJIRAText(latex, textContext(n)|ctxCode) + "}}")
+}
+
+// mathInline renders inline math as code, as ADF has no math
+func (r *ADFRenderer) mathInline(n *mathInline, marks []ADFMark) *ADFNode {
+	if r.options.WarnOnUnsupported {
+		r.addWarning(n, WarnMath, "inline math rendered as code: ADF has no math")
+	}
+	return textNode(mathText(r.source, n), withMark(marks, ADFMark{Type: "code"}))
+}
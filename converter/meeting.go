@@ -0,0 +1,188 @@
+// Meeting notes
+// Renders "Action items" sections as checkbox lines with mentions and due dates
+
+package converter
+
+import (
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/yuin/goldmark/ast"
+	east "github.com/yuin/goldmark/extension/ast"
+)
+
+// ActionItem is an action item found in an "Action items" section
+type ActionItem struct {
+	// Text is the item's text without its checkbox
+	Text string `json:"text"`
+	// Owner is the first @handle mentioned in the item ("" if none)
+	Owner string `json:"owner,omitempty"`
+	// Assignee is the JIRA user Options.Mentions maps Owner to ("" if unknown)
+	Assignee string `json:"assignee,omitempty"`
+	// Due is the date after "by" as YYYY-MM-DD ("" if the item has none)
+	Due  string `json:"due,omitempty"`
+	Done bool   `json:"done"`
+	// Line is the 1-based source line of the item
+	Line int `json:"line"`
+}
+
+// actionHeadingRe matches the heading of an action items section
+var actionHeadingRe = regexp.MustCompile(`(?i)^\s*(?:action items?|actions|next steps)\s*:?\s*$`)
+
+// mentionRe matches an @handle
+var mentionRe = regexp.MustCompile(`(^|[\s(])@([A-Za-z0-9][\w.-]*[A-Za-z0-9_]|[A-Za-z0-9])`)
+
+// dueRe matches a due date: "by" followed by a weekday, today, tomorrow or an ISO date
+var dueRe = regexp.MustCompile(`(?i)\bby (today|tomorrow|monday|tuesday|wednesday|thursday|friday|saturday|sunday|\d{4}-\d{2}-\d{2})\b`)
+
+// isActionList reports whether a list holds action items: in meeting notes
+// mode, every top-level list of an "Action items" section
+func (r *JIRARenderer) isActionList(n ast.Node) bool {
+	list, ok := n.(*ast.List)
+	if !ok || !r.options.MeetingNotes {
+		return false
+	}
+	if _, top := list.Parent().(*ast.Document); !top {
+		return false
+	}
+	return inActionSection(r.source, list)
+}
+
+// inActionSection reports whether a top-level node belongs to an "Action items"
+// section, directly or through its subsections
+func inActionSection(source []byte, n ast.Node) bool {
+	level := 7
+	for s := n.PreviousSibling(); s != nil && level > 1; s = s.PreviousSibling() {
+		h, ok := s.(*ast.Heading)
+		if !ok || h.Level >= level {
+			continue
+		}
+		if actionHeadingRe.MatchString(plainText(source, h)) {
+			return true
+		}
+		level = h.Level
+	}
+	return false
+}
+
+// codeSpanRe matches a {{monospace}} span, which keeps mentions and dates as written
+var codeSpanRe = regexp.MustCompile(`\{\{.*?\}\}`)
+
+// renderActionList renders an action items list as checkbox lines
+func (r *JIRARenderer) renderActionList(buf *strings.Builder, list *ast.List) {
+	r.listStack = append(r.listStack, list)
+	r.inTightList = list.IsTight
+	for child := list.FirstChild(); child != nil; child = child.NextSibling() {
+		var item strings.Builder
+		if taskCheckBox(child) == nil {
+			item.WriteString(r.checkbox(false))
+		}
+		r.renderChildren(&item, child)
+		buf.WriteString(r.actionText(strings.TrimRight(item.String(), "\n")) + "\n")
+	}
+	r.listStack = r.listStack[:len(r.listStack)-1]
+	r.inTightList = false
+	buf.WriteString("\n")
+}
+
+// actionText replaces mentions of known users with [~user] and due dates
+// with {{date}} lozenges in rendered action item text
+func (r *JIRARenderer) actionText(text string) string {
+	var out strings.Builder
+	last := 0
+	for _, span := range append(codeSpanRe.FindAllStringIndex(text, -1), []int{len(text), len(text)}) {
+		out.WriteString(r.replaceActions(text[last:span[0]]))
+		out.WriteString(text[span[0]:span[1]])
+		last = span[1]
+	}
+	return out.String()
+}
+
+// replaceActions converts the mentions and due dates of text outside code spans
+func (r *JIRARenderer) replaceActions(text string) string {
+	text = mentionRe.ReplaceAllStringFunc(text, func(m string) string {
+		sub := mentionRe.FindStringSubmatch(m)
+		if user, ok := r.options.Mentions[strings.ToLower(sub[2])]; ok {
+			return sub[1] + "[~" + user + "]"
+		}
+		return m
+	})
+	return dueRe.ReplaceAllStringFunc(text, func(m string) string {
+		due, ok := dueDate(dueRe.FindStringSubmatch(m)[1], r.options.MeetingDate)
+		if !ok {
+			return m
+		}
+		return "by {{" + due.Format("2006-01-02") + "}}"
+	})
+}
+
+// dueDate resolves a due date phrase relative to the meeting date (default today)
+func dueDate(phrase string, meeting time.Time) (time.Time, bool) {
+	if meeting.IsZero() {
+		meeting = time.Now()
+	}
+	day := time.Date(meeting.Year(), meeting.Month(), meeting.Day(), 0, 0, 0, 0, time.UTC)
+	phrase = strings.ToLower(phrase)
+	switch phrase {
+	case "today":
+		return day, true
+	case "tomorrow":
+		return day.AddDate(0, 0, 1), true
+	}
+	for wd := time.Sunday; wd <= time.Saturday; wd++ {
+		if strings.ToLower(wd.String()) == phrase {
+			// The next such weekday after the meeting
+			ahead := (int(wd) - int(day.Weekday()) + 6) % 7
+			return day.AddDate(0, 0, ahead+1), true
+		}
+	}
+	t, err := time.Parse("2006-01-02", phrase)
+	return t, err == nil
+}
+
+// taskCheckBox returns the checkbox of a task list item, or nil
+func taskCheckBox(item ast.Node) *east.TaskCheckBox {
+	block := item.FirstChild()
+	if block == nil {
+		return nil
+	}
+	box, _ := block.FirstChild().(*east.TaskCheckBox)
+	return box
+}
+
+// collectActionItems returns the action items of a document
+func collectActionItems(doc ast.Node, source []byte, opts Options) []ActionItem {
+	if !opts.MeetingNotes {
+		return nil
+	}
+	var items []ActionItem
+	for node := doc.FirstChild(); node != nil; node = node.NextSibling() {
+		list, ok := node.(*ast.List)
+		if !ok || !inActionSection(source, list) {
+			continue
+		}
+		for child := list.FirstChild(); child != nil; child = child.NextSibling() {
+			block := child.FirstChild()
+			if block == nil {
+				continue
+			}
+			item := ActionItem{Text: strings.Join(strings.Fields(plainText(source, block)), " ")}
+			item.Line, _ = nodePosition(source, block)
+			if box := taskCheckBox(child); box != nil {
+				item.Done = box.IsChecked
+			}
+			if m := mentionRe.FindStringSubmatch(item.Text); m != nil {
+				item.Owner = m[2]
+				item.Assignee = opts.Mentions[strings.ToLower(m[2])]
+			}
+			if m := dueRe.FindStringSubmatch(item.Text); m != nil {
+				if due, ok := dueDate(m[1], opts.MeetingDate); ok {
+					item.Due = due.Format("2006-01-02")
+				}
+			}
+			items = append(items, item)
+		}
+	}
+	return items
+}
@@ -0,0 +1,96 @@
+// Mentions
+// Converts the @handles of a document into JIRA user mentions through the
+// Options.Mentions mapping: [~name] on Server and Data Center, [~accountid:...]
+// on Cloud, and mention nodes in ADF
+
+package converter
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/yuin/goldmark/ast"
+)
+
+// splitMentions splits text at its @handles: even elements are the text
+// between them and odd elements the handles, without the @
+func splitMentions(text string) []string {
+	var parts []string
+	last := 0
+	for _, m := range mentionRe.FindAllStringSubmatchIndex(text, -1) {
+		at := m[4] - 1
+		parts = append(parts, text[last:at], text[m[4]:m[5]])
+		last = m[5]
+	}
+	return append(parts, text[last:])
+}
+
+// mentionUser returns the JIRA user a handle is mapped to
+func (o Options) mentionUser(handle string) (string, bool) {
+	user, ok := o.Mentions[strings.ToLower(handle)]
+	return user, ok && user != ""
+}
+
+// mentionText escapes text, rendering its mapped @handles as [~user]
+// mentions and warning about the others, which stay as written
+func (r *JIRARenderer) mentionText(text string, ctx escapeContext) string {
+	parts := splitMentions(text)
+	if len(parts) == 1 {
+		return r.issueText(text, ctx)
+	}
+	// Unmapped handles are escaped with the text around them
+	var b, run strings.Builder
+	for i, part := range parts {
+		if i%2 == 0 {
+			run.WriteString(part)
+			continue
+		}
+		user, ok := r.options.mentionUser(part)
+		if !ok {
+			r.addWarning(WarnMention, fmt.Sprintf("@%s is not mapped to a JIRA user; left as text", part))
+			run.WriteString("@" + part)
+			continue
+		}
+		b.WriteString(r.issueText(run.String(), ctx))
+		b.WriteString("[~" + user + "]")
+		run.Reset()
+		ctx &^= ctxLineStart
+	}
+	b.WriteString(r.issueText(run.String(), ctx))
+	return b.String()
+}
+
+// mentionNodes renders text as ADF text and mention nodes. ADF mentions
+// need an account ID, so handles mapped to user names stay text too.
+func (r *ADFRenderer) mentionNodes(node ast.Node, text string, marks []ADFMark) []*ADFNode {
+	var nodes []*ADFNode
+	for i, part := range splitMentions(text) {
+		if i%2 == 0 {
+			nodes = append(nodes, r.issueNodes(part, marks)...)
+			continue
+		}
+		user, ok := r.options.mentionUser(part)
+		id, cloud := strings.CutPrefix(user, "accountid:")
+		switch {
+		case ok && cloud:
+			nodes = append(nodes, &ADFNode{Type: "mention", Attrs: map[string]any{"id": id, "text": "@" + part}})
+			continue
+		case ok:
+			r.addWarning(node, WarnMention, fmt.Sprintf("@%s is mapped to %s, but ADF mentions need an account ID (accountid:...); left as text", part, user))
+		default:
+			r.addWarning(node, WarnMention, fmt.Sprintf("@%s is not mapped to a JIRA user; left as text", part))
+		}
+		nodes = append(nodes, textNode("@"+part, marks))
+	}
+	return nodes
+}
+
+// hasLinkMark reports whether marks put text inside a link
+func hasLinkMark(marks []ADFMark) bool {
+	for _, mark := range marks {
+		if mark.Type == "link" {
+			return true
+		}
+	}
+	return false
+}
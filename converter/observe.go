@@ -0,0 +1,123 @@
+// Embedding hooks
+// Caching, metrics and logging backends supplied by services that embed the
+// converter; all of them are optional and nil means none
+
+package converter
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"time"
+)
+
+// Cache stores results that are expensive to compute, such as remote link
+// checks and rendered diagrams, across conversions. Implementations must be
+// safe for concurrent use and decide themselves when entries expire.
+type Cache interface {
+	// Get returns the value stored under key, and false if there is none
+	Get(key string) ([]byte, bool)
+	// Set stores value under key
+	Set(key string, value []byte)
+}
+
+// MetricsSink receives conversion metrics; names are dot separated and start
+// with md2jira.
+type MetricsSink interface {
+	// Count adds delta to the counter name
+	Count(name string, delta int64, tags map[string]string)
+	// Timing records the duration of an operation
+	Timing(name string, d time.Duration, tags map[string]string)
+}
+
+// Logger receives diagnostic messages; the converter itself never writes to
+// stdout or stderr
+type Logger interface {
+	Debugf(format string, args ...any)
+	Warnf(format string, args ...any)
+}
+
+// cacheKey builds a cache key from a namespace and the parts identifying the
+// value; the parts are hashed so keys stay short
+func cacheKey(namespace string, parts ...string) string {
+	h := sha256.New()
+	for _, part := range parts {
+		h.Write([]byte(part))
+		h.Write([]byte{0})
+	}
+	return "md2jira:" + namespace + ":" + hex.EncodeToString(h.Sum(nil)[:16])
+}
+
+// cacheGet looks up key in the cache, if any
+func (o Options) cacheGet(key string) ([]byte, bool) {
+	if o.Cache == nil {
+		return nil, false
+	}
+	return o.Cache.Get(key)
+}
+
+// cacheSet stores value under key in the cache, if any
+func (o Options) cacheSet(key string, value []byte) {
+	if o.Cache != nil {
+		o.Cache.Set(key, value)
+	}
+}
+
+// count adds delta to a counter of the metrics sink, if any
+func (o Options) count(name string, delta int64, tags map[string]string) {
+	if o.Metrics != nil {
+		o.Metrics.Count(name, delta, tags)
+	}
+}
+
+// debugf logs a debug message to the logger, if any
+func (o Options) debugf(format string, args ...any) {
+	if o.Logger != nil {
+		o.Logger.Debugf(format, args...)
+	}
+}
+
+// warnf logs a warning to the logger, if any
+func (o Options) warnf(format string, args ...any) {
+	if o.Logger != nil {
+		o.Logger.Warnf(format, args...)
+	}
+}
+
+// observeConversion reports a finished conversion: its duration, its output
+// size and a counter per warning code
+func (o Options) observeConversion(format string, start time.Time, outputLen int, warnings []Warning) {
+	elapsed := time.Since(start)
+	if o.Metrics != nil {
+		tags := map[string]string{"format": format}
+		o.Metrics.Timing("md2jira.convert.duration", elapsed, tags)
+		o.Metrics.Count("md2jira.convert.documents", 1, tags)
+		o.Metrics.Count("md2jira.convert.output_bytes", int64(outputLen), tags)
+		for _, w := range warnings {
+			o.Metrics.Count("md2jira.convert.warnings", 1, map[string]string{"format": format, "code": string(w.Code)})
+		}
+	}
+	o.debugf("converted to %s in %s: %d bytes, %d warnings", format, elapsed.Round(time.Microsecond), outputLen, len(warnings))
+}
+
+// cachedDiagram renders a diagram through the cache, keyed by the renderer,
+// the language and the source
+func (o Options) cachedDiagram(lang, source string) (string, error) {
+	key := cacheKey("diagram", fmt.Sprintf("%T%+v", o.DiagramRenderer, o.DiagramRenderer), lang, source)
+	if ref, ok := o.cacheGet(key); ok {
+		o.count("md2jira.diagram.cache_hits", 1, map[string]string{"lang": lang})
+		return string(ref), nil
+	}
+	o.debugf("rendering %s diagram with %T", lang, o.DiagramRenderer)
+	start := time.Now()
+	ref, err := o.DiagramRenderer.RenderDiagram(lang, source)
+	if o.Metrics != nil {
+		o.Metrics.Timing("md2jira.diagram.duration", time.Since(start), map[string]string{"lang": lang})
+	}
+	if err != nil {
+		o.warnf("rendering %s diagram: %v", lang, err)
+		return "", err
+	}
+	o.cacheSet(key, []byte(ref))
+	return ref, nil
+}
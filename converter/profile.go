@@ -0,0 +1,135 @@
+// Block profiling
+// Measures the rendering time and output size of each top-level block and
+// the time spent in each node kind, to find what makes a document slow
+
+package converter
+
+import (
+	"sort"
+	"time"
+
+	"github.com/yuin/goldmark/ast"
+)
+
+// Profile is the rendering cost of a document, recorded with
+// Options.ProfileBlocks
+type Profile struct {
+	// Parse is the time spent parsing the Markdown
+	Parse time.Duration `json:"parse_ns"`
+	// Blocks are the top-level blocks, in document order
+	Blocks []BlockProfile `json:"blocks"`
+	// Kinds are the node kinds, slowest first
+	Kinds []KindProfile `json:"kinds"`
+}
+
+// BlockProfile is the rendering cost of a top-level block
+type BlockProfile struct {
+	Kind string `json:"kind"`
+	Line int    `json:"line"`
+	// Duration includes the nested blocks and inlines
+	Duration    time.Duration `json:"duration_ns"`
+	OutputBytes int           `json:"output_bytes"`
+}
+
+// KindProfile is the cumulative rendering time of the nodes of a kind
+type KindProfile struct {
+	Kind  string `json:"kind"`
+	Count int    `json:"count"`
+	// Duration excludes the time spent in child nodes, which is counted
+	// for their own kinds
+	Duration time.Duration `json:"duration_ns"`
+}
+
+// blockProfiler records the profile of a renderer; a nil profiler records nothing
+type blockProfiler struct {
+	source []byte
+	blocks []BlockProfile
+	// nodes are the nodes of blocks, positioned once rendering is done so the
+	// timings do not include it
+	nodes []ast.Node
+	kinds map[ast.NodeKind]*KindProfile
+	// children accumulates the time of the child nodes of each open node
+	children []time.Duration
+}
+
+// newBlockProfiler returns a profiler if opts asks for one
+func newBlockProfiler(source []byte, opts Options) *blockProfiler {
+	if !opts.ProfileBlocks {
+		return nil
+	}
+	return &blockProfiler{source: source, kinds: make(map[ast.NodeKind]*KindProfile)}
+}
+
+// stopNothing is returned by a nil profiler
+func stopNothing() {}
+
+// enter starts timing a node and returns the function that stops it
+func (p *blockProfiler) enter(node ast.Node) func() {
+	if p == nil {
+		return stopNothing
+	}
+	start := time.Now()
+	p.children = append(p.children, 0)
+	return func() {
+		elapsed := time.Since(start)
+		last := len(p.children) - 1
+		self := elapsed - p.children[last]
+		p.children = p.children[:last]
+		if last > 0 {
+			p.children[last-1] += elapsed
+		}
+		kind := p.kinds[node.Kind()]
+		if kind == nil {
+			kind = &KindProfile{Kind: node.Kind().String()}
+			p.kinds[node.Kind()] = kind
+		}
+		kind.Count++
+		kind.Duration += self
+	}
+}
+
+// topLevel starts timing a node if it is a top-level block, and returns the
+// function that records it; size reports the length of the output so far
+func (p *blockProfiler) topLevel(node ast.Node, size func() int) func() {
+	if p == nil || node.Parent() == nil || node.Parent().Kind() != ast.KindDocument {
+		return stopNothing
+	}
+	start, before := time.Now(), size()
+	return func() {
+		p.block(node, time.Since(start), size()-before)
+	}
+}
+
+// block records a rendered top-level block
+func (p *blockProfiler) block(node ast.Node, elapsed time.Duration, outputBytes int) {
+	if p == nil {
+		return
+	}
+	p.blocks = append(p.blocks, BlockProfile{
+		Kind:        node.Kind().String(),
+		Duration:    elapsed,
+		OutputBytes: outputBytes,
+	})
+	p.nodes = append(p.nodes, node)
+}
+
+// profile returns the recorded profile, or nil
+func (p *blockProfiler) profile(parse time.Duration) *Profile {
+	if p == nil {
+		return nil
+	}
+	for i, node := range p.nodes {
+		p.blocks[i].Line, _ = nodePosition(p.source, node)
+	}
+	profile := &Profile{Parse: parse, Blocks: p.blocks, Kinds: make([]KindProfile, 0, len(p.kinds))}
+	for _, kind := range p.kinds {
+		profile.Kinds = append(profile.Kinds, *kind)
+	}
+	sort.Slice(profile.Kinds, func(i, j int) bool {
+		if profile.Kinds[i].Duration != profile.Kinds[j].Duration {
+			return profile.Kinds[i].Duration > profile.Kinds[j].Duration
+		}
+		return profile.Kinds[i].Kind < profile.Kinds[j].Kind
+	})
+	return profile
+}
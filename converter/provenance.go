@@ -0,0 +1,81 @@
+// Conversion provenance
+// A trailer recording the tool version and source hash lets later syncs
+// detect descriptions that were edited by hand in JIRA
+
+package converter
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"regexp"
+	"strings"
+)
+
+// Provenance identifies the conversion that produced a description
+type Provenance struct {
+	// Version is the md2jira version that converted the source
+	Version string
+	// SourceSHA256 is the hex SHA-256 of the Markdown source
+	SourceSHA256 string
+	// OutputSHA256 is the hex SHA-256 of the markup the trailer follows, with
+	// CRLF line endings and surrounding whitespace ignored; it is empty in
+	// trailers written before Compat12
+	OutputSHA256 string
+}
+
+// provenanceRe matches a provenance trailer at the end of JIRA markup
+var provenanceRe = regexp.MustCompile(`\n*\{color:#97a0af\}md2jira ([^ {}]+) sha256:([0-9a-f]{64})(?: output:([0-9a-f]{64}))?\{color\}\s*$`)
+
+// NewProvenance records the current version and the hash of source
+func NewProvenance(source []byte) Provenance {
+	sum := sha256.Sum256(source)
+	return Provenance{Version: Version, SourceSHA256: hex.EncodeToString(sum[:])}
+}
+
+// WithOutput records the hash of the markup the trailer is appended to
+func (p Provenance) WithOutput(markup string) Provenance {
+	p.OutputSHA256 = outputSHA256(markup)
+	return p
+}
+
+// outputSHA256 returns the hex SHA-256 of markup, ignoring the CRLF line
+// endings and surrounding whitespace JIRA may add when storing it
+func outputSHA256(markup string) string {
+	sum := sha256.Sum256([]byte(strings.TrimSpace(strings.ReplaceAll(markup, "\r\n", "\n"))))
+	return hex.EncodeToString(sum[:])
+}
+
+// Trailer formats the provenance as a compact, grey JIRA markup line
+func (p Provenance) Trailer() string {
+	trailer := "{color:#97a0af}md2jira " + p.Version + " sha256:" + p.SourceSHA256
+	if p.OutputSHA256 != "" {
+		trailer += " output:" + p.OutputSHA256
+	}
+	return trailer + "{color}"
+}
+
+// MatchesSource reports whether source is the Markdown the provenance was recorded for
+func (p Provenance) MatchesSource(source []byte) bool {
+	return NewProvenance(source).SourceSHA256 == p.SourceSHA256
+}
+
+// MatchesOutput reports whether body, as returned by ParseProvenance, is the
+// markup the trailer was appended to, so it was not edited since; it is true
+// for trailers without an output hash, which cannot tell
+func (p Provenance) MatchesOutput(body string) bool {
+	return p.OutputSHA256 == "" || outputSHA256(body) == p.OutputSHA256
+}
+
+// ParseProvenance splits JIRA markup into its body and provenance trailer.
+// ok is false when the markup has no trailer, in which case body is the markup.
+func ParseProvenance(markup string) (body string, p Provenance, ok bool) {
+	m := provenanceRe.FindStringSubmatchIndex(markup)
+	if m == nil {
+		return markup, Provenance{}, false
+	}
+	p = Provenance{Version: markup[m[2]:m[3]], SourceSHA256: markup[m[4]:m[5]]}
+	if m[6] >= 0 {
+		p.OutputSHA256 = markup[m[6]:m[7]]
+	}
+	return strings.TrimRight(markup[:m[0]], "\n"), p, true
+}
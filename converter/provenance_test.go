@@ -0,0 +1,70 @@
+package converter
+
+import (
+	"context"
+	"strings"
+	"testing"
+)
+
+func TestProvenanceOutputHash(t *testing.T) {
+	markdown := "# T\n\nSome *text* and [a link](https://e.example).\n\n- a\n- b\n"
+	opts := Options{ProvenanceTrailer: true, LinkStyle: LinkStyleEndnotes}
+	result, err := ConvertWithOptions(markdown, opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	body, p, ok := ParseProvenance(result.Output)
+	if !ok {
+		t.Fatalf("no trailer in %q", result.Output)
+	}
+	if !p.MatchesSource([]byte(markdown)) || p.OutputSHA256 == "" {
+		t.Fatalf("provenance = %+v", p)
+	}
+	if !p.MatchesOutput(body) {
+		t.Error("the converted body does not match its trailer")
+	}
+	if !p.MatchesOutput(strings.ReplaceAll(body, "\n", "\r\n") + "\r\n") {
+		t.Error("CRLF line endings JIRA stores changed the output hash")
+	}
+	if p.MatchesOutput(strings.Replace(body, "text", "edited text", 1)) {
+		t.Error("an edited body matches its trailer")
+	}
+
+	// The streamed document carries the same trailer
+	chunks, warnings, err := NewConverterWithOptions(opts).ConvertStream(context.Background(), strings.NewReader(markdown))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var streamed []string
+	for chunks != nil || warnings != nil {
+		select {
+		case c, open := <-chunks:
+			if !open {
+				chunks = nil
+				continue
+			}
+			streamed = append(streamed, c.Output)
+		case _, open := <-warnings:
+			if !open {
+				warnings = nil
+			}
+		}
+	}
+	if got := strings.Join(streamed, "\n\n"); got != result.Output {
+		t.Errorf("streamed %q, converted %q", got, result.Output)
+	}
+}
+
+func TestProvenanceWithoutOutputHash(t *testing.T) {
+	result, err := ConvertWithOptions("text", Options{ProvenanceTrailer: true, CompatLevel: Compat11})
+	if err != nil {
+		t.Fatal(err)
+	}
+	body, p, ok := ParseProvenance(result.Output)
+	if !ok || p.OutputSHA256 != "" {
+		t.Fatalf("trailer before Compat12 = %+v in %q", p, result.Output)
+	}
+	if !p.MatchesOutput(body + " edited") {
+		t.Error("a trailer without an output hash rejects edits it cannot detect")
+	}
+}
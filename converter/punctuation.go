@@ -0,0 +1,105 @@
+// Locale punctuation
+// JIRA only recognizes *bold*, _italic_, -strike- and +underline+ when the
+// markers touch ASCII spaces or punctuation, so no-break and ideographic
+// spaces, full-width punctuation, guillemets and CJK brackets next to a marker
+// break the markup of non-English documents
+
+package converter
+
+import (
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/yuin/goldmark/ast"
+	east "github.com/yuin/goldmark/extension/ast"
+)
+
+// boundaryRune returns the ASCII character JIRA understands for a space or
+// punctuation mark next to markup: other spaces become a space and full-width
+// punctuation its ASCII form; any other rune is returned unchanged
+func boundaryRune(r rune) rune {
+	switch {
+	case r != ' ' && unicode.Is(unicode.Zs, r):
+		return ' '
+	case r >= 0xFF01 && r <= 0xFF5E && !unicode.IsLetter(r) && !unicode.IsDigit(r):
+		// Full-width * or _ would become markup themselves
+		if ascii := r - 0xFEE0; !strings.ContainsRune(jiraMetaChars, ascii) {
+			return ascii
+		}
+	}
+	return r
+}
+
+// jiraBoundary reports whether JIRA accepts r next to an effect marker
+func jiraBoundary(r rune) bool {
+	return r < utf8.RuneSelf && (unicode.IsSpace(r) || unicode.IsPunct(r) || unicode.IsSymbol(r))
+}
+
+// isMarkup reports whether a node is written with markers that need a
+// boundary next to them
+func isMarkup(node ast.Node) bool {
+	switch node.(type) {
+	case *ast.Emphasis, *east.Strikethrough, *inserted, *ast.Link:
+		return true
+	}
+	return false
+}
+
+// normalizeBoundaries normalizes the first rune of text following markup and
+// the last rune of text preceding markup
+func normalizeBoundaries(text string, n ast.Node) string {
+	if text == "" {
+		return text
+	}
+	if prev := n.PreviousSibling(); prev != nil && isMarkup(prev) {
+		r, size := utf8.DecodeRuneInString(text)
+		text = string(boundaryRune(r)) + text[size:]
+	}
+	if next := n.NextSibling(); next != nil && isMarkup(next) {
+		r, size := utf8.DecodeLastRuneInString(text)
+		text = text[:len(text)-size] + string(boundaryRune(r))
+	}
+	return text
+}
+
+// effectMarker returns the marker of an effect such as * for bold. With
+// NormalizePunctuation, an effect touching text JIRA does not accept as a
+// boundary, such as «*texte*» or 中文**强调**, uses the braced form {*}.
+func (r *JIRARenderer) effectMarker(n ast.Node, marker string) string {
+	if !r.options.NormalizePunctuation {
+		return marker
+	}
+	if r.outerBoundary(n.PreviousSibling(), true) && r.outerBoundary(n.NextSibling(), false) {
+		return marker
+	}
+	return "{" + marker + "}"
+}
+
+// outerBoundary reports whether the sibling before or after an effect ends or
+// starts with a boundary JIRA accepts; other inlines and the edges of the
+// parent always do
+func (r *JIRARenderer) outerBoundary(sibling ast.Node, before bool) bool {
+	var text string
+	switch s := sibling.(type) {
+	case *ast.Text:
+		if before && (s.SoftLineBreak() || s.HardLineBreak()) {
+			return true
+		}
+		text = string(s.Segment.Value(r.source))
+	case *ast.String:
+		text = string(s.Value)
+	default:
+		return true
+	}
+	if text == "" {
+		return true
+	}
+	var c rune
+	if before {
+		c, _ = utf8.DecodeLastRuneInString(text)
+	} else {
+		c, _ = utf8.DecodeRuneInString(text)
+	}
+	return jiraBoundary(boundaryRune(c))
+}
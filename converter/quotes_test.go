@@ -0,0 +1,102 @@
+package converter
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+func TestQuoteBlocks(t *testing.T) {
+	tests := []struct {
+		name     string
+		markdown string
+		want     string
+	}{
+		{
+			name:     "code block and list",
+			markdown: "> ```\n> code\n> ```\n> - a\n> - b\n>\n> text",
+			want:     "{quote}\n{code}\ncode\n{code}\n\n* a\n* b\n\ntext\n\n{quote}",
+		},
+		{
+			name:     "nested list",
+			markdown: "> 1. a\n>    - b",
+			want:     "{quote}\n# a\n#* b\n\n{quote}",
+		},
+		{
+			name:     "heading",
+			markdown: "> # h",
+			want:     "{quote}\nh1. h\n\n{quote}",
+		},
+		{
+			name:     "table",
+			markdown: "> a\n>\n> | h |\n> |---|\n> | c |\n>\n> b",
+			want:     "{quote}\na\n\n||h||\n|c|\n\nb\n\n{quote}",
+		},
+		{
+			name:     "nested blockquote",
+			markdown: "> > nested\n>\n> back",
+			want:     "{quote}\nbq. nested\n\nback\n\n{quote}",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := render(t, tt.markdown, Options{}); got != tt.want {
+				t.Errorf("got %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestADFQuoteBlocks(t *testing.T) {
+	tests := []struct {
+		name     string
+		markdown string
+		level    CompatLevel
+		// want lists the top-level nodes, with the children of blockquotes
+		want string
+	}{
+		{"paragraphs", "> a\n>\n> b", CompatLatest, "blockquote(paragraph paragraph)"},
+		{"list and code block", "> - a\n>\n> ```\n> code\n> ```", CompatLatest, "blockquote(bulletList codeBlock)"},
+		{"nested blockquote", "> a\n>\n> > b", CompatLatest, "blockquote(paragraph paragraph)"},
+		{"table", "> a\n>\n> | h |\n> |---|\n> | c |\n>\n> b", CompatLatest, "blockquote(paragraph) table blockquote(paragraph)"},
+		{"nested blockquote before Compat12", "> a\n>\n> > b", Compat11, "blockquote(paragraph blockquote)"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := ConvertToADFWithOptions(tt.markdown, Options{CompatLevel: tt.level})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := adfOutline(t, result.Output); got != tt.want {
+				t.Errorf("got %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+// adfOutline lists the types of the top-level nodes of an ADF document, and
+// those of the children of its blockquotes
+func adfOutline(t *testing.T, output string) string {
+	t.Helper()
+	type node struct {
+		Type    string `json:"type"`
+		Content []node `json:"content"`
+	}
+	var doc node
+	if err := json.Unmarshal([]byte(output), &doc); err != nil {
+		t.Fatalf("invalid ADF: %v", err)
+	}
+	var nodes []string
+	for _, n := range doc.Content {
+		if n.Type != "blockquote" {
+			nodes = append(nodes, n.Type)
+			continue
+		}
+		var children []string
+		for _, c := range n.Content {
+			children = append(children, c.Type)
+		}
+		nodes = append(nodes, n.Type+"("+strings.Join(children, " ")+")")
+	}
+	return strings.Join(nodes, " ")
+}
@@ -0,0 +1,936 @@
+// JIRA markup renderer
+// Renders the goldmark AST into JIRA Text Formatting Notation
+
+package converter
+
+import (
+	"fmt"
+	"html"
+	"net/url"
+	"regexp"
+	"strings"
+
+	"github.com/astsu-dev/md2jira/jiraescape"
+	"github.com/yuin/goldmark/ast"
+	east "github.com/yuin/goldmark/extension/ast"
+	"github.com/yuin/goldmark/renderer"
+)
+
+// Language mapping from Markdown to JIRA
+var languageMap = map[string]string{
+	"js":         "javascript",
+	"javascript": "javascript",
+	"ts":         "typescript",
+	"typescript": "typescript",
+	"py":         "python",
+	"python":     "python",
+	"rb":         "ruby",
+	"ruby":       "ruby",
+	"sh":         "bash",
+	"bash":       "bash",
+	"shell":      "bash",
+	"json":       "json",
+	"xml":        "xml",
+	"html":       "html",
+	"css":        "css",
+	"sql":        "sql",
+	"java":       "java",
+	"go":         "go",
+	"golang":     "go",
+	"rust":       "rust",
+	"c":          "cpp",
+	"cpp":        "cpp",
+	"c++":        "cpp",
+	"yaml":       "yaml",
+	"yml":        "yaml",
+	"php":        "php",
+	"swift":      "swift",
+	"kotlin":     "kotlin",
+	"scala":      "scala",
+	"r":          "r",
+	"perl":       "perl",
+	"groovy":     "groovy",
+	"powershell": "powershell",
+	"ps1":        "powershell",
+	"dockerfile": "dockerfile",
+	"makefile":   "makefile",
+	"markdown":   "none",
+	"md":         "none",
+	"text":       "none",
+	"txt":        "none",
+	"plaintext":  "none",
+}
+
+// JIRARenderer renders Markdown AST to JIRA markup
+type JIRARenderer struct {
+	source   []byte
+	warnings []Warning
+	options  Options
+	// Track list nesting
+	listStack []ast.Node
+	// Track if we're in a tight list
+	inTightList bool
+	// Blockquotes open around the node being rendered
+	quoteDepth int
+	// Node renderers registered through goldmark options
+	nodeFuncs map[ast.NodeKind]renderer.NodeRendererFunc
+	// midLine is set by NodeRenderer when the output written before the
+	// buffer of the node being rendered does not end with a newline
+	midLine bool
+	// Rendered content of footnotes that are inlined, by index
+	inlineFootnotes map[int]string
+	// URLs referenced by endnote-style links, in order of first use
+	endnotes []string
+	// Heading IDs that links of the document point to
+	anchors map[string]bool
+	// Nodes rendered by the default case, for Stats.UnknownNodes
+	unknown unknownNodes
+	// Node being rendered, used to position warnings
+	current ast.Node
+	// Open <font> and <span> tags, recording whether each one emitted {color}
+	fontColors []bool
+	// Open <a> tags, innermost last
+	htmlLinks []htmlLink
+	// Runbook steps numbered so far, and whether a phase {expand} is open
+	steps     int
+	phaseOpen bool
+	// Rendering a timeline event, which ends up in a table cell
+	inTimeline bool
+	// Rendering a table cell, and the inline HTML lists open in it: -1 for
+	// <ul>, the items so far for <ol>
+	inTableCell bool
+	cellLists   []int
+	// Open <details> sections; only the outermost is an {expand}
+	expandDepth int
+	// How each table is rendered, decided when it is first seen
+	tableChoices map[*east.Table]string
+	// Records rendering times with Options.ProfileBlocks
+	profiler *blockProfiler
+	// Local images rendered as attachments with Options.AttachLocalImages
+	attachments attachmentSet
+}
+
+// NewJIRARenderer creates a new JIRA renderer
+func NewJIRARenderer(source []byte, opts Options) *JIRARenderer {
+	return &JIRARenderer{
+		source:    source,
+		options:   safeOptions(opts),
+		listStack: make([]ast.Node, 0),
+		profiler:  newBlockProfiler(source, opts),
+	}
+}
+
+// Render renders the AST to JIRA markup
+func (r *JIRARenderer) Render(doc ast.Node) string {
+	var buf strings.Builder
+	r.collectFootnotes(doc)
+	r.collectAnchors(doc)
+	r.renderNode(&buf, doc, true)
+	r.renderEndnotes(&buf)
+	return buf.String()
+}
+
+// GetWarnings returns any warnings generated during rendering
+func (r *JIRARenderer) GetWarnings() []Warning {
+	return r.warnings
+}
+
+// addWarning adds a warning message positioned at the node being rendered
+func (r *JIRARenderer) addWarning(code WarningCode, msg string) {
+	r.warnings = append(r.warnings, newWarning(r.source, r.current, code, msg))
+}
+
+// renderNode renders a single node and its children
+func (r *JIRARenderer) renderNode(buf *strings.Builder, node ast.Node, entering bool) {
+	switch n := node.(type) {
+	case *ast.Document:
+		r.renderChildren(buf, n)
+		r.closePhase(buf)
+	case *ast.Heading:
+		r.renderHeading(buf, n, entering)
+	case *ast.Paragraph:
+		r.renderParagraph(buf, n, entering)
+	case *ast.Text:
+		r.renderText(buf, n, entering)
+	case *ast.String:
+		r.renderString(buf, n, entering)
+	case *ast.Emphasis:
+		r.renderEmphasis(buf, n, entering)
+	case *ast.CodeSpan:
+		r.renderCodeSpan(buf, n, entering)
+	case *ast.FencedCodeBlock:
+		r.renderFencedCodeBlock(buf, n, entering)
+	case *ast.CodeBlock:
+		r.renderCodeBlock(buf, n, entering)
+	case *ast.Link:
+		r.renderLink(buf, n, entering)
+	case *ast.AutoLink:
+		r.renderAutoLink(buf, n, entering)
+	case *ast.Image:
+		r.renderImage(buf, n, entering)
+	case *ast.List:
+		r.renderList(buf, n, entering)
+	case *ast.ListItem:
+		r.renderListItem(buf, n, entering)
+	case *ast.ThematicBreak:
+		r.renderThematicBreak(buf, n, entering)
+	case *ast.Blockquote:
+		r.renderBlockquote(buf, n, entering)
+	case *ast.HTMLBlock:
+		r.renderHTMLBlock(buf, n, entering)
+	case *ast.RawHTML:
+		r.renderRawHTML(buf, n, entering)
+	case *ast.TextBlock:
+		r.renderTextBlock(buf, n, entering)
+	case *east.Table:
+		r.renderTable(buf, n, entering)
+	case *east.TableHeader:
+		r.renderTableHeader(buf, n, entering)
+	case *east.TableRow:
+		r.renderTableRow(buf, n, entering)
+	case *east.TableCell:
+		r.renderTableCell(buf, n, entering)
+	case *east.Strikethrough:
+		r.renderStrikethrough(buf, n, entering)
+	case *inserted:
+		r.renderInserted(buf, n, entering)
+	case *east.TaskCheckBox:
+		r.renderTaskCheckBox(buf, n, entering)
+	case *east.FootnoteLink:
+		r.renderFootnoteLink(buf, n, entering)
+	case *east.FootnoteList:
+		r.renderFootnoteList(buf, n, entering)
+	case *east.Footnote:
+		r.renderFootnote(buf, n, entering)
+	case *admonition:
+		r.renderAdmonition(buf, n, entering)
+	case *details:
+		r.renderDetails(buf, n, entering)
+	case *mathBlock:
+		if entering {
+			r.renderMathBlock(buf, n)
+		}
+	case *mathInline:
+		if entering {
+			r.renderMathInline(buf, n)
+		}
+	case *tocMarker:
+		if entering {
+			buf.WriteString(r.options.tocMacro() + "\n\n")
+		}
+	case *east.FootnoteBacklink:
+		// Footnotes are rendered without their backlinks
+	default:
+		// Unknown nodes are transparent; walk renders their children
+		if entering {
+			r.unknown.add(node)
+		}
+	}
+}
+
+// renderChildren renders all children of a node
+func (r *JIRARenderer) renderChildren(buf *strings.Builder, node ast.Node) {
+	for child := node.FirstChild(); child != nil; child = child.NextSibling() {
+		r.walk(buf, child)
+	}
+}
+
+// walk walks the AST and renders nodes
+func (r *JIRARenderer) walk(buf *strings.Builder, node ast.Node) {
+	if r.profiler != nil {
+		defer r.profiler.enter(node)()
+		defer r.profiler.topLevel(node, buf.Len)()
+	}
+	if f, ok := r.nodeFuncs[node.Kind()]; ok {
+		r.walkFunc(buf, node, f)
+		return
+	}
+	r.current = node
+	r.renderNode(buf, node, true)
+	if !r.isLeafNode(node) && !r.skipChildren(node) {
+		r.renderChildren(buf, node)
+	}
+	r.current = node
+	r.renderNode(buf, node, false)
+}
+
+// isLeafNode returns true if the node is a leaf (no children to render separately)
+func (r *JIRARenderer) isLeafNode(node ast.Node) bool {
+	switch node.(type) {
+	case *ast.Text, *ast.String, *ast.CodeSpan, *ast.FencedCodeBlock,
+		*ast.CodeBlock, *ast.ThematicBreak, *ast.HTMLBlock, *ast.RawHTML,
+		*east.TaskCheckBox, *east.FootnoteLink, *east.FootnoteBacklink,
+		*mathBlock, *mathInline:
+		return true
+	}
+	return false
+}
+
+// skipChildren returns true if we handle children ourselves
+func (r *JIRARenderer) skipChildren(node ast.Node) bool {
+	switch node.(type) {
+	case *ast.Link, *ast.Image, *ast.AutoLink, *east.Footnote, *east.TableCell:
+		return true
+	case *ast.Paragraph:
+		return isSpacerParagraph(r.source, node) || r.isTrace(node)
+	case *ast.Heading:
+		return r.isPhaseHeading(node.(*ast.Heading))
+	case *ast.List:
+		return r.isTimeline(node) || r.isActionList(node)
+	case *ast.ListItem:
+		return r.options.compat(Compat10) && !r.isStepList(node.Parent())
+	case *east.Table:
+		return r.roadmapColumns(node) != nil || r.tableChoice(node.(*east.Table)) == ChoiceNoformat
+	}
+	return false
+}
+
+// renderHeading renders a heading
+func (r *JIRARenderer) renderHeading(buf *strings.Builder, n *ast.Heading, entering bool) {
+	if r.isPhaseHeading(n) {
+		if entering {
+			r.renderPhase(buf, n)
+		}
+		return
+	}
+	if entering {
+		if r.options.Runbook && n.Level < runbookPhaseLevel {
+			r.closePhase(buf)
+		}
+		fmt.Fprintf(buf, "h%d. ", r.headingLevel(n))
+		buf.WriteString(r.headingAnchor(n))
+	} else {
+		buf.WriteString("\n\n")
+	}
+}
+
+// renderParagraph renders a paragraph
+func (r *JIRARenderer) renderParagraph(buf *strings.Builder, n *ast.Paragraph, entering bool) {
+	if isSpacerParagraph(r.source, n) {
+		// Spacers collapse into the surrounding blank lines
+		if entering && r.options.PreserveSpacers {
+			buf.WriteString(spacerBreak + "\n\n")
+		}
+		return
+	}
+	if r.isTrace(n) {
+		if entering {
+			text, _ := traceText(r.source, n)
+			r.renderTrace(buf, text)
+		}
+		return
+	}
+	if entering && r.quoteDepth > 1 && r.options.compat(Compat12) {
+		if _, ok := n.Parent().(*ast.Blockquote); ok {
+			buf.WriteString("bq. ")
+		}
+	}
+	if !entering {
+		// Check if we're in a tight list
+		if !r.inTightList || len(r.listStack) == 0 {
+			buf.WriteString("\n\n")
+		}
+	}
+}
+
+// renderText renders text content
+func (r *JIRARenderer) renderText(buf *strings.Builder, n *ast.Text, entering bool) {
+	if entering {
+		text := string(n.Segment.Value(r.source))
+		if r.options.NormalizePunctuation {
+			text = normalizeBoundaries(text, n)
+		}
+		text = decodeEntities(text)
+		// Labels are escaped with the text, as [done] would be a link
+		if r.options.Symbols == SymbolsText {
+			text = labelEmoji(text)
+		}
+		// Escape JIRA special characters in text
+		ctx := textContext(n)
+		if r.startsLine(buf) {
+			ctx |= ctxLineStart
+		}
+		switch {
+		case ctx&ctxLinkLabel != 0:
+			text = r.escapeJIRAText(text, ctx)
+		case r.options.ConvertMentions:
+			text = r.mentionText(text, ctx)
+		default:
+			text = r.issueText(text, ctx)
+		}
+		if r.options.Emoticons && r.options.Symbols == SymbolsEmoticons {
+			text = replaceEmoji(text)
+		}
+		buf.WriteString(text)
+		buf.WriteString(r.lineBreak(n))
+	}
+}
+
+// renderString renders a string node
+func (r *JIRARenderer) renderString(buf *strings.Builder, n *ast.String, entering bool) {
+	if entering {
+		text := string(n.Value)
+		if r.options.NormalizePunctuation {
+			text = normalizeBoundaries(text, n)
+		}
+		text = r.escapeJIRAText(text, textContext(n))
+		buf.WriteString(text)
+	}
+}
+
+// startsLine reports whether text written to buf starts a line
+func (r *JIRARenderer) startsLine(buf *strings.Builder) bool {
+	if buf.Len() == 0 {
+		return !r.midLine
+	}
+	return strings.HasSuffix(buf.String(), "\n")
+}
+
+// escapeJIRAText escapes special characters for JIRA
+func (r *JIRARenderer) escapeJIRAText(text string, ctx escapeContext) string {
+	if r.inTimeline {
+		ctx |= ctxTableCell
+	}
+	return escapeJIRA(text, ctx, r.options.EscapeMode, r.options)
+}
+
+// entityRe matches named and numeric HTML character references
+var entityRe = regexp.MustCompile(`&(?:#[0-9]{1,7}|#[xX][0-9a-fA-F]{1,6}|[A-Za-z][A-Za-z0-9]{1,31});`)
+
+// decodeEntities decodes HTML character references to Unicode,
+// escaping any that decode to JIRA metacharacters
+func decodeEntities(text string) string {
+	if !strings.Contains(text, "&") {
+		return text
+	}
+	var buf strings.Builder
+	last := 0
+	for _, loc := range entityRe.FindAllStringIndex(text, -1) {
+		// Backslash-escaped ampersands are literal
+		if loc[0] > 0 && text[loc[0]-1] == '\\' {
+			continue
+		}
+		buf.WriteString(text[last:loc[0]])
+		decoded := html.UnescapeString(text[loc[0]:loc[1]])
+		if len(decoded) == 1 && strings.Contains(jiraMetaChars, decoded) {
+			buf.WriteString("\\")
+		}
+		buf.WriteString(decoded)
+		last = loc[1]
+	}
+	buf.WriteString(text[last:])
+	return buf.String()
+}
+
+// renderEmphasis renders emphasis (bold/italic)
+func (r *JIRARenderer) renderEmphasis(buf *strings.Builder, n *ast.Emphasis, entering bool) {
+	switch n.Level {
+	case 1:
+		// Single emphasis = italic
+		buf.WriteString(r.effectMarker(n, "_"))
+	case 2:
+		// Double emphasis = bold
+		buf.WriteString(r.effectMarker(n, "*"))
+	}
+	// Note: goldmark parses ***text*** as nested Emphasis nodes (level 2 containing level 1),
+	// not as a single level 3 node. The nesting handles bold+italic automatically.
+}
+
+// renderStrikethrough renders strikethrough text
+func (r *JIRARenderer) renderStrikethrough(buf *strings.Builder, n *east.Strikethrough, entering bool) {
+	buf.WriteString(r.effectMarker(n, "-"))
+}
+
+// renderCodeSpan renders inline code
+func (r *JIRARenderer) renderCodeSpan(buf *strings.Builder, n *ast.CodeSpan, entering bool) {
+	if entering {
+		buf.WriteString("{{")
+		// Get the code content
+		for range n.ChildCount() {
+			segment := n.Text(r.source) //nolint: staticcheck
+			buf.WriteString(r.escapeJIRAText(string(segment), textContext(n)|ctxCode))
+			break
+		}
+		buf.WriteString("}}")
+	}
+}
+
+// renderFencedCodeBlock renders a fenced code block
+func (r *JIRARenderer) renderFencedCodeBlock(buf *strings.Builder, n *ast.FencedCodeBlock, entering bool) {
+	if entering {
+		lang := string(n.Language(r.source))
+		lang = strings.TrimSpace(lang)
+		if strings.EqualFold(lang, "jql") {
+			r.renderJQL(buf, n)
+			return
+		}
+		if r.renderDiagram(buf, n, lang) {
+			return
+		}
+		if r.options.isMathFence(lang) {
+			r.renderMathBlock(buf, n)
+			return
+		}
+		if strings.Contains(lang, "=") {
+			// The info string starts with an attribute, not a language
+			lang = ""
+		}
+		var title string
+		if n.Info != nil {
+			if title = codeTitle(string(n.Info.Segment.Value(r.source))); title != "" {
+				title = "title=" + title
+			}
+		}
+
+		// Map language to the JIRA equivalent supported by the dialect
+		buf.WriteString(r.codeMacro(lang, title, r.collapseParam(n)) + "\n")
+		buf.WriteString(r.options.macroBody("code", fenceText(r.source, n)))
+		buf.WriteString("{code}\n\n")
+	}
+}
+
+// renderJQL renders a jql fence as a {jql} macro, or as a link to the issue
+// navigator when Options.JQLBaseURL is set
+func (r *JIRARenderer) renderJQL(buf *strings.Builder, n *ast.FencedCodeBlock) {
+	var query strings.Builder
+	lines := n.Lines()
+	for i := 0; i < lines.Len(); i++ {
+		line := lines.At(i)
+		query.Write(line.Value(r.source))
+	}
+	if r.options.JQLBaseURL == "" {
+		buf.WriteString("{jql}\n" + r.options.macroBody("jql", query.String()) + "{jql}\n\n")
+		return
+	}
+	jql := strings.Join(strings.Fields(query.String()), " ")
+	link := strings.TrimRight(r.options.JQLBaseURL, "/") + "/issues/?jql=" + url.QueryEscape(jql)
+	fmt.Fprintf(buf, "[%s|%s]\n\n", escapeJIRA(jql, ctxLinkLabel, EscapeAggressive, r.options), link)
+}
+
+// renderCodeBlock renders an indented code block
+func (r *JIRARenderer) renderCodeBlock(buf *strings.Builder, n *ast.CodeBlock, entering bool) {
+	if entering {
+		buf.WriteString(r.codeMacro("", r.collapseParam(n)) + "\n")
+		buf.WriteString(r.options.macroBody("code", fenceText(r.source, n)))
+		buf.WriteString("{code}\n\n")
+	}
+}
+
+// codeTitleRe matches a title="..." attribute in a fenced code info string
+var codeTitleRe = regexp.MustCompile(`(?:^|[\s{,])title=(?:"([^"]*)"|'([^']*)'|(\S+))`)
+
+// codeTitle returns the title attribute of a fenced code info string
+func codeTitle(info string) string {
+	m := codeTitleRe.FindStringSubmatch(info)
+	if m == nil {
+		return ""
+	}
+	return jiraescape.MacroParam(m[1] + m[2] + m[3])
+}
+
+// collapseParam returns the collapse=true parameter for code blocks longer
+// than Options.CollapseCodeOver lines
+func (r *JIRARenderer) collapseParam(n ast.Node) string {
+	if r.options.CollapseCodeOver > 0 && n.Lines().Len() > r.options.CollapseCodeOver {
+		return "collapse=true"
+	}
+	return ""
+}
+
+// mapLanguage maps Markdown language identifiers to JIRA equivalents,
+// preferring the overrides over the built-in mapping
+func mapLanguage(lang string, overrides map[string]string) string {
+	lang = strings.ToLower(strings.TrimSpace(lang))
+	if mapped, ok := overrides[lang]; ok {
+		return mapped
+	}
+	if mapped, ok := languageMap[lang]; ok {
+		return mapped
+	}
+	// Return the language as-is if no mapping exists
+	return lang
+}
+
+// renderLink renders a link
+func (r *JIRARenderer) renderLink(buf *strings.Builder, n *ast.Link, entering bool) {
+	if entering {
+		// Get link text
+		var linkText strings.Builder
+		for child := n.FirstChild(); child != nil; child = child.NextSibling() {
+			r.renderLinkContent(&linkText, child)
+		}
+
+		url := string(n.Destination)
+		text := linkText.String()
+		bare := text == "" || text == url
+		if target, ok := r.options.documentURL(url); ok {
+			// The file name stays the label of [doc.md](doc.md)
+			url, bare = target, text == ""
+		}
+
+		url, ok := r.options.safeURL(url)
+		if !ok {
+			// The label alone, as the target would run a script
+			buf.WriteString(text)
+			return
+		}
+		if bare {
+			if title, ok := r.linkTitle(url); ok {
+				fmt.Fprintf(buf, "[%s|%s]", title, url)
+			} else {
+				fmt.Fprintf(buf, "[%s]", url)
+			}
+		} else if r.options.LinkStyle == LinkStyleEndnotes && !isFragment(url) {
+			// Links within the document stay inline
+			fmt.Fprintf(buf, "%s \\[%d\\]", text, r.endnoteIndex(url))
+		} else {
+			fmt.Fprintf(buf, "[%s|%s]", text, url)
+		}
+	}
+}
+
+// renderLinkContent renders content inside a link
+func (r *JIRARenderer) renderLinkContent(buf *strings.Builder, node ast.Node) {
+	switch n := node.(type) {
+	case *ast.Text:
+		text := decodeEntities(string(n.Segment.Value(r.source)))
+		buf.WriteString(r.escapeJIRAText(text, textContext(n)))
+		if n.SoftLineBreak() || n.HardLineBreak() {
+			buf.WriteString(" ")
+		}
+	case *ast.String:
+		buf.WriteString(r.escapeJIRAText(string(n.Value), textContext(n)))
+	case *ast.CodeSpan:
+		buf.WriteString("{{")
+		code := string(n.Text(r.source)) //nolint: staticcheck
+		buf.WriteString(r.escapeJIRAText(code, textContext(n)|ctxCode))
+		buf.WriteString("}}")
+	case *ast.Emphasis:
+		if n.Level == 1 {
+			buf.WriteString("_")
+		} else {
+			buf.WriteString("*")
+		}
+		for child := n.FirstChild(); child != nil; child = child.NextSibling() {
+			r.renderLinkContent(buf, child)
+		}
+		if n.Level == 1 {
+			buf.WriteString("_")
+		} else {
+			buf.WriteString("*")
+		}
+	default:
+		for child := node.FirstChild(); child != nil; child = child.NextSibling() {
+			r.renderLinkContent(buf, child)
+		}
+	}
+}
+
+// renderAutoLink renders an autolink
+func (r *JIRARenderer) renderAutoLink(buf *strings.Builder, n *ast.AutoLink, entering bool) {
+	if entering {
+		url, ok := r.options.safeURL(string(n.URL(r.source)))
+		if !ok {
+			buf.WriteString(r.escapeJIRAText(string(n.URL(r.source)), textContext(n)))
+			return
+		}
+		if title, ok := r.linkTitle(url); ok {
+			fmt.Fprintf(buf, "[%s|%s]", title, url)
+		} else {
+			fmt.Fprintf(buf, "[%s]", url)
+		}
+	}
+}
+
+// renderImage renders an image
+func (r *JIRARenderer) renderImage(buf *strings.Builder, n *ast.Image, entering bool) {
+	if entering {
+		url, ok := r.attachImage(string(n.Destination))
+		if !ok {
+			if url, ok = r.options.safeURL(string(n.Destination)); !ok {
+				buf.WriteString(r.escapeJIRAText(r.getImageAlt(n), textContext(n)))
+				return
+			}
+		}
+		// JIRA image syntax: !url! or !url|alt=text!
+		attrs := r.imageAttributes(r.imageAlt(n), 0, 0)
+		if len(attrs) > 0 {
+			fmt.Fprintf(buf, "!%s|%s!", url, strings.Join(attrs, ","))
+		} else {
+			fmt.Fprintf(buf, "!%s!", url)
+		}
+	}
+}
+
+// imageAttributes builds the attribute list for an image; a width of 0 is
+// Options.ImageWidth, and a height of 0 is left out
+func (r *JIRARenderer) imageAttributes(alt string, width, height int) []string {
+	// JIRA does not accept other attributes alongside thumbnail
+	if r.options.ImageThumbnail {
+		return []string{"thumbnail"}
+	}
+	var attrs []string
+	if width == 0 {
+		width = r.options.ImageWidth
+	}
+	if width > 0 {
+		attrs = append(attrs, fmt.Sprintf("width=%d", width))
+	}
+	if height > 0 {
+		attrs = append(attrs, fmt.Sprintf("height=%d", height))
+	}
+	if r.options.SafeMode || r.options.compat(Compat12) {
+		alt = strings.Join(strings.Fields(altTextReplacer.Replace(alt)), " ")
+	}
+	if alt != "" {
+		attrs = append(attrs, "alt="+alt)
+	}
+	return attrs
+}
+
+// getImageAlt gets the alt text from an image node
+func (r *JIRARenderer) getImageAlt(n *ast.Image) string {
+	var alt strings.Builder
+	for child := n.FirstChild(); child != nil; child = child.NextSibling() {
+		if text, ok := child.(*ast.Text); ok {
+			alt.WriteString(decodeEntities(string(text.Segment.Value(r.source))))
+		}
+	}
+	return alt.String()
+}
+
+// renderList renders a list
+func (r *JIRARenderer) renderList(buf *strings.Builder, n *ast.List, entering bool) {
+	if r.isTimeline(n) {
+		if entering {
+			r.renderTimeline(buf, n)
+		}
+		return
+	}
+	if r.isActionList(n) {
+		if entering {
+			r.renderActionList(buf, n)
+		}
+		return
+	}
+	if entering {
+		// If we're already in a list (nested list), add a newline before
+		if len(r.listStack) > 0 {
+			buf.WriteString("\n")
+		}
+		r.listStack = append(r.listStack, n)
+		r.inTightList = n.IsTight
+	} else {
+		if len(r.listStack) > 0 {
+			r.listStack = r.listStack[:len(r.listStack)-1]
+		}
+		if len(r.listStack) == 0 {
+			r.inTightList = false
+			buf.WriteString("\n")
+		}
+	}
+}
+
+// renderListItem renders a list item
+func (r *JIRARenderer) renderListItem(buf *strings.Builder, n *ast.ListItem, entering bool) {
+	if entering {
+		if r.isStepList(n.Parent()) {
+			r.renderStep(buf)
+			return
+		}
+		// Build the list prefix based on nesting
+		prefix := r.buildListPrefix()
+		buf.WriteString(prefix)
+		buf.WriteString(" ")
+		if r.options.compat(Compat10) {
+			r.renderItemBlocks(buf, n)
+		}
+	} else {
+		buf.WriteString("\n")
+	}
+}
+
+// renderItemBlocks renders the blocks of a list item without blank lines
+// between them, which would end the JIRA list: paragraphs are separated by
+// \\ line breaks, and code blocks and nested lists follow on the next line;
+// from Compat12 that includes those that start the item, after a &nbsp;
+// placeholder, as JIRA reads a bare marker line as text
+func (r *JIRARenderer) renderItemBlocks(buf *strings.Builder, n *ast.ListItem) {
+	var previous ast.Node
+	for child := n.FirstChild(); child != nil; child = child.NextSibling() {
+		var block strings.Builder
+		r.walk(&block, child)
+		text := strings.Trim(block.String(), "\n")
+		if text == "" {
+			continue
+		}
+		if previous != nil {
+			if isTextBlock(previous) && isTextBlock(child) {
+				buf.WriteString("\\\\")
+			}
+			buf.WriteString("\n")
+		} else if startsItemLine(child) && r.options.compat(Compat12) {
+			buf.WriteString("&nbsp;\n")
+		}
+		buf.WriteString(text)
+		previous = child
+	}
+}
+
+// isTextBlock reports whether a block is a paragraph of a list item
+func isTextBlock(n ast.Node) bool {
+	switch n.(type) {
+	case *ast.Paragraph, *ast.TextBlock:
+		return true
+	}
+	return false
+}
+
+// startsItemLine reports whether a block that starts a list item goes on the
+// line after the item's marker
+func startsItemLine(n ast.Node) bool {
+	switch n.(type) {
+	case *ast.List, *ast.FencedCodeBlock, *ast.CodeBlock:
+		return true
+	}
+	return false
+}
+
+// buildListPrefix builds the appropriate list prefix based on nesting
+func (r *JIRARenderer) buildListPrefix() string {
+	var prefix strings.Builder
+	for _, node := range r.listStack {
+		if r.isStepList(node) || r.isActionList(node) {
+			// Steps and action items are not list items, so nested lists start afresh
+			continue
+		}
+		if list, ok := node.(*ast.List); ok {
+			if list.IsOrdered() {
+				prefix.WriteString("#")
+			} else {
+				prefix.WriteString("*")
+			}
+		}
+	}
+	return prefix.String()
+}
+
+// renderThematicBreak renders a horizontal rule
+func (r *JIRARenderer) renderThematicBreak(buf *strings.Builder, n *ast.ThematicBreak, entering bool) {
+	if entering {
+		buf.WriteString("----\n\n")
+	}
+}
+
+// renderBlockquote renders a blockquote
+func (r *JIRARenderer) renderBlockquote(buf *strings.Builder, n *ast.Blockquote, entering bool) {
+	// {quote} macros do not nest: the first {quote} inside one would close it.
+	// Nested blockquotes are rendered in the outer one, their paragraphs as
+	// bq. paragraphs.
+	if entering {
+		r.quoteDepth++
+		if r.quoteDepth == 1 || !r.options.compat(Compat12) {
+			buf.WriteString("{quote}\n")
+		}
+	} else {
+		r.quoteDepth--
+		if r.quoteDepth == 0 || !r.options.compat(Compat12) {
+			buf.WriteString("{quote}\n\n")
+		}
+	}
+}
+
+// renderTextBlock renders a text block
+func (r *JIRARenderer) renderTextBlock(buf *strings.Builder, n *ast.TextBlock, entering bool) {
+	// Text blocks are typically children of list items in tight lists
+	// We don't add extra newlines for them
+}
+
+// renderTable renders a table
+func (r *JIRARenderer) renderTable(buf *strings.Builder, n *east.Table, entering bool) {
+	if columns := r.roadmapColumns(n); columns != nil {
+		if entering {
+			r.renderRoadmap(buf, n, columns)
+		}
+		return
+	}
+	if entering && r.tableChoice(n) != ChoiceNoformat {
+		r.warnTableAlignment(n)
+	}
+	switch r.tableChoice(n) {
+	case ChoiceNoformat:
+		if entering {
+			buf.WriteString(r.noformatSource(n))
+		}
+		return
+	case ChoiceExpand:
+		if r.phaseOpen || r.expandDepth > 0 {
+			break
+		}
+		if entering {
+			buf.WriteString("{expand:Table}\n")
+		} else {
+			buf.WriteString("{expand}\n\n")
+		}
+		return
+	}
+	if !entering {
+		buf.WriteString("\n")
+	}
+}
+
+// renderTableHeader renders a table header row
+func (r *JIRARenderer) renderTableHeader(buf *strings.Builder, n *east.TableHeader, entering bool) {
+	if !entering {
+		buf.WriteString("\n")
+	}
+}
+
+// renderTableRow renders a table row
+func (r *JIRARenderer) renderTableRow(buf *strings.Builder, n *east.TableRow, entering bool) {
+	if !entering {
+		buf.WriteString("\n")
+	}
+}
+
+// renderTableCell renders a table cell
+func (r *JIRARenderer) renderTableCell(buf *strings.Builder, n *east.TableCell, entering bool) {
+	if entering {
+		// Check if this is a header cell
+		parent := n.Parent()
+		_, isHeader := parent.(*east.TableHeader)
+
+		if isHeader {
+			buf.WriteString("||")
+		} else {
+			buf.WriteString("|")
+		}
+		if !r.options.compat(Compat12) {
+			r.renderChildren(buf, n)
+			return
+		}
+		var cell strings.Builder
+		r.inTableCell, r.cellLists = true, nil
+		r.renderChildren(&cell, n)
+		r.inTableCell = false
+		buf.WriteString(cellContent(cell.String()))
+	} else {
+		// Check if this is the last cell in the row
+		if n.NextSibling() == nil {
+			parent := n.Parent()
+			_, isHeader := parent.(*east.TableHeader)
+			if isHeader {
+				buf.WriteString("||")
+			} else {
+				buf.WriteString("|")
+			}
+		}
+	}
+}
+
+// renderTaskCheckBox renders a task checkbox
+func (r *JIRARenderer) renderTaskCheckBox(buf *strings.Builder, n *east.TaskCheckBox, entering bool) {
+	if entering {
+		buf.WriteString(r.checkbox(n.IsChecked))
+	}
+}
@@ -0,0 +1,99 @@
+// Roadmap tables
+// Renders task | start | end | owner tables through a roadmap macro template
+
+package converter
+
+import (
+	"fmt"
+	"strings"
+	"text/template"
+
+	"github.com/astsu-dev/md2jira/jiraescape"
+	"github.com/yuin/goldmark/ast"
+	east "github.com/yuin/goldmark/extension/ast"
+)
+
+// RoadmapTask is a row of a roadmap table, as plain text with the characters
+// that end a macro parameter removed
+type RoadmapTask struct {
+	Task  string
+	Start string
+	End   string
+	Owner string
+}
+
+// RoadmapData is the data Options.RoadmapTemplate is executed with
+type RoadmapData struct {
+	Tasks []RoadmapTask
+}
+
+// roadmapColumns returns the column index of each roadmap field, or nil if the
+// table is not a roadmap: its header must name task, start and end columns
+func (r *JIRARenderer) roadmapColumns(n ast.Node) map[string]int {
+	table, ok := n.(*east.Table)
+	if !ok || r.options.RoadmapTemplate == nil {
+		return nil
+	}
+	header, ok := table.FirstChild().(*east.TableHeader)
+	if !ok {
+		return nil
+	}
+	columns := make(map[string]int)
+	i := 0
+	for cell := header.FirstChild(); cell != nil; cell = cell.NextSibling() {
+		name := strings.ToLower(strings.TrimSpace(plainText(r.source, cell)))
+		if _, seen := columns[name]; !seen {
+			columns[name] = i
+		}
+		i++
+	}
+	for _, required := range []string{"task", "start", "end"} {
+		if _, ok := columns[required]; !ok {
+			return nil
+		}
+	}
+	return columns
+}
+
+// renderRoadmap renders a roadmap table through the roadmap template, falling
+// back to a plain table if the template fails
+func (r *JIRARenderer) renderRoadmap(buf *strings.Builder, table *east.Table, columns map[string]int) {
+	var data RoadmapData
+	for row := table.FirstChild(); row != nil; row = row.NextSibling() {
+		if _, ok := row.(*east.TableRow); !ok {
+			continue
+		}
+		var cells []string
+		for cell := row.FirstChild(); cell != nil; cell = cell.NextSibling() {
+			cells = append(cells, plainText(r.source, cell))
+		}
+		field := func(name string) string {
+			if i, ok := columns[name]; ok && i < len(cells) {
+				return jiraescape.MacroParam(cells[i])
+			}
+			return ""
+		}
+		data.Tasks = append(data.Tasks, RoadmapTask{
+			Task:  field("task"),
+			Start: field("start"),
+			End:   field("end"),
+			Owner: field("owner"),
+		})
+	}
+
+	var out strings.Builder
+	if err := r.options.RoadmapTemplate.Execute(&out, data); err != nil {
+		r.addWarning(WarnRoadmapTemplate, fmt.Sprintf("roadmap template failed: %v; rendered as a table", err))
+		r.renderChildren(buf, table)
+		buf.WriteString("\n")
+		return
+	}
+	buf.WriteString(strings.TrimRight(out.String(), "\n") + "\n\n")
+}
+
+// DefaultRoadmapTemplate renders roadmap tasks as one {roadmap} macro with a
+// task line per row; replace it with the markup of the macro your site uses
+var DefaultRoadmapTemplate = template.Must(template.New("roadmap").Parse(
+	`{roadmap}
+{{range .Tasks}}task={{.Task}}|start={{.Start}}|end={{.End}}{{if .Owner}}|owner={{.Owner}}{{end}}
+{{end}}{roadmap}`))
@@ -0,0 +1,78 @@
+// Runbook rendering
+// Numbers procedure steps across sections and folds each phase into an {expand}
+
+package converter
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/astsu-dev/md2jira/jiraescape"
+	"github.com/yuin/goldmark/ast"
+)
+
+// runbookPhaseLevel is the heading level that starts a runbook phase
+const runbookPhaseLevel = 2
+
+// isPhaseHeading reports whether a heading starts a runbook phase
+func (r *JIRARenderer) isPhaseHeading(n *ast.Heading) bool {
+	return r.options.Runbook && n.Level == runbookPhaseLevel
+}
+
+// isStepList reports whether a list holds runbook steps: in runbook mode,
+// every top-level ordered list is a sequence of steps
+func (r *JIRARenderer) isStepList(n ast.Node) bool {
+	list, ok := n.(*ast.List)
+	if !ok || !r.options.Runbook || !list.IsOrdered() {
+		return false
+	}
+	_, top := list.Parent().(*ast.Document)
+	return top
+}
+
+// renderPhase opens the {expand} of a runbook phase, closing the previous one
+func (r *JIRARenderer) renderPhase(buf *strings.Builder, n *ast.Heading) {
+	r.closePhase(buf)
+	title := jiraescape.MacroParam(plainText(r.source, n))
+	fmt.Fprintf(buf, "{expand:%s}\n", title)
+	r.phaseOpen = true
+}
+
+// closePhase closes the {expand} of the current runbook phase, if any
+func (r *JIRARenderer) closePhase(buf *strings.Builder) {
+	if r.phaseOpen {
+		buf.WriteString("{expand}\n\n")
+		r.phaseOpen = false
+	}
+}
+
+// renderStep writes the checkpoint marker and number of the next runbook step
+func (r *JIRARenderer) renderStep(buf *strings.Builder) {
+	r.steps++
+	fmt.Fprintf(buf, "%s*Step %d.* ", r.checkbox(false), r.steps)
+}
+
+// backslashEscapeRe matches a Markdown backslash escape
+var backslashEscapeRe = regexp.MustCompile(`\\([!-/:-@\[-` + "`" + `{-~])`)
+
+// plainText returns the text of a node's descendants without markup
+func plainText(source []byte, n ast.Node) string {
+	var text strings.Builder
+	ast.Walk(n, func(node ast.Node, entering bool) (ast.WalkStatus, error) {
+		if !entering {
+			return ast.WalkContinue, nil
+		}
+		switch t := node.(type) {
+		case *ast.Text:
+			text.WriteString(backslashEscapeRe.ReplaceAllString(string(t.Segment.Value(source)), "$1"))
+			if t.SoftLineBreak() || t.HardLineBreak() {
+				text.WriteByte(' ')
+			}
+		case *ast.String:
+			text.Write(t.Value)
+		}
+		return ast.WalkContinue, nil
+	})
+	return decodeEntities(text.String())
+}
@@ -0,0 +1,69 @@
+// Safe mode
+// Neutralizes constructs of untrusted Markdown that could inject active JIRA
+// markup, for services converting user-submitted content
+
+package converter
+
+import (
+	"regexp"
+	"strings"
+)
+
+// safeOptions returns the options a renderer uses: in safe mode raw HTML is
+// always sanitized and converted, text is always escaped, and media embeds
+// become links instead of macros
+func safeOptions(opts Options) Options {
+	if !opts.SafeMode {
+		return opts
+	}
+	opts.PreserveHTML = false
+	opts.MediaMacro = ""
+	if opts.EscapeMode == EscapeNone {
+		opts.EscapeMode = EscapeMinimal
+	}
+	if opts.HTMLSanitizer == nil {
+		opts.HTMLSanitizer = NewBasicSanitizer()
+	}
+	return opts
+}
+
+// safeURLReplacer percent-encodes the characters that end a JIRA link or
+// image, or start markup inside one
+var safeURLReplacer = strings.NewReplacer(
+	"|", "%7C", "[", "%5B", "]", "%5D", "{", "%7B", "}", "%7D", "!", "%21",
+	" ", "%20", "\t", "%09", "\n", "%0A", "\r", "%0D",
+)
+
+// safeURL returns a link or image destination that is safe to embed, and
+// false for schemes that execute code or inline content (javascript:,
+// vbscript:, data:); outside safe mode the URL is returned unchanged
+func (o Options) safeURL(url string) (string, bool) {
+	if !o.SafeMode {
+		return url, true
+	}
+	if unsafeSchemeRe.MatchString(urlScheme(url)) {
+		return "", false
+	}
+	return safeURLReplacer.Replace(url), true
+}
+
+// safeLanguageRe matches code languages that cannot break out of {code:...}
+var safeLanguageRe = regexp.MustCompile(`^[a-z0-9#+._-]+$`)
+
+// macroTagRe matches macro tags such as {code} or {noformat:title=x}
+var macroTagRe = regexp.MustCompile(`\{([A-Za-z][A-Za-z0-9-]*)([}:])`)
+
+// macroBody returns the verbatim content of a macro. In safe mode a closing
+// tag of the macro inside it, which would end the macro early and let the
+// rest be rendered as markup, is broken with a zero-width space.
+func (o Options) macroBody(macro, body string) string {
+	if !o.SafeMode || !strings.Contains(body, "{") {
+		return body
+	}
+	return macroTagRe.ReplaceAllStringFunc(body, func(tag string) string {
+		if !strings.EqualFold(macroTagRe.FindStringSubmatch(tag)[1], macro) {
+			return tag
+		}
+		return "{\u200b" + tag[1:]
+	})
+}
@@ -0,0 +1,54 @@
+package converter
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestSafeModeRawHTML(t *testing.T) {
+	tests := []struct {
+		name     string
+		markdown string
+		want     string
+	}{
+		{
+			name:     "link in HTML block",
+			markdown: "<div>[click|javascript:alert(1)]</div>",
+			want:     `\[click|javascript:alert(1)]`,
+		},
+		{
+			name:     "image in HTML block",
+			markdown: "<div>!data:image/svg+xml,abc!</div>",
+			want:     `\!data:image/svg+xml,abc!`,
+		},
+		{
+			name:     "macro in HTML block",
+			markdown: "<div>{color:red}x{color}</div>",
+			want:     `\{color:red}x\{color}`,
+		},
+		{
+			name:     "link in inline HTML",
+			markdown: "Inline <span>[a|javascript:x]</span> end",
+			want:     `Inline \[a|javascript:x] end`,
+		},
+		{
+			name:     "character references",
+			markdown: "<div>&#91;y&#93; &amp; <b>bold</b></div>",
+			want:     `\[y\] & *bold*`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := render(t, tt.markdown, Options{SafeMode: true}); got != tt.want {
+				t.Errorf("got %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSafeModeAggressiveKeepsReferences(t *testing.T) {
+	got := render(t, "<div>&#35;1 [x|javascript:y]</div>", Options{SafeMode: true, EscapeMode: EscapeAggressive})
+	if !strings.Contains(got, `\[x\|javascript:y\]`) || strings.Contains(got, "&") {
+		t.Errorf("got %q", got)
+	}
+}
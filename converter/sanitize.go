@@ -0,0 +1,94 @@
+// HTML sanitization
+// Strips active content from raw HTML before it is converted or preserved
+
+package converter
+
+import (
+	stdhtml "html"
+	"regexp"
+	"strings"
+)
+
+// HTMLSanitizer cleans raw HTML before conversion. A bluemonday *Policy
+// satisfies it, so existing policies can be used unchanged.
+type HTMLSanitizer interface {
+	Sanitize(html string) string
+}
+
+// BasicSanitizer removes scripts, styles, embedded objects, event handler
+// attributes, script URLs and tracking pixels, keeping other markup intact
+type BasicSanitizer struct{}
+
+// NewBasicSanitizer creates the built-in sanitizer
+func NewBasicSanitizer() *BasicSanitizer {
+	return &BasicSanitizer{}
+}
+
+// activeElementRe matches elements whose content must not survive as text
+var activeElementRe = regexp.MustCompile(`(?is)<(script|style|noscript|template)\b[^>]*>.*?</(?:script|style|noscript|template)\s*>`)
+
+// activeTagRe matches active tags left without a closing tag
+var activeTagRe = regexp.MustCompile(`(?is)</?(?:script|style|noscript|template|object|embed|applet|base|meta|link|form)\b[^>]*>`)
+
+// eventAttrRe matches on* event handler attributes
+var eventAttrRe = regexp.MustCompile(`(?is)\s+on[a-z]+\s*=\s*(?:"[^"]*"|'[^']*'|[^\s>]+)`)
+
+// urlAttrRe matches URL attributes so their scheme can be checked
+var urlAttrRe = regexp.MustCompile(`(?is)\s+(?:href|src|action|formaction|poster|background)\s*=\s*(?:"([^"]*)"|'([^']*)'|([^\s>]+))`)
+
+// unsafeSchemeRe matches URL schemes that execute code
+var unsafeSchemeRe = regexp.MustCompile(`(?i)^\s*(?:javascript|vbscript|data):`)
+
+// imgTagRe matches <img> tags
+var imgTagRe = regexp.MustCompile(`(?is)<img\b[^>]*>`)
+
+// Sanitize returns html with active content removed
+func (s *BasicSanitizer) Sanitize(html string) string {
+	html = activeElementRe.ReplaceAllString(html, "")
+	html = activeTagRe.ReplaceAllString(html, "")
+	html = eventAttrRe.ReplaceAllString(html, "")
+	html = urlAttrRe.ReplaceAllStringFunc(html, func(attr string) string {
+		m := urlAttrRe.FindStringSubmatch(attr)
+		if unsafeSchemeRe.MatchString(urlScheme(m[1] + m[2] + m[3])) {
+			return ""
+		}
+		return attr
+	})
+	return imgTagRe.ReplaceAllStringFunc(html, func(tag string) string {
+		if isTrackingPixel(tag) {
+			return ""
+		}
+		return tag
+	})
+}
+
+// isTrackingPixel reports whether an <img> tag is an invisible 0 or 1 pixel image
+func isTrackingPixel(tag string) bool {
+	for _, attr := range []string{"width", "height"} {
+		value := strings.TrimSuffix(strings.TrimSpace(htmlAttr(tag, attr)), "px")
+		if value == "0" || value == "1" {
+			return true
+		}
+	}
+	return false
+}
+
+// urlScheme decodes a URL attribute value and removes the whitespace and
+// control characters browsers ignore inside URL schemes
+func urlScheme(value string) string {
+	return strings.Map(func(c rune) rune {
+		if c <= ' ' {
+			return -1
+		}
+		return c
+	}, stdhtml.UnescapeString(value))
+}
+
+// sanitizeHTML applies the configured sanitizer, reporting whether anything changed
+func sanitizeHTML(opts Options, html string) (string, bool) {
+	if opts.HTMLSanitizer == nil {
+		return html, false
+	}
+	clean := opts.HTMLSanitizer.Sanitize(html)
+	return clean, clean != html
+}
@@ -0,0 +1,63 @@
+// Spacer paragraphs
+// Editors export vertical space as &nbsp;-only paragraphs and <p><br></p>
+
+package converter
+
+import (
+	stdhtml "html"
+	"regexp"
+	"strings"
+
+	"github.com/yuin/goldmark/ast"
+)
+
+// spacerParagraphRe matches HTML paragraphs holding only whitespace, &nbsp; and <br>
+var spacerParagraphRe = regexp.MustCompile(`(?is)<p\b[^>]*>(?:\s|&nbsp;|&#160;|&#x0*a0;|<br\s*/?>)*</p\s*>`)
+
+// spacerBrRe matches a lone <br> tag
+var spacerBrRe = regexp.MustCompile(`(?i)^<br\s*/?>$`)
+
+// spacerBreak is the forced break emitted for a spacer when PreserveSpacers is set
+const spacerBreak = "\\\\"
+
+// isBlankText reports whether text holds only whitespace and non-breaking spaces
+func isBlankText(text string) bool {
+	return strings.TrimSpace(strings.ReplaceAll(stdhtml.UnescapeString(text), "\u00a0", " ")) == ""
+}
+
+// isSpacerParagraph reports whether a paragraph holds only non-breaking spaces
+// and line breaks, so it only adds vertical space
+func isSpacerParagraph(source []byte, n ast.Node) bool {
+	if n.FirstChild() == nil {
+		return false
+	}
+	for child := n.FirstChild(); child != nil; child = child.NextSibling() {
+		switch c := child.(type) {
+		case *ast.Text:
+			if !isBlankText(string(c.Segment.Value(source))) {
+				return false
+			}
+		case *ast.RawHTML:
+			var raw strings.Builder
+			for i := 0; i < c.Segments.Len(); i++ {
+				segment := c.Segments.At(i)
+				raw.Write(segment.Value(source))
+			}
+			if !spacerBrRe.MatchString(raw.String()) {
+				return false
+			}
+		default:
+			return false
+		}
+	}
+	return true
+}
+
+// convertSpacers removes HTML spacer paragraphs, or turns them into forced breaks
+func (r *JIRARenderer) convertSpacers(html string) string {
+	replacement := "\n"
+	if r.options.PreserveSpacers {
+		replacement = "\n" + spacerBreak + "\n"
+	}
+	return spacerParagraphRe.ReplaceAllString(html, replacement)
+}
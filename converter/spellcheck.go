@@ -0,0 +1,190 @@
+// Spellcheck integration
+// Reports misspelled words in prose text nodes as warnings
+
+package converter
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/yuin/goldmark/ast"
+	east "github.com/yuin/goldmark/extension/ast"
+)
+
+// SpellChecker decides whether a word is spelled correctly
+type SpellChecker interface {
+	Check(word string) bool
+}
+
+// Dictionary is a SpellChecker backed by hunspell .dic files, expanded with
+// the prefix and suffix rules of their .aff files
+type Dictionary struct {
+	words map[string]bool
+}
+
+// NewDictionary creates an empty dictionary
+func NewDictionary() *Dictionary {
+	return &Dictionary{words: make(map[string]bool)}
+}
+
+// LoadDictionary loads one or more hunspell .dic files, and the .aff files
+// next to them
+func LoadDictionary(paths ...string) (*Dictionary, error) {
+	d := NewDictionary()
+	for _, path := range paths {
+		if err := d.LoadFile(path); err != nil {
+			return nil, err
+		}
+	}
+	return d, nil
+}
+
+// LoadFile adds the words from a hunspell .dic file. Words with affix flags
+// (word/FLAGS) are added with the forms the rules of the .aff file of the
+// same name give them; without one, only the words themselves are added.
+func (d *Dictionary) LoadFile(path string) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	var aff *affixes
+	affPath := strings.TrimSuffix(path, filepath.Ext(path)) + ".aff"
+	if _, err := os.Stat(affPath); err == nil {
+		if aff, err = loadAffixes(affPath); err != nil {
+			return err
+		}
+	}
+
+	scanner := bufio.NewScanner(f)
+	first := true
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		// The first line of a .dic file is an approximate word count
+		if first {
+			first = false
+			if _, err := strconv.Atoi(line); err == nil {
+				continue
+			}
+		}
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		// Drop morphological fields and split off the affix flags (word/FLAGS)
+		if i := strings.IndexAny(line, "\t "); i >= 0 {
+			line = line[:i]
+		}
+		word, flags, _ := strings.Cut(line, "/")
+		if aff == nil || flags == "" {
+			d.Add(word)
+			continue
+		}
+		for _, form := range aff.expand(word, flags) {
+			d.Add(form)
+		}
+	}
+	return scanner.Err()
+}
+
+// Add adds a word to the dictionary
+func (d *Dictionary) Add(word string) {
+	d.words[word] = true
+}
+
+// Check reports whether word is in the dictionary
+func (d *Dictionary) Check(word string) bool {
+	if word == "" || d.words[word] {
+		return true
+	}
+	// Capitalized and upper-case forms of dictionary words are accepted
+	lower := strings.ToLower(word)
+	if lower != word && d.words[lower] {
+		return true
+	}
+	runes := []rune(lower)
+	runes[0] = unicode.ToUpper(runes[0])
+	if title := string(runes); title != word && d.words[title] {
+		return true
+	}
+	return false
+}
+
+// spellcheck walks prose text nodes and returns a warning per misspelled word
+func spellcheck(doc ast.Node, source []byte, checker SpellChecker) []Warning {
+	var warnings []Warning
+	reported := make(map[string]bool)
+	_ = ast.Walk(doc, func(node ast.Node, entering bool) (ast.WalkStatus, error) {
+		if !entering {
+			return ast.WalkContinue, nil
+		}
+		var text string
+		offset := -1
+		switch n := node.(type) {
+		case *ast.CodeSpan, *ast.FencedCodeBlock, *ast.CodeBlock, *ast.HTMLBlock,
+			*ast.RawHTML, *ast.AutoLink, *east.TaskCheckBox:
+			return ast.WalkSkipChildren, nil
+		case *ast.Text:
+			text = string(n.Segment.Value(source))
+			offset = n.Segment.Start
+		case *ast.String:
+			text = string(n.Value)
+		default:
+			return ast.WalkContinue, nil
+		}
+		for _, word := range splitWords(text) {
+			if reported[word.text] || checker.Check(word.text) {
+				continue
+			}
+			reported[word.text] = true
+			w := newWarning(source, node, WarnMisspelling, fmt.Sprintf("Possible misspelling: %q", word.text))
+			if offset >= 0 {
+				w.Line, w.Column = offsetPosition(source, offset+word.offset)
+			}
+			warnings = append(warnings, w)
+		}
+		return ast.WalkContinue, nil
+	})
+	return warnings
+}
+
+// textWord is a word and its byte offset within the text it was split from
+type textWord struct {
+	text   string
+	offset int
+}
+
+// splitWords splits text into checkable words, skipping tokens with digits
+func splitWords(text string) []textWord {
+	var words []textWord
+	isWordRune := func(c rune) bool {
+		return unicode.IsLetter(c) || unicode.IsDigit(c) || c == '\''
+	}
+	start := -1
+	for i, c := range text + " " {
+		if isWordRune(c) {
+			if start < 0 {
+				start = i
+			}
+			continue
+		}
+		if start < 0 {
+			continue
+		}
+		field := text[start:i]
+		trimmed := strings.TrimLeft(field, "'")
+		offset := start + len(field) - len(trimmed)
+		trimmed = strings.TrimRight(trimmed, "'")
+		start = -1
+		if len([]rune(trimmed)) < 2 || strings.IndexFunc(trimmed, unicode.IsDigit) >= 0 {
+			continue
+		}
+		words = append(words, textWord{text: trimmed, offset: offset})
+	}
+	return words
+}
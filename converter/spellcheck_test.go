@@ -0,0 +1,137 @@
+package converter
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// testAffixes are rules in the style of the en_US dictionary
+const testAffixes = `SET UTF-8
+TRY esianrtolcdugmphbyfvkwz
+
+PFX A Y 1
+PFX A   0     re         .
+
+SFX D Y 4
+SFX D   0     d          e
+SFX D   y     ied        [^aeiou]y
+SFX D   0     ed         [^ey]
+SFX D   0     ed         [aeiou]y
+
+SFX S Y 2
+SFX S   y     ies        [^aeiou]y
+SFX S   0     s          [aeiou]y
+
+SFX N N 1
+SFX N   0     ness       .
+
+NEEDAFFIX X
+`
+
+func TestDictionaryAffixes(t *testing.T) {
+	dir := t.TempDir()
+	dic := filepath.Join(dir, "test.dic")
+	writeFile(t, dic, "5\ncreate/AD\ntry/DS\nplay/DS\nkind/N\nbrad/X\n# comment\nJIRA\n")
+	writeFile(t, filepath.Join(dir, "test.aff"), testAffixes)
+
+	d, err := LoadDictionary(dic)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		word string
+		want bool
+	}{
+		{"create", true},
+		{"created", true},
+		{"recreate", true},
+		{"recreated", true},
+		{"Recreated", true},
+		{"try", true},
+		{"tried", true},
+		{"tries", true},
+		{"tryed", false},
+		{"played", true},
+		{"plays", true},
+		{"plaied", false},
+		{"kindness", true},
+		{"rekind", false},
+		{"brad", false},
+		{"JIRA", true},
+		{"creat", false},
+	}
+	for _, tt := range tests {
+		if got := d.Check(tt.word); got != tt.want {
+			t.Errorf("Check(%q) = %v, want %v", tt.word, got, tt.want)
+		}
+	}
+}
+
+func TestDictionaryFlagModes(t *testing.T) {
+	tests := []struct {
+		name  string
+		aff   string
+		dic   string
+		words []string
+	}{
+		{
+			name:  "long flags",
+			aff:   "FLAG long\nSFX Aa Y 1\nSFX Aa 0 s .\nSFX Bb Y 1\nSFX Bb 0 ed .\n",
+			dic:   "1\nwalk/AaBb\n",
+			words: []string{"walk", "walks", "walked"},
+		},
+		{
+			name:  "numeric flags",
+			aff:   "FLAG num\nSFX 101 Y 1\nSFX 101 0 s .\nSFX 7 Y 1\nSFX 7 0 ing .\n",
+			dic:   "1\nwalk/101,7\n",
+			words: []string{"walk", "walks", "walking"},
+		},
+		{
+			name:  "flag aliases",
+			aff:   "AF 2\nAF S\nAF DS\nSFX S Y 1\nSFX S 0 s .\nSFX D Y 1\nSFX D 0 ed .\n",
+			dic:   "2\nwalk/2\ntalk/1\n",
+			words: []string{"walks", "walked", "talks"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			dic := filepath.Join(dir, "test.dic")
+			writeFile(t, dic, tt.dic)
+			writeFile(t, filepath.Join(dir, "test.aff"), tt.aff)
+			d, err := LoadDictionary(dic)
+			if err != nil {
+				t.Fatal(err)
+			}
+			for _, word := range tt.words {
+				if !d.Check(word) {
+					t.Errorf("Check(%q) = false", word)
+				}
+			}
+			if d.Check("talked") {
+				t.Error(`Check("talked") = true`)
+			}
+		})
+	}
+}
+
+func TestDictionaryWithoutAffixes(t *testing.T) {
+	dic := filepath.Join(t.TempDir(), "team.dic")
+	writeFile(t, dic, "md2jira\ncreate/AD\n")
+	d, err := LoadDictionary(dic)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !d.Check("md2jira") || !d.Check("create") || d.Check("created") {
+		t.Error("a .dic file without .aff should add its words only")
+	}
+}
+
+// writeFile writes a test file, failing the test on errors
+func writeFile(t *testing.T, path, data string) {
+	t.Helper()
+	if err := os.WriteFile(path, []byte(data), 0o644); err != nil {
+		t.Fatal(err)
+	}
+}
@@ -0,0 +1,92 @@
+// Conversion statistics
+// Counts the constructs in a document and the size of the converted output
+
+package converter
+
+import (
+	"bytes"
+	"unicode/utf8"
+
+	"github.com/yuin/goldmark/ast"
+	east "github.com/yuin/goldmark/extension/ast"
+)
+
+// Stats summarizes a conversion
+type Stats struct {
+	// InputLines and InputChars measure the Markdown source
+	InputLines int `json:"input_lines"`
+	InputChars int `json:"input_chars"`
+	// OutputChars measures the converted output
+	OutputChars int `json:"output_chars"`
+	Headings    int `json:"headings"`
+	Paragraphs  int `json:"paragraphs"`
+	Lists       int `json:"lists"`
+	CodeBlocks  int `json:"code_blocks"`
+	Tables      int `json:"tables"`
+	Links       int `json:"links"`
+	Images      int `json:"images"`
+	HTMLBlocks  int `json:"html_blocks"`
+	// Warnings counts warnings by severity name
+	Warnings map[string]int `json:"warnings"`
+	// UnknownNodes counts the nodes the renderer has no rendering for, by
+	// kind, e.g. those of goldmark extensions; only their children are
+	// rendered
+	UnknownNodes map[string]int `json:"unknown_nodes,omitempty"`
+}
+
+// unknownNodes counts the nodes of kinds a renderer does not know
+type unknownNodes map[string]int
+
+// add counts a node
+func (u *unknownNodes) add(node ast.Node) {
+	if *u == nil {
+		*u = make(unknownNodes)
+	}
+	(*u)[node.Kind().String()]++
+}
+
+// collectStats counts the constructs in doc and summarizes the conversion
+func collectStats(doc ast.Node, source []byte, output string, warnings []Warning, unknown unknownNodes) Stats {
+	stats := Stats{
+		InputChars:   utf8.RuneCount(source),
+		OutputChars:  utf8.RuneCountInString(output),
+		Warnings:     make(map[string]int),
+		UnknownNodes: unknown,
+	}
+	if len(source) > 0 {
+		stats.InputLines = bytes.Count(source, []byte("\n")) + 1
+		if source[len(source)-1] == '\n' {
+			stats.InputLines--
+		}
+	}
+
+	ast.Walk(doc, func(node ast.Node, entering bool) (ast.WalkStatus, error) {
+		if !entering {
+			return ast.WalkContinue, nil
+		}
+		switch node.(type) {
+		case *ast.Heading:
+			stats.Headings++
+		case *ast.Paragraph:
+			stats.Paragraphs++
+		case *ast.List:
+			stats.Lists++
+		case *ast.FencedCodeBlock, *ast.CodeBlock:
+			stats.CodeBlocks++
+		case *east.Table:
+			stats.Tables++
+		case *ast.Link, *ast.AutoLink:
+			stats.Links++
+		case *ast.Image:
+			stats.Images++
+		case *ast.HTMLBlock:
+			stats.HTMLBlocks++
+		}
+		return ast.WalkContinue, nil
+	})
+
+	for _, w := range warnings {
+		stats.Warnings[w.Severity.String()]++
+	}
+	return stats
+}
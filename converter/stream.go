@@ -0,0 +1,138 @@
+// Streaming conversion
+// Emits converted top-level blocks one at a time for progressive display
+
+package converter
+
+import (
+	"context"
+	"io"
+	"strings"
+	"time"
+)
+
+// Chunk is the converted markup of a top-level block
+type Chunk struct {
+	// Output is the block's JIRA markup without surrounding blank lines;
+	// joining the chunks with blank lines gives the converted document
+	Output string
+	// Line is the 1-based source line where the block starts (0 for the
+	// header and the trailing sections, such as link endnotes, the footer and
+	// the provenance trailer)
+	Line int
+}
+
+// ConvertStream converts Markdown from r, sending each top-level block on the
+// first channel as soon as it is converted and its warnings on the second.
+// The input is read in full first, because reference links and footnotes may
+// point forward; reading, encoding (see DecodeSource), template and
+// compatibility level errors are returned directly. Both channels are closed
+// when the conversion finishes or ctx is cancelled, and callers must receive
+// from both (e.g. in one select loop) until then.
+func (c *Converter) ConvertStream(ctx context.Context, r io.Reader) (<-chan Chunk, <-chan Warning, error) {
+	input, err := io.ReadAll(r)
+	if err == nil {
+		input, err = DecodeSource(input)
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+	if err := checkCompatLevel(c.options.CompatLevel); err != nil {
+		return nil, nil, err
+	}
+	header, footer, err := executeTemplates(c.options)
+	if err != nil {
+		return nil, nil, err
+	}
+	chunks := make(chan Chunk)
+	warnings := make(chan Warning)
+	go c.stream(ctx, input, header, footer, chunks, warnings)
+	return chunks, warnings, nil
+}
+
+// stream renders the blocks of source and sends them until done or cancelled
+func (c *Converter) stream(ctx context.Context, input []byte, header, footer string, chunks chan<- Chunk, warnings chan<- Warning) {
+	defer close(chunks)
+	defer close(warnings)
+
+	start := time.Now()
+	opts := c.options
+	source := normalizeSource(input, opts)
+	doc := parseMarkdown(source, opts)
+	renderer := NewJIRARenderer(source, opts)
+	renderer.collectFootnotes(doc)
+	renderer.collectAnchors(doc)
+
+	// send delivers a chunk followed by the warnings raised since the last one
+	sent := 0
+	var outputLen int
+	// body keeps the chunks for the output hash of the provenance trailer
+	var body []string
+	send := func(output string, line int) bool {
+		if output = cleanOutput(output); output != "" {
+			outputLen += len(output)
+			if opts.ProvenanceTrailer {
+				body = append(body, output)
+			}
+			select {
+			case chunks <- Chunk{Output: output, Line: line}:
+			case <-ctx.Done():
+				return false
+			}
+		}
+		return sendWarnings(ctx, warnings, renderer.warnings[sent:], &sent)
+	}
+
+	if !send(templateMarkup(header, opts), 0) {
+		return
+	}
+	for node := doc.FirstChild(); node != nil; node = node.NextSibling() {
+		var buf strings.Builder
+		renderer.walk(&buf, node)
+		line, _ := nodePosition(source, node)
+		if !send(buf.String(), line) {
+			return
+		}
+	}
+
+	var trailer strings.Builder
+	renderer.closePhase(&trailer)
+	renderer.renderEndnotes(&trailer)
+	if footer != "" {
+		trailer.WriteString("\n\n" + templateMarkup(footer, opts))
+	}
+	if opts.ProvenanceTrailer {
+		provenance := NewProvenance(input)
+		if opts.compat(Compat12) {
+			provenance = provenance.WithOutput(strings.Join(append(body, cleanOutput(trailer.String())), "\n\n"))
+		}
+		trailer.WriteString("\n\n" + provenance.Trailer())
+	}
+	if !send(trailer.String(), 0) {
+		return
+	}
+
+	var checks []Warning
+	if opts.CheckLinks {
+		checks = append(checks, newLinkChecker(opts).Check(doc, source)...)
+	}
+	if opts.SpellChecker != nil {
+		checks = append(checks, spellcheck(doc, source, opts.SpellChecker)...)
+	}
+	var done int
+	if sendWarnings(ctx, warnings, checks, &done) {
+		opts.observeConversion("stream", start, outputLen, append(renderer.warnings, checks...))
+	}
+}
+
+// sendWarnings sends warnings, counting them in sent, until ctx is cancelled
+func sendWarnings(ctx context.Context, warnings chan<- Warning, pending []Warning, sent *int) bool {
+	for _, w := range pending {
+		select {
+		case warnings <- w:
+			*sent++
+		case <-ctx.Done():
+			return false
+		}
+	}
+	return true
+}
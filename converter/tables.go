@@ -0,0 +1,120 @@
+// Table cells and column alignment
+// A JIRA table row is a single line, so line breaks and inline HTML lists and
+// paragraphs in cells become \\ line breaks. JIRA tables have no alignment,
+// so centered and right-aligned columns are reported; ADF aligns the
+// paragraphs of their cells instead.
+
+package converter
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	east "github.com/yuin/goldmark/extension/ast"
+)
+
+// alignmentNames are the names of the alignments JIRA drops, as warned
+var alignmentNames = map[east.Alignment]string{
+	east.AlignCenter: "centered",
+	east.AlignRight:  "right-aligned",
+}
+
+// adfAlignments are the ADF alignment marks of the column alignments; left
+// is the default and has none
+var adfAlignments = map[east.Alignment]string{
+	east.AlignCenter: "center",
+	east.AlignRight:  "end",
+}
+
+// warnTableAlignment reports the centered and right-aligned columns of a
+// table, which render left-aligned
+func (r *JIRARenderer) warnTableAlignment(n *east.Table) {
+	if !r.options.WarnOnUnsupported {
+		return
+	}
+	var columns []string
+	for i, alignment := range n.Alignments {
+		if name, ok := alignmentNames[alignment]; ok {
+			columns = append(columns, fmt.Sprintf("%d (%s)", i+1, name))
+		}
+	}
+	if len(columns) == 0 {
+		return
+	}
+	noun := "column"
+	if len(columns) > 1 {
+		noun = "columns"
+	}
+	r.addWarning(WarnTableAlignment, fmt.Sprintf("%s %s rendered left-aligned: JIRA tables have no alignment", noun, strings.Join(columns, ", ")))
+}
+
+// cellAlignment returns the alignment mark of the paragraph of a table cell,
+// or nil
+func (r *ADFRenderer) cellAlignment(cell *east.TableCell) []ADFMark {
+	align, ok := adfAlignments[cell.Alignment]
+	if !ok || !r.options.compat(Compat8) {
+		return nil
+	}
+	return []ADFMark{{Type: "alignment", Attrs: map[string]any{"align": align}}}
+}
+
+// cellBreakRe matches the hard line breaks and blank lines of rendered cell
+// content, with the whitespace around them
+var cellBreakRe = regexp.MustCompile(`[ \t]*(?:\\\\\n|\n[ \t]*\n)\s*`)
+
+// cellHTMLRe matches the inline HTML tags that start lines in a cell
+var cellHTMLRe = regexp.MustCompile(`(?i)^<(/?)(ul|ol|li|p)(?:\s[^>]*)?>$`)
+
+// cellContent fits rendered content into a cell: breaks and blank lines
+// become \\, other newlines spaces, and a trailing backslash, which would
+// escape the | closing the cell, is kept apart from it
+func cellContent(content string) string {
+	content = strings.TrimSpace(content)
+	content = cellBreakRe.ReplaceAllLiteralString(content, `\\`)
+	content = strings.ReplaceAll(content, "\n", " ")
+	content = strings.TrimSuffix(content, `\\`)
+	if strings.HasSuffix(content, `\`) {
+		content += " "
+	}
+	return content
+}
+
+// cellHTML renders the list and paragraph tags of inline HTML in a table
+// cell, flattening lists into lines that start with - or their number, and
+// reports whether html was one of them; written is the cell content so far
+func (r *JIRARenderer) cellHTML(html string, written int) (string, bool) {
+	m := cellHTMLRe.FindStringSubmatch(strings.TrimSpace(html))
+	if m == nil {
+		return "", false
+	}
+	closing, tag := m[1] == "/", strings.ToLower(m[2])
+	lineBreak := ""
+	if written > 0 {
+		lineBreak = `\\`
+	}
+	switch {
+	case closing && tag != "li" && tag != "p":
+		if len(r.cellLists) == 0 {
+			break
+		}
+		// Text after the outermost list goes on a line of its own
+		r.cellLists = r.cellLists[:len(r.cellLists)-1]
+		if len(r.cellLists) == 0 {
+			return lineBreak, true
+		}
+	case closing:
+	case tag == "ul":
+		r.cellLists = append(r.cellLists, -1)
+	case tag == "ol":
+		r.cellLists = append(r.cellLists, 0)
+	case tag == "p":
+		return lineBreak, true
+	case len(r.cellLists) > 0 && r.cellLists[len(r.cellLists)-1] >= 0:
+		r.cellLists[len(r.cellLists)-1]++
+		return fmt.Sprintf("%s%d. ", lineBreak, r.cellLists[len(r.cellLists)-1]), true
+	default:
+		return lineBreak + "- ", true
+	}
+	return "", true
+}
@@ -0,0 +1,28 @@
+package converter
+
+import "testing"
+
+func TestTableCellContent(t *testing.T) {
+	tests := []struct {
+		name string
+		cell string
+		want string
+	}{
+		{"line break", "x<br>y", "|x\\\\y|"},
+		{"trailing line break", "a<br>", "|a|"},
+		{"unordered list", "<ul><li>one</li><li>two</li></ul>", "|- one\\\\- two|"},
+		{"ordered list", "<ol><li>one</li><li>two</li></ol>", "|1. one\\\\2. two|"},
+		{"paragraphs", "<p>a</p><p>b</p>", "|a\\\\b|"},
+		{"trailing backslash", `a\\`, `|a\ |`},
+		{"escaped pipe", "`x` \\| y", `|{{x}} \| y|`},
+		{"list marker", "- z", "|- z|"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			markdown := "| h |\n|---|\n| " + tt.cell + " |"
+			if got := render(t, markdown, Options{}); got != "||h||\n"+tt.want {
+				t.Errorf("got %q, want %q", got, "||h||\n"+tt.want)
+			}
+		})
+	}
+}
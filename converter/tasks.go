@@ -0,0 +1,151 @@
+// Task lists
+// Options.TaskStyle writes the checkboxes of task lists, runbook steps and
+// action items independently of the emoji, for instances that render ( ) as
+// something other than an empty box
+
+package converter
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/yuin/goldmark/ast"
+	east "github.com/yuin/goldmark/extension/ast"
+)
+
+// TaskStyle selects how checkboxes are written
+type TaskStyle int
+
+const (
+	// TaskAuto writes checkboxes as Options.Symbols does; it is the zero value
+	TaskAuto TaskStyle = iota
+	// TaskEmoticons writes checkboxes as the dialect does with emoticons,
+	// (/) and ( ) or ☑ and ☐ for Cloud, whatever Options.Symbols is
+	TaskEmoticons
+	// TaskText writes checkboxes as [x] and [ ]
+	TaskText
+	// TaskUnicode writes checkboxes as the ballot boxes ☑ and ☐
+	TaskUnicode
+	// TaskNone leaves the markers out, keeping the text of the items
+	TaskNone
+)
+
+// taskStyleNames maps task styles to their CLI names
+var taskStyleNames = map[TaskStyle]string{
+	TaskAuto:      "auto",
+	TaskEmoticons: "emoticons",
+	TaskText:      "text",
+	TaskUnicode:   "unicode",
+	TaskNone:      "none",
+}
+
+// String returns the CLI name of the task style
+func (s TaskStyle) String() string {
+	if name, ok := taskStyleNames[s]; ok {
+		return name
+	}
+	return fmt.Sprintf("TaskStyle(%d)", int(s))
+}
+
+// ParseTaskStyle parses a task style name (auto, emoticons, text, unicode or
+// none)
+func ParseTaskStyle(name string) (TaskStyle, error) {
+	for style, styleName := range taskStyleNames {
+		if strings.EqualFold(name, styleName) {
+			return style, nil
+		}
+	}
+	return TaskAuto, fmt.Errorf("unknown task style %q (want auto, emoticons, text, unicode or none)", name)
+}
+
+// taskMarker returns the checkbox of the text and Unicode task styles and
+// none, followed by a space unless it is empty, and false for the others
+func (o Options) taskMarker(checked bool) (string, bool) {
+	switch o.TaskStyle {
+	case TaskText:
+		if checked {
+			return "[x] ", true
+		}
+		return "[ ] ", true
+	case TaskUnicode:
+		if checked {
+			return "☑ ", true
+		}
+		return "☐ ", true
+	case TaskNone:
+		return "", true
+	}
+	return "", false
+}
+
+// adfTasks reports whether ADF task lists are rendered as taskList nodes,
+// which the text, unicode and none task styles opt out of
+func (o Options) adfTasks() bool {
+	return o.TaskStyle == TaskAuto || o.TaskStyle == TaskEmoticons
+}
+
+// isTaskList reports whether a list can be rendered as an ADF taskList: a
+// bullet list whose items each hold a checkbox paragraph, followed by nothing
+// but nested task lists. Items of other lists cannot hold a taskList.
+func isTaskList(n *ast.List) bool {
+	if _, nested := n.Parent().(*ast.ListItem); nested {
+		return false
+	}
+	return isTaskItems(n)
+}
+
+// isTaskItems reports whether the items of a list are all task items
+func isTaskItems(n *ast.List) bool {
+	if n.IsOrdered() || n.FirstChild() == nil {
+		return false
+	}
+	for item := n.FirstChild(); item != nil; item = item.NextSibling() {
+		text := item.FirstChild()
+		if text == nil || !isTextBlock(text) {
+			return false
+		}
+		if _, ok := text.FirstChild().(*east.TaskCheckBox); !ok {
+			return false
+		}
+		for child := text.NextSibling(); child != nil; child = child.NextSibling() {
+			if list, ok := child.(*ast.List); !ok || !isTaskItems(list) {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// renderTaskList renders a task list as an ADF taskList, whose items Jira
+// Cloud shows as checkboxes that can be ticked. Nested task lists follow the
+// item they belong to in the taskList, as ADF nests them.
+func (r *ADFRenderer) renderTaskList(n *ast.List) *ADFNode {
+	list := &ADFNode{Type: "taskList", Attrs: map[string]any{"localId": r.taskID()}}
+	for item := n.FirstChild(); item != nil; item = item.NextSibling() {
+		text := item.FirstChild()
+		state := "TODO"
+		if text.FirstChild().(*east.TaskCheckBox).IsChecked {
+			state = "DONE"
+		}
+		r.inTaskItem = true
+		content := r.renderInlines(text, nil)
+		r.inTaskItem = false
+		list.Content = append(list.Content, &ADFNode{
+			Type:    "taskItem",
+			Attrs:   map[string]any{"localId": r.taskID(), "state": state},
+			Content: content,
+		})
+		for child := text.NextSibling(); child != nil; child = child.NextSibling() {
+			list.Content = append(list.Content, r.renderTaskList(child.(*ast.List)))
+		}
+	}
+	return list
+}
+
+// taskID returns the next localId of the task lists and items of the
+// document; they only need to be unique within it
+func (r *ADFRenderer) taskID() string {
+	r.tasks++
+	return "task-" + strconv.Itoa(r.tasks)
+}
@@ -0,0 +1,106 @@
+package converter
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestTaskStyles(t *testing.T) {
+	const markdown = "- [x] done\n- [ ] todo"
+	tests := []struct {
+		name string
+		opts Options
+		want string
+	}{
+		{"auto", Options{}, "* (/) done\n* ( ) todo"},
+		{"auto with text symbols", Options{Symbols: SymbolsText}, "* \\[done] done\n* \\[todo] todo"},
+		{"auto on Cloud", Options{Dialect: DialectCloud}, "* ☑ done\n* ☐ todo"},
+		{"emoticons with text symbols", Options{TaskStyle: TaskEmoticons, Symbols: SymbolsText}, "* (/) done\n* ( ) todo"},
+		{"text", Options{TaskStyle: TaskText}, "* \\[x] done\n* \\[ ] todo"},
+		{"unicode", Options{TaskStyle: TaskUnicode}, "* ☑ done\n* ☐ todo"},
+		{"none", Options{TaskStyle: TaskNone}, "* done\n* todo"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := render(t, markdown, tt.opts); got != tt.want {
+				t.Errorf("got %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseTaskStyle(t *testing.T) {
+	for _, style := range []TaskStyle{TaskAuto, TaskEmoticons, TaskText, TaskUnicode, TaskNone} {
+		if got, err := ParseTaskStyle(style.String()); err != nil || got != style {
+			t.Errorf("ParseTaskStyle(%q) = %v, %v", style.String(), got, err)
+		}
+	}
+	if _, err := ParseTaskStyle("boxes"); err == nil {
+		t.Error("ParseTaskStyle(boxes) succeeded")
+	}
+}
+
+func TestADFTaskLists(t *testing.T) {
+	tests := []struct {
+		name     string
+		markdown string
+		opts     Options
+		want     []string
+		wantNot  []string
+	}{
+		{
+			name:     "task list",
+			markdown: "- [x] done\n- [ ] todo",
+			want: []string{
+				`"type":"taskList","attrs":{"localId":"task-1"}`,
+				`"type":"taskItem","attrs":{"localId":"task-2","state":"DONE"}`,
+				`"type":"taskItem","attrs":{"localId":"task-3","state":"TODO"}`,
+			},
+			wantNot: []string{`"bulletList"`, `[x]`},
+		},
+		{
+			name:     "nested task list",
+			markdown: "- [ ] todo\n  - [x] sub",
+			want:     []string{`"type":"taskList","attrs":{"localId":"task-3"}`, `"text":"sub"`},
+			wantNot:  []string{`"listItem"`},
+		},
+		{
+			name:     "mixed list",
+			markdown: "- [x] done\n- plain",
+			want:     []string{`"bulletList"`},
+			wantNot:  []string{`"taskList"`},
+		},
+		{
+			name:     "text task style",
+			markdown: "- [x] done",
+			opts:     Options{TaskStyle: TaskText},
+			want:     []string{`"bulletList"`, `"text":"[x] done"`},
+			wantNot:  []string{`"taskList"`},
+		},
+		{
+			name:     "before Compat11",
+			markdown: "- [x] done",
+			opts:     Options{CompatLevel: Compat10},
+			want:     []string{`"bulletList"`},
+			wantNot:  []string{`"taskList"`},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := ConvertToADFWithOptions(tt.markdown, tt.opts)
+			if err != nil {
+				t.Fatal(err)
+			}
+			for _, want := range tt.want {
+				if !strings.Contains(result.Output, want) {
+					t.Errorf("output lacks %s:\n%s", want, result.Output)
+				}
+			}
+			for _, not := range tt.wantNot {
+				if strings.Contains(result.Output, not) {
+					t.Errorf("output has %s:\n%s", not, result.Output)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,47 @@
+// Adjacent text
+// goldmark ends a text node after every * and _ run that does not delimit
+// emphasis, so snake_case_name arrives as snake_, case_ and name. Escaping
+// needs the whole run of text to tell an intra-word underscore from a marker.
+
+package converter
+
+import (
+	"github.com/yuin/goldmark/ast"
+	"github.com/yuin/goldmark/parser"
+	"github.com/yuin/goldmark/text"
+)
+
+// textMerger joins text nodes that continue each other in the source
+type textMerger struct{}
+
+// Transform implements parser.ASTTransformer
+func (t *textMerger) Transform(doc *ast.Document, reader text.Reader, pc parser.Context) {
+	_ = ast.Walk(doc, func(n ast.Node, entering bool) (ast.WalkStatus, error) {
+		if !entering {
+			return ast.WalkContinue, nil
+		}
+		first, ok := n.(*ast.Text)
+		if !ok {
+			return ast.WalkContinue, nil
+		}
+		for {
+			next, ok := first.NextSibling().(*ast.Text)
+			if !ok || !mergeable(first, next) {
+				break
+			}
+			first.Segment = first.Segment.WithStop(next.Segment.Stop)
+			first.SetSoftLineBreak(next.SoftLineBreak())
+			first.SetHardLineBreak(next.HardLineBreak())
+			first.Parent().RemoveChild(first.Parent(), next)
+		}
+		return ast.WalkSkipChildren, nil
+	})
+}
+
+// mergeable reports whether next continues first on the same line
+func mergeable(first, next *ast.Text) bool {
+	return !first.SoftLineBreak() && !first.HardLineBreak() &&
+		!first.IsRaw() && !next.IsRaw() &&
+		first.Segment.Stop == next.Segment.Start &&
+		first.Segment.Padding == 0 && next.Segment.Padding == 0
+}
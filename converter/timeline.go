@@ -0,0 +1,124 @@
+// Incident timeline tables
+// Converts "- 12:03 UTC — detected" lists into a time | event table
+
+package converter
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/yuin/goldmark/ast"
+)
+
+// timelineRe matches a timeline entry: an optional date, a time, an optional
+// zone and a dash separating the event
+var timelineRe = regexp.MustCompile(`^(?:(\d{4}-\d{2}-\d{2})[ T])?(\d{1,2}:\d{2}(?::\d{2})?)(?:\s*([A-Z]{1,5}|[+-]\d{2}:?\d{2}))?\s+\\?[-–—]\s+`)
+
+// timelineZones are the UTC offsets of common zone abbreviations, in minutes
+var timelineZones = map[string]int{
+	"Z": 0, "UTC": 0, "GMT": 0,
+	"EST": -5 * 60, "EDT": -4 * 60, "CST": -6 * 60, "CDT": -5 * 60,
+	"MST": -7 * 60, "MDT": -6 * 60, "PST": -8 * 60, "PDT": -7 * 60,
+	"BST": 60, "CET": 60, "CEST": 2 * 60, "EET": 2 * 60, "EEST": 3 * 60,
+	"IST": 5*60 + 30, "JST": 9 * 60, "AEST": 10 * 60, "AEDT": 11 * 60,
+}
+
+// isTimeline reports whether a list is an incident timeline: a top-level bullet
+// list whose items are all single "time — event" lines
+func (r *JIRARenderer) isTimeline(n ast.Node) bool {
+	list, ok := n.(*ast.List)
+	if !ok || !r.options.TimelineTables || list.IsOrdered() {
+		return false
+	}
+	if _, top := list.Parent().(*ast.Document); !top || !list.HasChildren() {
+		return false
+	}
+	for item := list.FirstChild(); item != nil; item = item.NextSibling() {
+		block := item.FirstChild()
+		if block == nil || block.NextSibling() != nil {
+			return false
+		}
+		if !timelineRe.MatchString(plainText(r.source, block)) {
+			return false
+		}
+	}
+	return true
+}
+
+// renderTimeline renders a timeline list as a two-column table
+func (r *JIRARenderer) renderTimeline(buf *strings.Builder, list *ast.List) {
+	buf.WriteString("||Time||Event||\n")
+	zone := ""
+	for item := list.FirstChild(); item != nil; item = item.NextSibling() {
+		var cell strings.Builder
+		r.inTimeline = true
+		r.renderChildren(&cell, item.FirstChild())
+		r.inTimeline = false
+
+		text := strings.TrimSpace(strings.ReplaceAll(cell.String(), "\n", " "))
+		m := timelineRe.FindStringSubmatch(text)
+		if m == nil {
+			// The rendered line lost its time prefix (e.g. to escaping); keep it whole
+			fmt.Fprintf(buf, "| |%s|\n", text)
+			continue
+		}
+		if m[3] != "" {
+			zone = m[3]
+		}
+		fmt.Fprintf(buf, "|%s|%s|\n", r.timelineTime(m[1], m[2], m[3], zone), text[len(m[0]):])
+	}
+	buf.WriteString("\n")
+}
+
+// timelineTime formats an entry's time as written, or converted to
+// Options.TimelineZone when the zone in effect for the entry is known
+func (r *JIRARenderer) timelineTime(date, clock, entryZone, zone string) string {
+	written := strings.Join(strings.Fields(strings.Join([]string{date, clock, entryZone}, " ")), " ")
+	loc := r.options.TimelineZone
+	offset, ok := zoneOffset(zone)
+	if loc == nil || !ok {
+		return written
+	}
+
+	layout := "15:04"
+	if strings.Count(clock, ":") == 2 {
+		layout = "15:04:05"
+	}
+	day := "2000-01-01"
+	if date != "" {
+		day = date
+	}
+	t, err := time.ParseInLocation("2006-01-02 "+layout, day+" "+clock, time.FixedZone(zone, offset*60))
+	if err != nil {
+		return written
+	}
+	if date != "" {
+		layout = "2006-01-02 " + layout
+	}
+	return t.In(loc).Format(layout + " MST")
+}
+
+// zoneOffset returns the UTC offset in minutes of a zone abbreviation or
+// numeric offset such as +02:00
+func zoneOffset(zone string) (int, bool) {
+	if offset, ok := timelineZones[zone]; ok {
+		return offset, true
+	}
+	digits := strings.ReplaceAll(zone, ":", "")
+	if len(digits) != 5 || (digits[0] != '+' && digits[0] != '-') {
+		return 0, false
+	}
+	hours, err1 := strconv.Atoi(digits[1:3])
+	minutes, err2 := strconv.Atoi(digits[3:])
+	if err1 != nil || err2 != nil {
+		return 0, false
+	}
+	offset := hours*60 + minutes
+	if digits[0] == '-' {
+		offset = -offset
+	}
+	return offset, true
+}
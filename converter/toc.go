@@ -0,0 +1,126 @@
+// Table of contents
+// Replaces [TOC], [[toc]] and <!-- toc --> markers with a {toc} macro; the
+// list markdown-toc generates between <!-- toc --> and <!-- tocstop --> is
+// replaced along with the marker
+
+package converter
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/yuin/goldmark/ast"
+	"github.com/yuin/goldmark/parser"
+	"github.com/yuin/goldmark/text"
+)
+
+// kindTOC is the node kind of table of contents markers
+var kindTOC = ast.NewNodeKind("TOC")
+
+// tocMarker is where the table of contents of the document goes
+type tocMarker struct {
+	ast.BaseBlock
+}
+
+// Kind implements ast.Node
+func (n *tocMarker) Kind() ast.NodeKind {
+	return kindTOC
+}
+
+// Dump implements ast.Node
+func (n *tocMarker) Dump(source []byte, level int) {
+	ast.DumpHelper(n, source, level, nil, nil)
+}
+
+// tocParagraphRe matches a paragraph holding only a [TOC] or [[toc]] marker
+var tocParagraphRe = regexp.MustCompile(`(?i)^\s*(?:\[toc\]|\[\[toc\]\])\s*$`)
+
+// tocCommentRe and tocStopRe match the comments markdown-toc puts around the
+// list it generates
+var (
+	tocCommentRe = regexp.MustCompile(`(?i)^\s*<!--\s*toc\s*-->\s*$`)
+	tocStopRe    = regexp.MustCompile(`(?i)^\s*<!--\s*tocstop\s*-->\s*$`)
+)
+
+// tocTransformer replaces table of contents markers with tocMarker nodes;
+// with prepend, a document without markers gets one at its start
+type tocTransformer struct {
+	markers bool
+	prepend bool
+}
+
+// Transform implements parser.ASTTransformer
+func (t *tocTransformer) Transform(doc *ast.Document, reader text.Reader, pc parser.Context) {
+	source := reader.Source()
+	var markers []ast.Node
+	if t.markers {
+		ast.Walk(doc, func(n ast.Node, entering bool) (ast.WalkStatus, error) {
+			if entering && isTOCMarker(n, source) {
+				markers = append(markers, n)
+			}
+			return ast.WalkContinue, nil
+		})
+	}
+	for _, marker := range markers {
+		replaceTOCMarker(marker, source)
+	}
+	if len(markers) == 0 && t.prepend {
+		doc.InsertBefore(doc, doc.FirstChild(), &tocMarker{})
+	}
+}
+
+// isTOCMarker reports whether a block is a table of contents marker
+func isTOCMarker(n ast.Node, source []byte) bool {
+	switch n := n.(type) {
+	case *ast.Paragraph:
+		if n.Lines().Len() != 1 {
+			return false
+		}
+		line := n.Lines().At(0)
+		return tocParagraphRe.Match(line.Value(source))
+	case *ast.HTMLBlock:
+		return tocCommentRe.MatchString(htmlBlockText(n, source))
+	}
+	return false
+}
+
+// replaceTOCMarker replaces a marker, and the list up to a <!-- tocstop -->
+// comment following it, with a tocMarker
+func replaceTOCMarker(marker ast.Node, source []byte) {
+	parent := marker.Parent()
+	if _, ok := marker.(*ast.HTMLBlock); ok {
+		for sibling := marker.NextSibling(); sibling != nil; sibling = sibling.NextSibling() {
+			block, ok := sibling.(*ast.HTMLBlock)
+			if !ok || !tocStopRe.MatchString(htmlBlockText(block, source)) {
+				continue
+			}
+			for s := marker.NextSibling(); s != block; s = marker.NextSibling() {
+				parent.RemoveChild(parent, s)
+			}
+			parent.RemoveChild(parent, block)
+			break
+		}
+	}
+	node := &tocMarker{}
+	// The lines of the marker position warnings
+	node.SetLines(marker.Lines())
+	node.SetBlankPreviousLines(marker.HasBlankPreviousLines())
+	parent.ReplaceChild(parent, marker, node)
+}
+
+// tocMacro returns the {toc} macro, limited to the Options.TOCMinLevel to
+// Options.TOCMaxLevel headings
+func (o Options) tocMacro() string {
+	var params []string
+	if o.TOCMinLevel > 0 {
+		params = append(params, fmt.Sprintf("minLevel=%d", o.TOCMinLevel))
+	}
+	if o.TOCMaxLevel > 0 {
+		params = append(params, fmt.Sprintf("maxLevel=%d", o.TOCMaxLevel))
+	}
+	if len(params) == 0 {
+		return "{toc}"
+	}
+	return "{toc:" + strings.Join(params, "|") + "}"
+}
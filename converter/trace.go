@@ -0,0 +1,59 @@
+// Stack trace detection
+// Recognizes stack traces and compiler output pasted as plain paragraphs
+
+package converter
+
+import (
+	"regexp"
+	"strings"
+
+	"github.com/yuin/goldmark/ast"
+)
+
+// traceHeaderRe matches the first line of a stack trace
+var traceHeaderRe = regexp.MustCompile(`^(?:Traceback \(most recent call last\):|goroutine \d+ \[|panic: |Exception in thread |(?:[\w$]+\.)+[\w$]*(?:Exception|Error)(?::|$)|Caused by: )`)
+
+// traceLineRe matches a frame of a stack trace or a compiler diagnostic
+var traceLineRe = regexp.MustCompile(`^(?:at [\w$.<>/]+ ?\(.*\)|at .+:\d+(?::\d+)?\)?|File ".+", line \d+|\S+\.go:\d+(?: \+0x[0-9a-f]+)?|[\w.$/]+\(.*\)$|Caused by: .+|\.\.\. \d+ more|[\w./\\-]+:\d+(?::\d+)?: (?:fatal error|error|warning|note)\b.*|error(?:\[E\d+\])?: .+|--> .+:\d+:\d+)$`)
+
+// traceText returns the source of a paragraph that looks like a stack trace or
+// compiler output: a trace header followed by frames, or mostly frame and
+// diagnostic lines
+func traceText(source []byte, n ast.Node) (string, bool) {
+	lines := n.Lines()
+	if lines.Len() < 2 {
+		return "", false
+	}
+	frames := 0
+	var first string
+	for i := 0; i < lines.Len(); i++ {
+		segment := lines.At(i)
+		line := strings.TrimSpace(string(segment.Value(source)))
+		if i == 0 {
+			first = line
+		}
+		if traceLineRe.MatchString(line) {
+			frames++
+		}
+	}
+	if !(traceHeaderRe.MatchString(first) && frames > 0) && frames*2 < lines.Len()+1 {
+		return "", false
+	}
+	// Keep the indentation of continuation lines, which paragraph lines drop
+	text := string(source[lines.At(0).Start:lines.At(lines.Len()-1).Stop])
+	return strings.TrimRight(text, "\n") + "\n", true
+}
+
+// isTrace reports whether a paragraph is rendered as a detected stack trace
+func (r *JIRARenderer) isTrace(n ast.Node) bool {
+	if !r.options.DetectTraces {
+		return false
+	}
+	_, ok := traceText(r.source, n)
+	return ok
+}
+
+// renderTrace renders a detected stack trace paragraph as {noformat}
+func (r *JIRARenderer) renderTrace(buf *strings.Builder, text string) {
+	buf.WriteString("{noformat}\n" + r.options.macroBody("noformat", text) + "{noformat}\n\n")
+}
@@ -0,0 +1,189 @@
+// Conversion warnings
+// Warnings carry the source position of the construct that triggered them
+
+package converter
+
+import (
+	"fmt"
+	"unicode/utf8"
+
+	"github.com/yuin/goldmark/ast"
+)
+
+// WarningCode identifies a class of warning; codes are stable across releases
+type WarningCode string
+
+const (
+	// WarnHTMLBlock reports a raw HTML block converted with best effort
+	WarnHTMLBlock WarningCode = "W001_HTML_BLOCK"
+	// WarnMediaNoSource reports a media embed dropped because it has no URL
+	WarnMediaNoSource WarningCode = "W002_MEDIA_NO_SOURCE"
+	// WarnMediaLink reports a media embed degraded to a plain link
+	WarnMediaLink WarningCode = "W003_MEDIA_LINK"
+	// WarnBrokenLink reports a link whose target could not be reached
+	WarnBrokenLink WarningCode = "W004_BROKEN_LINK"
+	// WarnMisspelling reports a word missing from the spellcheck dictionaries
+	WarnMisspelling WarningCode = "W005_MISSPELLING"
+	// WarnRendererFailed reports a registered node renderer that returned an error
+	WarnRendererFailed WarningCode = "W006_RENDERER_FAILED"
+	// WarnLegacyStyle reports legacy HTML styling that JIRA cannot express
+	WarnLegacyStyle WarningCode = "W007_LEGACY_STYLE"
+	// WarnHTMLSanitized reports raw HTML changed by the HTML sanitizer
+	WarnHTMLSanitized WarningCode = "W008_HTML_SANITIZED"
+	// WarnCodeLanguage reports a code language the dialect cannot highlight
+	WarnCodeLanguage WarningCode = "W009_CODE_LANGUAGE"
+	// WarnRoadmapTemplate reports a roadmap template that failed to execute
+	WarnRoadmapTemplate WarningCode = "W010_ROADMAP_TEMPLATE"
+	// WarnDiagram reports a diagram fence left as code because it could not be rendered
+	WarnDiagram WarningCode = "W011_DIAGRAM"
+	// WarnMath reports math rendered as code because there is no math macro
+	WarnMath WarningCode = "W012_MATH"
+	// WarnLargeTable reports a table with too many columns or rows to read comfortably
+	WarnLargeTable WarningCode = "W013_LARGE_TABLE"
+	// WarnAltText reports an image left without alt text because generating one failed
+	WarnAltText WarningCode = "W014_ALT_TEXT"
+	// WarnAttachment reports a local image that could not be attached
+	WarnAttachment WarningCode = "W015_ATTACHMENT"
+	// WarnMention reports an @handle that could not be converted into a mention
+	WarnMention WarningCode = "W016_MENTION"
+	// WarnTOC reports a table of contents dropped from ADF output
+	WarnTOC WarningCode = "W017_TOC"
+	// WarnHeadingLevel reports a heading deeper than h6. rendered as h6.
+	WarnHeadingLevel WarningCode = "W018_HEADING_LEVEL"
+	// WarnTableAlignment reports column alignment dropped from a JIRA table
+	WarnTableAlignment WarningCode = "W019_TABLE_ALIGNMENT"
+)
+
+// Severity ranks how much a warning affects the converted output
+type Severity int
+
+const (
+	// SeverityInfo marks output that is converted but may read differently
+	SeverityInfo Severity = iota
+	// SeverityWarning marks content that was degraded or partially lost
+	SeverityWarning
+	// SeverityError marks content that is missing or broken in the output
+	SeverityError
+)
+
+// severityNames maps severities to their display names
+var severityNames = map[Severity]string{
+	SeverityInfo:    "info",
+	SeverityWarning: "warning",
+	SeverityError:   "error",
+}
+
+// String returns the display name of the severity
+func (s Severity) String() string {
+	if name, ok := severityNames[s]; ok {
+		return name
+	}
+	return fmt.Sprintf("Severity(%d)", int(s))
+}
+
+// MarshalText encodes the severity as its display name
+func (s Severity) MarshalText() ([]byte, error) {
+	return []byte(s.String()), nil
+}
+
+// codeSeverities are the severities assigned to each warning code
+var codeSeverities = map[WarningCode]Severity{
+	WarnHTMLBlock:       SeverityWarning,
+	WarnMediaNoSource:   SeverityWarning,
+	WarnMediaLink:       SeverityInfo,
+	WarnBrokenLink:      SeverityError,
+	WarnMisspelling:     SeverityInfo,
+	WarnRendererFailed:  SeverityError,
+	WarnLegacyStyle:     SeverityInfo,
+	WarnHTMLSanitized:   SeverityWarning,
+	WarnDiagram:         SeverityWarning,
+	WarnMath:            SeverityWarning,
+	WarnLargeTable:      SeverityWarning,
+	WarnAltText:         SeverityInfo,
+	WarnAttachment:      SeverityError,
+	WarnMention:         SeverityWarning,
+	WarnTOC:             SeverityInfo,
+	WarnHeadingLevel:    SeverityWarning,
+	WarnTableAlignment:  SeverityInfo,
+	WarnCodeLanguage:    SeverityInfo,
+	WarnRoadmapTemplate: SeverityError,
+}
+
+// Warning describes a construct that did not convert cleanly
+type Warning struct {
+	Code     WarningCode `json:"code"`
+	Severity Severity    `json:"severity"`
+	// Line is the 1-based source line (0 if unknown)
+	Line int `json:"line"`
+	// Column is the 1-based source column in characters (0 if unknown)
+	Column  int    `json:"column"`
+	Message string `json:"message"`
+	// Key identifies the construct in Directives, for warnings that offer Choices
+	Key string `json:"key,omitempty"`
+	// Choices are the ways the construct can be rendered; the first is the default
+	Choices []string `json:"choices,omitempty"`
+}
+
+// String formats the warning as "line:column: severity code: message"
+func (w Warning) String() string {
+	if w.Line == 0 {
+		return fmt.Sprintf("%s %s: %s", w.Severity, w.Code, w.Message)
+	}
+	return fmt.Sprintf("%d:%d: %s %s: %s", w.Line, w.Column, w.Severity, w.Code, w.Message)
+}
+
+// newWarning creates a warning positioned at a node
+func newWarning(source []byte, node ast.Node, code WarningCode, msg string) Warning {
+	line, column := nodePosition(source, node)
+	return Warning{Code: code, Severity: codeSeverities[code], Line: line, Column: column, Message: msg}
+}
+
+// nodePosition returns the 1-based line and column where a node starts
+func nodePosition(source []byte, node ast.Node) (int, int) {
+	for n := node; n != nil; n = n.Parent() {
+		if offset := nodeOffset(n); offset >= 0 {
+			return offsetPosition(source, offset)
+		}
+	}
+	return 0, 0
+}
+
+// nodeOffset returns the source offset of a node, or -1 if it has none
+func nodeOffset(node ast.Node) int {
+	switch n := node.(type) {
+	case *ast.Text:
+		return n.Segment.Start
+	case *mathInline:
+		return n.Segment.Start
+	case *ast.RawHTML:
+		if n.Segments.Len() > 0 {
+			return n.Segments.At(0).Start
+		}
+	}
+	if node.Type() == ast.TypeBlock {
+		if lines := node.Lines(); lines != nil && lines.Len() > 0 {
+			return lines.At(0).Start
+		}
+	}
+	for child := node.FirstChild(); child != nil; child = child.NextSibling() {
+		if offset := nodeOffset(child); offset >= 0 {
+			return offset
+		}
+	}
+	return -1
+}
+
+// offsetPosition converts a byte offset into a 1-based line and column
+func offsetPosition(source []byte, offset int) (int, int) {
+	if offset > len(source) {
+		offset = len(source)
+	}
+	line, lineStart := 1, 0
+	for i := 0; i < offset; i++ {
+		if source[i] == '\n' {
+			line++
+			lineStart = i + 1
+		}
+	}
+	return line, utf8.RuneCount(source[lineStart:offset]) + 1
+}
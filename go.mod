@@ -2,4 +2,11 @@ module github.com/astsu-dev/md2jira
 
 go 1.25.1
 
-require github.com/yuin/goldmark v1.7.16
+require (
+	github.com/BurntSushi/toml v1.6.0
+	github.com/fsnotify/fsnotify v1.10.1
+	github.com/yuin/goldmark v1.7.16
+	gopkg.in/yaml.v3 v3.0.1
+)
+
+require golang.org/x/sys v0.13.0 // indirect
@@ -0,0 +1,320 @@
+// Package jira is a minimal JIRA REST API client for publishing converted
+// descriptions: issues, comments, attachments, issue links, fix versions and
+// JQL search (REST API v2, wiki markup).
+package jira
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+)
+
+// Client talks to a JIRA instance
+type Client struct {
+	// BaseURL is the site URL, e.g. https://example.atlassian.net
+	BaseURL string
+	// User is the account email (Jira Cloud) or user name; when empty, Token
+	// is sent as a bearer personal access token (Jira Server/Data Center)
+	User  string
+	Token string
+	// HTTPClient performs requests (default: a client with a 30s timeout)
+	HTTPClient *http.Client
+}
+
+// NewClient creates a client for baseURL
+func NewClient(baseURL, user, token string) *Client {
+	return &Client{
+		BaseURL:    strings.TrimRight(baseURL, "/"),
+		User:       user,
+		Token:      token,
+		HTTPClient: &http.Client{Timeout: 30 * time.Second},
+	}
+}
+
+// NewClientFromEnv creates a client from JIRA_URL, JIRA_USER and JIRA_TOKEN
+func NewClientFromEnv() (*Client, error) {
+	baseURL := os.Getenv("JIRA_URL")
+	token := os.Getenv("JIRA_TOKEN")
+	if baseURL == "" || token == "" {
+		return nil, fmt.Errorf("JIRA_URL and JIRA_TOKEN must be set")
+	}
+	return NewClient(baseURL, os.Getenv("JIRA_USER"), token), nil
+}
+
+// Error is a non-2xx response from the REST API
+type Error struct {
+	StatusCode int
+	// Messages are the errorMessages and field errors reported by JIRA
+	Messages []string
+}
+
+// Error formats the status and messages
+func (e *Error) Error() string {
+	if len(e.Messages) == 0 {
+		return fmt.Sprintf("jira: %d %s", e.StatusCode, http.StatusText(e.StatusCode))
+	}
+	return fmt.Sprintf("jira: %d %s: %s", e.StatusCode, http.StatusText(e.StatusCode), strings.Join(e.Messages, "; "))
+}
+
+// do sends a JSON request to path and decodes the JSON response into out (if not nil)
+func (c *Client) do(method, path string, body, out interface{}) error {
+	var reader io.Reader
+	if body != nil {
+		data, err := json.Marshal(body)
+		if err != nil {
+			return err
+		}
+		reader = bytes.NewReader(data)
+	}
+	req, err := http.NewRequest(method, c.BaseURL+path, reader)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Accept", "application/json")
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	return c.send(req, out)
+}
+
+// send authorizes and sends a request and decodes the JSON response into out (if not nil)
+func (c *Client) send(req *http.Request, out interface{}) error {
+	c.authorize(req)
+	resp, err := c.httpClient().Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return responseError(resp)
+	}
+	if out == nil || resp.StatusCode == http.StatusNoContent {
+		return nil
+	}
+	return json.NewDecoder(resp.Body).Decode(out)
+}
+
+// authorize adds basic or bearer authentication to a request
+func (c *Client) authorize(req *http.Request) {
+	if c.User != "" {
+		req.SetBasicAuth(c.User, c.Token)
+	} else if c.Token != "" {
+		req.Header.Set("Authorization", "Bearer "+c.Token)
+	}
+}
+
+// httpClient returns the configured HTTP client or the default one
+func (c *Client) httpClient() *http.Client {
+	if c.HTTPClient != nil {
+		return c.HTTPClient
+	}
+	return http.DefaultClient
+}
+
+// responseError builds an Error from a failed response
+func responseError(resp *http.Response) error {
+	apiErr := &Error{StatusCode: resp.StatusCode}
+	var body struct {
+		ErrorMessages []string          `json:"errorMessages"`
+		Errors        map[string]string `json:"errors"`
+	}
+	if json.NewDecoder(io.LimitReader(resp.Body, 1<<20)).Decode(&body) == nil {
+		apiErr.Messages = body.ErrorMessages
+		for field, msg := range body.Errors {
+			apiErr.Messages = append(apiErr.Messages, field+": "+msg)
+		}
+	}
+	return apiErr
+}
+
+// Issue is the subset of an issue used by md2jira
+type Issue struct {
+	ID     string `json:"id"`
+	Key    string `json:"key"`
+	Fields struct {
+		Summary     string `json:"summary"`
+		Description string `json:"description"`
+	} `json:"fields"`
+}
+
+// GetIssue fetches an issue's summary and description
+func (c *Client) GetIssue(key string) (*Issue, error) {
+	var issue Issue
+	err := c.do(http.MethodGet, "/rest/api/2/issue/"+url.PathEscape(key)+"?fields=summary,description", nil, &issue)
+	if err != nil {
+		return nil, err
+	}
+	return &issue, nil
+}
+
+// CreateIssue creates an issue and returns its key
+func (c *Client) CreateIssue(project, issueType, summary, description string) (string, error) {
+	return c.CreateIssueFields(map[string]interface{}{
+		"project":     map[string]string{"key": project},
+		"issuetype":   map[string]string{"name": issueType},
+		"summary":     summary,
+		"description": description,
+	})
+}
+
+// CreateIssueFields creates an issue from raw REST API fields and returns its key
+func (c *Client) CreateIssueFields(fields map[string]interface{}) (string, error) {
+	body := map[string]interface{}{"fields": fields}
+	var created struct {
+		Key string `json:"key"`
+	}
+	if err := c.do(http.MethodPost, "/rest/api/2/issue", body, &created); err != nil {
+		return "", err
+	}
+	return created.Key, nil
+}
+
+// UserField references a user in an issue field: "accountid:..." values
+// (Jira Cloud) by account ID, others by user name
+func UserField(user string) map[string]string {
+	if id, ok := strings.CutPrefix(user, "accountid:"); ok {
+		return map[string]string{"accountId": id}
+	}
+	return map[string]string{"name": user}
+}
+
+// LinkIssues links two issues with a link type such as "Relates" or "Blocks"
+func (c *Client) LinkIssues(linkType, inward, outward string) error {
+	body := map[string]interface{}{
+		"type":         map[string]string{"name": linkType},
+		"inwardIssue":  map[string]string{"key": inward},
+		"outwardIssue": map[string]string{"key": outward},
+	}
+	return c.do(http.MethodPost, "/rest/api/2/issueLink", body, nil)
+}
+
+// UpdateDescription replaces an issue's description
+func (c *Client) UpdateDescription(key, description string) error {
+	body := map[string]interface{}{
+		"fields": map[string]string{"description": description},
+	}
+	return c.do(http.MethodPut, "/rest/api/2/issue/"+url.PathEscape(key), body, nil)
+}
+
+// UpdateIssueFields sets raw REST API fields of an issue
+func (c *Client) UpdateIssueFields(key string, fields map[string]interface{}) error {
+	body := map[string]interface{}{"fields": fields}
+	return c.do(http.MethodPut, "/rest/api/2/issue/"+url.PathEscape(key), body, nil)
+}
+
+// AddComment adds a comment to an issue
+func (c *Client) AddComment(key, body string) error {
+	return c.do(http.MethodPost, "/rest/api/2/issue/"+url.PathEscape(key)+"/comment", map[string]string{"body": body}, nil)
+}
+
+// Attachment is a file attached to an issue
+type Attachment struct {
+	ID       string `json:"id"`
+	Filename string `json:"filename"`
+	Size     int64  `json:"size"`
+}
+
+// Attachments returns the files attached to an issue
+func (c *Client) Attachments(key string) ([]Attachment, error) {
+	var issue struct {
+		Fields struct {
+			Attachment []Attachment `json:"attachment"`
+		} `json:"fields"`
+	}
+	if err := c.do(http.MethodGet, "/rest/api/2/issue/"+url.PathEscape(key)+"?fields=attachment", nil, &issue); err != nil {
+		return nil, err
+	}
+	return issue.Fields.Attachment, nil
+}
+
+// AddAttachment uploads content to an issue as a file named name
+func (c *Client) AddAttachment(key, name string, content io.Reader) error {
+	var body bytes.Buffer
+	form := multipart.NewWriter(&body)
+	part, err := form.CreateFormFile("file", name)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(part, content); err != nil {
+		return err
+	}
+	if err := form.Close(); err != nil {
+		return err
+	}
+	req, err := http.NewRequest(http.MethodPost, c.BaseURL+"/rest/api/2/issue/"+url.PathEscape(key)+"/attachments", &body)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Accept", "application/json")
+	req.Header.Set("Content-Type", form.FormDataContentType())
+	// Without it JIRA rejects the upload as a cross-site request
+	req.Header.Set("X-Atlassian-Token", "no-check")
+	return c.send(req, nil)
+}
+
+// SearchIssues returns the issues matching a JQL query (at most max)
+func (c *Client) SearchIssues(jql string, max int) ([]Issue, error) {
+	body := map[string]interface{}{
+		"jql":        jql,
+		"maxResults": max,
+		"fields":     []string{"summary", "description"},
+	}
+	var result struct {
+		Issues []Issue `json:"issues"`
+	}
+	if err := c.do(http.MethodPost, "/rest/api/2/search", body, &result); err != nil {
+		return nil, err
+	}
+	return result.Issues, nil
+}
+
+// Version is a project fix version
+type Version struct {
+	ID          string `json:"id"`
+	Name        string `json:"name"`
+	Description string `json:"description"`
+	Released    bool   `json:"released"`
+}
+
+// FindVersion returns the named fix version of a project, or nil if it does not exist
+func (c *Client) FindVersion(project, name string) (*Version, error) {
+	var versions []Version
+	if err := c.do(http.MethodGet, "/rest/api/2/project/"+url.PathEscape(project)+"/versions", nil, &versions); err != nil {
+		return nil, err
+	}
+	for i := range versions {
+		if versions[i].Name == name {
+			return &versions[i], nil
+		}
+	}
+	return nil, nil
+}
+
+// CreateVersion creates an unreleased fix version of a project
+func (c *Client) CreateVersion(project, name, description string) (*Version, error) {
+	body := map[string]string{"project": project, "name": name, "description": description}
+	var version Version
+	if err := c.do(http.MethodPost, "/rest/api/2/version", body, &version); err != nil {
+		return nil, err
+	}
+	return &version, nil
+}
+
+// UpdateVersionDescription replaces a fix version's description
+func (c *Client) UpdateVersionDescription(id, description string) error {
+	body := map[string]string{"description": description}
+	return c.do(http.MethodPut, "/rest/api/2/version/"+url.PathEscape(id), body, nil)
+}
+
+// QuoteJQL quotes a string for use as a JQL value
+func QuoteJQL(s string) string {
+	return `"` + strings.NewReplacer(`\`, `\\`, `"`, `\"`).Replace(s) + `"`
+}
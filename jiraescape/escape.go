@@ -0,0 +1,505 @@
+// Package jiraescape escapes text for JIRA wiki markup with the rules md2jira
+// uses: only the characters that would trigger JIRA formatting in their
+// context are escaped, so the output stays readable.
+//
+//	desc := "||Step||Result||\n|" + jiraescape.CellContent(step) + "|" + jiraescape.CellContent(result) + "|"
+//	macro := "{panel:title=" + jiraescape.MacroParam(title) + "}"
+package jiraescape
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// Mode controls how aggressively JIRA markup characters are escaped
+type Mode int
+
+const (
+	// Minimal escapes only characters that would trigger JIRA formatting in context
+	Minimal Mode = iota
+	// Aggressive escapes every JIRA special character
+	Aggressive
+	// None leaves text untouched, for targets that do not interpret the markup
+	None
+)
+
+// modeNames maps escape modes to their CLI names
+var modeNames = map[Mode]string{
+	Minimal:    "minimal",
+	Aggressive: "aggressive",
+	None:       "none",
+}
+
+// String returns the CLI name of the escape mode
+func (m Mode) String() string {
+	if name, ok := modeNames[m]; ok {
+		return name
+	}
+	return fmt.Sprintf("EscapeMode(%d)", int(m))
+}
+
+// ParseMode parses an escape mode name (none, minimal or aggressive)
+func ParseMode(name string) (Mode, error) {
+	for mode, modeName := range modeNames {
+		if strings.EqualFold(name, modeName) {
+			return mode, nil
+		}
+	}
+	return Minimal, fmt.Errorf("unknown escape mode %q (want none, minimal or aggressive)", name)
+}
+
+// Style is how escaped characters are neutralized
+type Style int
+
+const (
+	// Backslash prefixes escaped characters with a backslash
+	Backslash Style = iota
+	// ZeroWidth follows escaped text effect markers and ?? with an invisible
+	// zero-width space (U+200B) instead
+	ZeroWidth
+	// EmptyGroup follows escaped text effect markers and ?? with an empty {}
+	// group instead
+	EmptyGroup
+)
+
+// styleNames maps escape styles to their CLI names
+var styleNames = map[Style]string{
+	Backslash:  "backslash",
+	ZeroWidth:  "zero-width",
+	EmptyGroup: "empty-group",
+}
+
+// String returns the CLI name of the escape style
+func (s Style) String() string {
+	if name, ok := styleNames[s]; ok {
+		return name
+	}
+	return fmt.Sprintf("EscapeStyle(%d)", int(s))
+}
+
+// ParseStyle parses an escape style name (backslash, zero-width or empty-group)
+func ParseStyle(name string) (Style, error) {
+	for style, styleName := range styleNames {
+		if strings.EqualFold(name, styleName) {
+			return style, nil
+		}
+	}
+	return Backslash, fmt.Errorf("unknown escape style %q (want backslash, zero-width or empty-group)", name)
+}
+
+// Context describes where escaped text ends up in the markup; contexts combine
+// with |
+type Context uint8
+
+const (
+	// TableCell marks text inside a table cell, where | splits the row
+	TableCell Context = 1 << iota
+	// LinkLabel marks text inside [label|url], where | and ] end the label
+	LinkLabel
+	// Code marks text inside {{monospace}}, where } ends the span and
+	// backslashes are literal in the Markdown source
+	Code
+	// LineStart marks text that starts a line of the markup, where *, # and
+	// - followed by a space start a list, h1. to h6. a heading, bq. a quote
+	// and | a table row. The lines after newlines in the text start lines
+	// too, in every context but a cell, a link label or code.
+	LineStart
+)
+
+// Rules are the escaping rules added after the first release of md2jira,
+// which converter.CompatLevel turns off to keep the output of a pinned level;
+// rules combine with |
+type Rules uint8
+
+const (
+	// IssueKeys spares the dashes of issue keys such as PROJ-123 in
+	// aggressive escaping, so JIRA still links them
+	IssueKeys Rules = 1 << iota
+	// LineStarts escapes the list, heading, quote and table markers that
+	// start a line (see LineStart)
+	LineStarts
+	// Underscores escapes the underscores JIRA would read as emphasis
+	// although they do not pair, outside code
+	Underscores
+
+	// AllRules are the rules Escape and Markdown apply
+	AllRules = IssueKeys | LineStarts | Underscores
+)
+
+// MetaChars are the characters that carry formatting meaning in JIRA markup
+const MetaChars = "*_-+^~{}[]|!#?\\"
+
+// effectChars are the paired text effect markers (*strong*, _emphasis_,
+// -deleted-, +inserted+, ^superscript^, ~subscript~)
+const effectChars = "*_-+^~"
+
+// Text escapes plain text for a JIRA paragraph
+func Text(s string) string {
+	return Escape(s, LineStart, Minimal)
+}
+
+// CellContent escapes plain text for a JIRA table cell
+func CellContent(s string) string {
+	return Escape(s, TableCell, Minimal)
+}
+
+// MacroParam makes plain text safe as a macro parameter value such as a
+// {panel:title=...}: the characters that end a parameter, |, { and }, cannot
+// be escaped there and are removed, and whitespace is collapsed
+func MacroParam(s string) string {
+	return strings.Join(strings.Fields(macroParamReplacer.Replace(s)), " ")
+}
+
+// macroParamReplacer removes characters that end a macro parameter
+var macroParamReplacer = strings.NewReplacer("|", " ", "{", "", "}", "")
+
+// Escape escapes JIRA markup characters in plain text for the given context
+func Escape(s string, ctx Context, mode Mode) string {
+	return EscapeWithStyle(s, ctx, mode, Backslash)
+}
+
+// EscapeWithStyle escapes plain text like Escape, neutralizing the escaped
+// characters in the given style
+func EscapeWithStyle(s string, ctx Context, mode Mode, style Style) string {
+	return EscapeWithRules(s, ctx, mode, style, AllRules)
+}
+
+// EscapeWithRules escapes plain text like EscapeWithStyle, applying only the
+// given rules of those added since the first release
+func EscapeWithRules(s string, ctx Context, mode Mode, style Style, rules Rules) string {
+	if s == "" || mode == None {
+		return s
+	}
+	runes := []rune(s)
+	return escape(runes, make([]bool, len(runes)), ctx, mode, style, rules)
+}
+
+// Markdown escapes text that may still contain Markdown backslash escapes, as
+// found in the source of Markdown text nodes. Escaped characters stay literal
+// in the output: \*not bold\* becomes \*not bold\*, and \[ becomes \[.
+func Markdown(s string, ctx Context, mode Mode) string {
+	return MarkdownWithStyle(s, ctx, mode, Backslash)
+}
+
+// MarkdownWithStyle escapes Markdown text like Markdown, neutralizing the
+// escaped characters in the given style
+func MarkdownWithStyle(s string, ctx Context, mode Mode, style Style) string {
+	return MarkdownWithRules(s, ctx, mode, style, AllRules)
+}
+
+// MarkdownWithRules escapes Markdown text like MarkdownWithStyle, applying
+// only the given rules of those added since the first release
+func MarkdownWithRules(s string, ctx Context, mode Mode, style Style, rules Rules) string {
+	if s == "" || mode == None {
+		return s
+	}
+	runes, literal := unescapeSource(s, ctx)
+	return escape(runes, literal, ctx, mode, style, rules)
+}
+
+// escape escapes runes for the given context; literal marks runes escaped in
+// the source, which always stay literal
+func escape(runes []rune, literal []bool, ctx Context, mode Mode, style Style, rules Rules) string {
+	escaped := make([]bool, len(runes))
+	keys := issueKeyMarkers(runes)
+
+	if mode == Aggressive {
+		for i, c := range runes {
+			// PROJ\-123 would no longer be linked to the issue
+			escaped[i] = c != '\\' && strings.ContainsRune(MetaChars, c) && !(keys[i] && rules&IssueKeys != 0)
+		}
+		if rules&LineStarts != 0 {
+			markLineStarts(runes, literal, escaped, ctx)
+		}
+		return writeEscaped(runes, literal, escaped, ctx, style)
+	}
+
+	for i, c := range runes {
+		if literal[i] {
+			continue
+		}
+		switch c {
+		case '{', '[':
+			// Macros and links open on a single character
+			escaped[i] = true
+		case '}':
+			escaped[i] = ctx&Code != 0
+		case ']':
+			escaped[i] = ctx&LinkLabel != 0
+		case '|':
+			escaped[i] = ctx&(TableCell|LinkLabel) != 0
+		case '?':
+			// ??citation??
+			escaped[i] = i+1 < len(runes) && runes[i+1] == '?' && !literal[i+1] &&
+				(i == 0 || runes[i-1] != '?')
+		case '!':
+			escaped[i] = opensImage(runes, literal, i)
+		}
+	}
+	for _, effect := range effectChars {
+		markEffectPairs(runes, literal, escaped, effect)
+	}
+	if ctx&Code == 0 && rules&Underscores != 0 {
+		markUnderscores(runes, literal, escaped, keys)
+	}
+	if rules&LineStarts != 0 {
+		markLineStarts(runes, literal, escaped, ctx)
+	}
+	return writeEscaped(runes, literal, escaped, ctx, style)
+}
+
+// blockMarkers are the paragraph markers JIRA reads at the start of a line
+var blockMarkers = []string{"h1.", "h2.", "h3.", "h4.", "h5.", "h6.", "bq."}
+
+// markLineStarts marks the characters that would start a list, a heading, a
+// quote or a table row at the start of a line: the first list marker of a
+// run such as ** or #* followed by a space, the dot of h1. to h6. and bq.,
+// and a leading |
+func markLineStarts(runes []rune, literal, escaped []bool, ctx Context) {
+	if ctx&(TableCell|LinkLabel|Code) != 0 {
+		return
+	}
+	for i := range runes {
+		if (i == 0 && ctx&LineStart == 0) || (i > 0 && runes[i-1] != '\n') {
+			continue
+		}
+		end := i
+		for end < len(runes) && strings.ContainsRune("*#-", runes[end]) && !literal[end] {
+			end++
+		}
+		if end > i && end < len(runes) && (runes[end] == ' ' || runes[end] == '\t') && !literal[i] {
+			escaped[i] = true
+			continue
+		}
+		if runes[i] == '|' {
+			escaped[i] = !literal[i]
+			continue
+		}
+		for _, marker := range blockMarkers {
+			dot := i + len(marker) - 1
+			if dot < len(runes) && string(runes[i:dot+1]) == marker && !literal[dot] {
+				escaped[dot] = true
+				break
+			}
+		}
+	}
+}
+
+// separators are what the invisible styles insert after an escaped character
+var separators = map[Style]string{
+	ZeroWidth:  "\u200b",
+	EmptyGroup: "{}",
+}
+
+// writeEscaped writes runes, neutralizing literal and escaped ones. Effect
+// markers and ? outside code can be separated from the text they would
+// format; characters that open or end a link, macro, image or cell on their
+// own always take a backslash.
+func writeEscaped(runes []rune, literal, escaped []bool, ctx Context, style Style) string {
+	var out strings.Builder
+	for i, c := range runes {
+		if !literal[i] && !escaped[i] {
+			out.WriteRune(c)
+			continue
+		}
+		separator, ok := separators[style]
+		if ok && c == '.' {
+			// The separator goes before the dot of h1., which stays the
+			// end of a sentence
+			out.WriteString(separator)
+			out.WriteRune(c)
+			continue
+		}
+		if !ok || ctx&Code != 0 || (c != '?' && !strings.ContainsRune(effectChars, c)) {
+			out.WriteRune('\\')
+			out.WriteRune(c)
+			continue
+		}
+		out.WriteRune(c)
+		out.WriteString(separator)
+	}
+	return out.String()
+}
+
+// unescapeSource resolves Markdown backslash escapes, returning the runes and
+// which of them were escaped in the source and must stay literal
+func unescapeSource(text string, ctx Context) ([]rune, []bool) {
+	src := []rune(text)
+	runes := make([]rune, 0, len(src))
+	literal := make([]bool, 0, len(src))
+	for i := 0; i < len(src); i++ {
+		c := src[i]
+		if c == '\\' && ctx&Code == 0 && i+1 < len(src) && isASCIIPunct(src[i+1]) {
+			i++
+			runes = append(runes, src[i])
+			// Only JIRA metacharacters need the backslash in the output
+			literal = append(literal, strings.ContainsRune(MetaChars, src[i]) && src[i] != '\\')
+			continue
+		}
+		runes = append(runes, c)
+		literal = append(literal, false)
+	}
+	return runes, literal
+}
+
+// markEffectPairs marks the opening marker of every effect pair on a line
+func markEffectPairs(runes []rune, literal, escaped []bool, effect rune) {
+	opener := -1
+	for i, c := range runes {
+		if c == '\n' {
+			opener = -1
+			continue
+		}
+		if c != effect || literal[i] {
+			continue
+		}
+		// Runs such as -- or ** are dashes and rules, not effect markers
+		if (i > 0 && runes[i-1] == effect) || (i+1 < len(runes) && runes[i+1] == effect) {
+			opener = -1
+			continue
+		}
+		if opener >= 0 && i > opener+1 && canClose(runes, i) {
+			escaped[opener] = true
+			opener = -1
+		} else if canOpen(runes, i) {
+			opener = i
+		}
+	}
+}
+
+// markUnderscores marks the underscores JIRA would read as emphasis although
+// they do not pair in the text: JIRA emphasizes across word boundaries, so
+// my_variable_name comes out as my<em>variable</em>name, and an unpaired _
+// may pair with the marker of emphasis rendered after the text. The closing
+// underscore of a pair whose opener is escaped is left alone, as are runs
+// such as __ and the underscores of issue keys. Identifiers in {{monospace}}
+// are left as they are.
+func markUnderscores(runes []rune, literal, escaped, keys []bool) {
+	opener := -1
+	for i, c := range runes {
+		if c == '\n' {
+			opener = -1
+			continue
+		}
+		if c != '_' || literal[i] || keys[i] ||
+			(i > 0 && runes[i-1] == '_') || (i+1 < len(runes) && runes[i+1] == '_') {
+			continue
+		}
+		if escaped[i] {
+			opener = i
+			continue
+		}
+		if opener >= 0 && canClose(runes, i) {
+			opener = -1
+			continue
+		}
+		intraWord := i > 0 && i+1 < len(runes) && isWordRune(runes[i-1]) && isWordRune(runes[i+1])
+		escaped[i] = intraWord || canOpen(runes, i) || canClose(runes, i)
+	}
+}
+
+// issueKeyMarkers marks the dashes and underscores of the issue keys in
+// runes, such as PROJ-123 and MY_PROJ-7, which JIRA links to the issue and
+// would no longer link with a backslash in them
+func issueKeyMarkers(runes []rune) []bool {
+	markers := make([]bool, len(runes))
+	s := string(runes)
+	for _, key := range IssueKeyIndex(s) {
+		for i := key[0]; i < key[1]; i++ {
+			if s[i] == '-' || s[i] == '_' {
+				markers[utf8.RuneCountInString(s[:i])] = true
+			}
+		}
+	}
+	return markers
+}
+
+// IssueKeyIndex returns the byte offsets of the issue keys in s, such as
+// PROJ-123, like regexp's FindAllStringIndex. A key is an upper-case letter,
+// at least one more upper-case letter, digit or underscore, a dash and a
+// number, not inside a word or after one of &=?>^!~/.[- (as JIRA links them).
+func IssueKeyIndex(s string) [][]int {
+	var keys [][]int
+	for i := 0; i < len(s); i++ {
+		if s[i] < 'A' || s[i] > 'Z' {
+			continue
+		}
+		if before, _ := utf8.DecodeLastRuneInString(s[:i]); i > 0 && (isWordRune(before) || strings.ContainsRune(keyExcludedPrefixes, before)) {
+			continue
+		}
+		project := i + 1
+		for project < len(s) && isProjectKeyByte(s[project]) {
+			project++
+		}
+		end := project + 1
+		for end < len(s) && s[end] >= '0' && s[end] <= '9' {
+			end++
+		}
+		if project-i < 2 || project == len(s) || s[project] != '-' || end == project+1 {
+			i = project - 1
+			continue
+		}
+		if after, _ := utf8.DecodeRuneInString(s[end:]); end < len(s) && isWordRune(after) {
+			i = end - 1
+			continue
+		}
+		keys = append(keys, []int{i, end})
+		i = end - 1
+	}
+	return keys
+}
+
+// keyExcludedPrefixes are the characters JIRA does not link an issue key after
+const keyExcludedPrefixes = "&=?>^!~/.[-"
+
+// isProjectKeyByte reports whether c can continue a project key
+func isProjectKeyByte(c byte) bool {
+	return (c >= 'A' && c <= 'Z') || (c >= '0' && c <= '9') || c == '_'
+}
+
+// isWordRune reports whether c is part of a word around an issue key
+func isWordRune(c rune) bool {
+	return unicode.IsLetter(c) || unicode.IsDigit(c) || c == '_'
+}
+
+// canOpen reports whether the marker at i could open a JIRA effect
+func canOpen(runes []rune, i int) bool {
+	return isBoundary(runes, i-1) && i+1 < len(runes) && !unicode.IsSpace(runes[i+1])
+}
+
+// canClose reports whether the marker at i could close a JIRA effect
+func canClose(runes []rune, i int) bool {
+	return i > 0 && !unicode.IsSpace(runes[i-1]) && isBoundary(runes, i+1)
+}
+
+// isBoundary reports whether position i is outside the text, whitespace or punctuation
+func isBoundary(runes []rune, i int) bool {
+	if i < 0 || i >= len(runes) {
+		return true
+	}
+	c := runes[i]
+	return unicode.IsSpace(c) || unicode.IsPunct(c) || unicode.IsSymbol(c)
+}
+
+// opensImage reports whether the ! at i starts a !image! reference
+func opensImage(runes []rune, literal []bool, i int) bool {
+	if i+1 >= len(runes) || unicode.IsSpace(runes[i+1]) || runes[i+1] == '!' {
+		return false
+	}
+	for j := i + 1; j < len(runes); j++ {
+		if unicode.IsSpace(runes[j]) {
+			return false
+		}
+		if runes[j] == '!' && !literal[j] {
+			return true
+		}
+	}
+	return false
+}
+
+// isASCIIPunct reports whether c is ASCII punctuation (escapable in Markdown)
+func isASCIIPunct(c rune) bool {
+	return c < unicode.MaxASCII && (unicode.IsPunct(c) || unicode.IsSymbol(c))
+}
@@ -0,0 +1,200 @@
+package jiraescape
+
+import "testing"
+
+func TestEscape(t *testing.T) {
+	tests := []struct {
+		name string
+		in   string
+		ctx  Context
+		mode Mode
+		want string
+	}{
+		// Text effects and single-character openers
+		{"plain text", "plain text", 0, Minimal, "plain text"},
+		{"effect pair", "*bold* text", 0, Minimal, `\*bold* text`},
+		{"unpaired effect markers", "2 * 3 * 4", 0, Minimal, "2 * 3 * 4"},
+		{"deleted pair", "a -b- c", 0, Minimal, `a \-b- c`},
+		{"intra-word plus", "x+y+z", 0, Minimal, "x+y+z"},
+		{"link and macro openers", "see [x] and {y}", 0, Minimal, `see \[x] and \{y}`},
+		{"citation", "??cite??", 0, Minimal, `\??cite\??`},
+		{"single question mark", "what?", 0, Minimal, "what?"},
+		{"image", "!img.png!", 0, Minimal, `\!img.png!`},
+		{"exclamation marks", "wow! nice!", 0, Minimal, "wow! nice!"},
+
+		// Contexts
+		{"pipe in text", "a|b", 0, Minimal, "a|b"},
+		{"pipe in a cell", "a|b", TableCell, Minimal, `a\|b`},
+		{"link label", "a]|b", LinkLabel, Minimal, `a\]\|b`},
+		{"brace in code", "a}b", Code, Minimal, `a\}b`},
+
+		// Issue keys
+		{"issue keys", "PROJ-123 and PROJ-124", 0, Minimal, "PROJ-123 and PROJ-124"},
+		{"deleted issue key", "-PROJ-1-", 0, Minimal, `\-PROJ-1-`},
+		{"issue key in aggressive mode", "PROJ-12", 0, Aggressive, "PROJ-12"},
+		{"underscore of an issue key", "MY_PROJ-12", 0, Minimal, "MY_PROJ-12"},
+
+		// Modes
+		{"aggressive", "a*b _c_", 0, Aggressive, `a\*b \_c\_`},
+		{"none", "*bold* [x]", 0, None, "*bold* [x]"},
+
+		// Line starts
+		{"list marker", "- item", LineStart, Minimal, `\- item`},
+		{"list marker mid-line", "- item", 0, Minimal, "- item"},
+		{"nested list marker", "** two", LineStart, Minimal, `\** two`},
+		{"mixed list marker", "#* mixed", LineStart, Minimal, `\#* mixed`},
+		{"numbered list marker", "# heading", LineStart, Minimal, `\# heading`},
+		{"negative number", "-1 degrees", LineStart, Minimal, "-1 degrees"},
+		{"heading marker", "h1. Title", LineStart, Minimal, `h1\. Title`},
+		{"quote marker", "bq. quote", LineStart, Minimal, `bq\. quote`},
+		{"not a heading", "h7. no", LineStart, Minimal, "h7. no"},
+		{"table row", "| row", LineStart, Minimal, `\| row`},
+		{"lines after newlines", "a\n- b\nh2. c\n| d", 0, Minimal, "a\n\\- b\nh2\\. c\n\\| d"},
+		{"line start in a cell", "- b", TableCell | LineStart, Minimal, "- b"},
+		{"line start in code", "- b", Code | LineStart, Minimal, "- b"},
+
+		// Underscores
+		{"intra-word underscores", "snake_case_name", 0, Minimal, `snake\_case\_name`},
+		{"emphasis", "_emph_", 0, Minimal, `\_emph_`},
+		{"lone underscore", "a _ b", 0, Minimal, "a _ b"},
+		{"double underscores", "__init__", 0, Minimal, "__init__"},
+		{"unpaired opener", "_open only", 0, Minimal, `\_open only`},
+		{"underscores in code", "HKEY_CURRENT_USER", Code, Minimal, "HKEY_CURRENT_USER"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Escape(tt.in, tt.ctx, tt.mode); got != tt.want {
+				t.Errorf("Escape(%q, %d, %v) = %q, want %q", tt.in, tt.ctx, tt.mode, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestEscapeWithStyle(t *testing.T) {
+	tests := []struct {
+		name  string
+		in    string
+		style Style
+		want  string
+	}{
+		{"zero-width effect", "*bold*", ZeroWidth, "*\u200bbold*"},
+		{"empty-group effect", "*bold*", EmptyGroup, "*{}bold*"},
+		{"zero-width link opener", "[x]", ZeroWidth, `\[x]`},
+		{"zero-width heading marker", "h1. Title", ZeroWidth, "h1\u200b. Title"},
+		{"empty-group heading marker", "h1. Title", EmptyGroup, "h1{}. Title"},
+		{"empty-group list marker", "- item", EmptyGroup, "-{} item"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := EscapeWithStyle(tt.in, LineStart, Minimal, tt.style); got != tt.want {
+				t.Errorf("EscapeWithStyle(%q, %v) = %q, want %q", tt.in, tt.style, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestEscapeWithRules(t *testing.T) {
+	tests := []struct {
+		name  string
+		in    string
+		mode  Mode
+		rules Rules
+		want  string
+	}{
+		{"issue keys", "PROJ-12 a-b", Aggressive, AllRules, `PROJ-12 a\-b`},
+		{"without issue keys", "PROJ-12 a-b", Aggressive, 0, `PROJ\-12 a\-b`},
+		{"line starts", "h1. x\n- y", Minimal, AllRules, "h1\\. x\n\\- y"},
+		{"without line starts", "h1. x\n- y", Minimal, AllRules &^ LineStarts, "h1. x\n- y"},
+		{"underscores", "snake_case", Minimal, AllRules, `snake\_case`},
+		{"without underscores", "snake_case", Minimal, AllRules &^ Underscores, "snake_case"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := EscapeWithRules(tt.in, LineStart, tt.mode, Backslash, tt.rules); got != tt.want {
+				t.Errorf("EscapeWithRules(%q, %v) = %q, want %q", tt.in, tt.rules, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMarkdown(t *testing.T) {
+	tests := []struct {
+		in   string
+		ctx  Context
+		want string
+	}{
+		{`\*not bold\*`, 0, `\*not bold\*`},
+		{`\[x`, 0, `\[x`},
+		{`a\_b`, 0, `a\_b`},
+		{`C:\path`, 0, `C:\path`},
+		{`\- item`, LineStart, `\- item`},
+		{`a\\b`, 0, `a\b`},
+	}
+	for _, tt := range tests {
+		if got := Markdown(tt.in, tt.ctx, Minimal); got != tt.want {
+			t.Errorf("Markdown(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestHelpers(t *testing.T) {
+	tests := []struct {
+		name string
+		got  string
+		want string
+	}{
+		{"Text", Text("- x"), `\- x`},
+		{"CellContent", CellContent("a|b"), `a\|b`},
+		{"MacroParam", MacroParam("a|b {c}\n d"), "a b c d"},
+	}
+	for _, tt := range tests {
+		if tt.got != tt.want {
+			t.Errorf("%s = %q, want %q", tt.name, tt.got, tt.want)
+		}
+	}
+}
+
+func TestParseModeAndStyle(t *testing.T) {
+	for _, mode := range []Mode{Minimal, Aggressive, None} {
+		if got, err := ParseMode(mode.String()); err != nil || got != mode {
+			t.Errorf("ParseMode(%q) = %v, %v", mode.String(), got, err)
+		}
+	}
+	if _, err := ParseMode("loud"); err == nil {
+		t.Error("ParseMode(loud) succeeded")
+	}
+	for _, style := range []Style{Backslash, ZeroWidth, EmptyGroup} {
+		if got, err := ParseStyle(style.String()); err != nil || got != style {
+			t.Errorf("ParseStyle(%q) = %v, %v", style.String(), got, err)
+		}
+	}
+	if _, err := ParseStyle("bold"); err == nil {
+		t.Error("ParseStyle(bold) succeeded")
+	}
+}
+
+func TestIssueKeyIndex(t *testing.T) {
+	tests := []struct {
+		in   string
+		want []string
+	}{
+		{"PROJ-1 and AB2-34.", []string{"PROJ-1", "AB2-34"}},
+		{"UTF-8 is a key too", []string{"UTF-8"}},
+		{"proj-1 x-PROJ-2 PROJ-", nil},
+	}
+	for _, tt := range tests {
+		var got []string
+		for _, m := range IssueKeyIndex(tt.in) {
+			got = append(got, tt.in[m[0]:m[1]])
+		}
+		if len(got) != len(tt.want) {
+			t.Errorf("IssueKeyIndex(%q) = %q, want %q", tt.in, got, tt.want)
+			continue
+		}
+		for i := range got {
+			if got[i] != tt.want[i] {
+				t.Errorf("IssueKeyIndex(%q) = %q, want %q", tt.in, got, tt.want)
+			}
+		}
+	}
+}